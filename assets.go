@@ -0,0 +1,95 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+const (
+	// EnvAssetsTag is set to the tag of the release for the Assets.Commands and Package.ReleaseCommands.
+	EnvAssetsTag = "RELEASER_PLEASER_TAG"
+	// EnvAssetsVersion is set to the version of the release without the tag prefix for the Assets.Commands and
+	// Package.ReleaseCommands.
+	EnvAssetsVersion = "RELEASER_PLEASER_VERSION"
+)
+
+// Assets are attached to every release of a package. The commands run and the files are resolved in the working
+// directory, which should be a checkout of the repository at the release commit.
+type Assets struct {
+	// Commands are run with "sh -c" in order, before the release is created. They can build the files, e.g.
+	// "make dist". The release is not created if a command fails.
+	Commands []string
+	// Files are glob patterns (see filepath.Match) of the files that are uploaded. Every pattern must match at least
+	// one file.
+	Files []string
+}
+
+func (a Assets) empty() bool {
+	return len(a.Commands) == 0 && len(a.Files) == 0
+}
+
+// build runs the commands and returns the paths of all files matching the patterns. The output of the commands is
+// written to output.
+func (a Assets) build(ctx context.Context, logger *slog.Logger, dir string, output io.Writer, tag, version string) ([]string, error) {
+	for _, command := range a.Commands {
+		logger.InfoContext(ctx, "running asset command", "command", command)
+
+		if err := runCommand(ctx, command, dir, output, tag, version); err != nil {
+			return nil, fmt.Errorf("asset command %q failed: %w", command, err)
+		}
+	}
+
+	files := make([]string, 0, len(a.Files))
+	for _, pattern := range a.Files {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		matches = slices.DeleteFunc(matches, func(match string) bool {
+			info, err := os.Stat(match)
+			return err == nil && info.IsDir()
+		})
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset pattern %q does not match any files", pattern)
+		}
+
+		for _, match := range matches {
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// uploadAssets attaches the files to the release on the forge, using their base name as the asset name.
+func (rp *ReleaserPleaser) uploadAssets(ctx context.Context, logger *slog.Logger, release forge.Release, files []string) error {
+	for _, path := range files {
+		err := rp.uploadAsset(ctx, release, path)
+		if err != nil {
+			return fmt.Errorf("failed to upload asset %s: %w", path, err)
+		}
+
+		logger.InfoContext(ctx, "uploaded release asset", "asset.path", path)
+	}
+
+	return nil
+}
+
+func (rp *ReleaserPleaser) uploadAsset(ctx context.Context, release forge.Release, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return rp.forge.UploadReleaseAsset(ctx, release, filepath.Base(path), file)
+}
@@ -0,0 +1,153 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestAssets_build(t *testing.T) {
+	tests := []struct {
+		name       string
+		assets     Assets
+		files      []string
+		want       []string
+		wantOutput string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "files",
+			assets:  Assets{Files: []string{"dist/*.tar.gz", "README.md"}},
+			files:   []string{"dist/app-linux.tar.gz", "dist/app-darwin.tar.gz", "dist/checksums.txt", "README.md"},
+			want:    []string{"dist/app-darwin.tar.gz", "dist/app-linux.tar.gz", "README.md"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "duplicate matches",
+			assets:  Assets{Files: []string{"dist/*", "dist/*.txt"}},
+			files:   []string{"dist/checksums.txt"},
+			want:    []string{"dist/checksums.txt"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "commands",
+			assets: Assets{
+				Commands: []string{
+					"mkdir dist",
+					`echo "$RELEASER_PLEASER_TAG $RELEASER_PLEASER_VERSION" > "dist/app-$RELEASER_PLEASER_VERSION.txt"`,
+					"echo built",
+				},
+				Files: []string{"dist/*"},
+			},
+			want:       []string{"dist/app-1.2.3.txt"},
+			wantOutput: "built\n",
+			wantErr:    assert.NoError,
+		},
+		{
+			name:    "failing command",
+			assets:  Assets{Commands: []string{"exit 1"}},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "pattern without match",
+			assets:  Assets{Files: []string{"dist/*"}},
+			files:   []string{"README.md"},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "pattern matching only directories",
+			assets:  Assets{Files: []string{"dist"}},
+			files:   []string{"dist/app"},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0o644))
+			}
+
+			var output bytes.Buffer
+			got, err := tt.assets.build(context.Background(), slog.Default(), dir, &output, "v1.2.3", "1.2.3")
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			var want []string
+			for _, file := range tt.want {
+				want = append(want, filepath.Join(dir, file))
+			}
+			assert.Equal(t, want, got)
+			assert.Equal(t, tt.wantOutput, output.String())
+		})
+	}
+}
+
+// fakeCloneURL serves a local repository.
+type fakeCloneURL struct {
+	forge.Forge
+
+	dir string
+}
+
+func (f *fakeCloneURL) CloneURL() string              { return f.dir }
+func (f *fakeCloneURL) GitAuth() transport.AuthMethod { return nil }
+
+func TestReleaserPleaser_buildAssets(t *testing.T) {
+	dir := t.TempDir()
+	r, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	commit := func(content string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "VERSION"), []byte(content), 0o644))
+		_, err := worktree.Add("VERSION")
+		require.NoError(t, err)
+		hash, err := worktree.Commit("chore: release "+content, &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+		require.NoError(t, err)
+		return hash.String()
+	}
+	release := commit("1.2.3")
+	commit("1.3.0")
+
+	assets := Assets{Commands: []string{"mkdir dist && cp VERSION dist/VERSION.txt"}, Files: []string{"dist/*"}}
+
+	t.Run("release commit", func(t *testing.T) {
+		rp := New(&fakeCloneURL{dir: dir}, Options{TargetBranch: "master"})
+
+		files, err := rp.buildAssets(context.Background(), slog.Default(), assets, git.Commit{Hash: release}, "v1.2.3", "1.2.3")
+		require.NoError(t, err)
+		if assert.Len(t, files, 1) {
+			assert.NotEqual(t, dir, filepath.Dir(filepath.Dir(files[0])), "assets are built in a clone")
+
+			content, err := os.ReadFile(files[0])
+			require.NoError(t, err)
+			assert.Equal(t, "1.2.3", string(content))
+		}
+	})
+
+	t.Run("memory clone", func(t *testing.T) {
+		rp := New(&fakeCloneURL{dir: dir}, Options{TargetBranch: "master", Clone: git.CloneOptions{Mode: git.CloneModeMemory}})
+
+		_, err := rp.buildAssets(context.Background(), slog.Default(), assets, git.Commit{Hash: release}, "v1.2.3", "1.2.3")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/updater"
+)
+
+// ChangelogFile is a file in the repository that receives the changelog entry of every release.
+type ChangelogFile struct {
+	// Path of the file, relative to the package. If it contains a template action, e.g.
+	// "docs/changelog/{{ .Version }}.md", it is rendered with ChangelogPathData and a new file that only contains the
+	// entry is written for every release. Otherwise, the entry is added above the previous releases in the file.
+	Path string
+	// Format of the entry, defaults to Options.ChangelogFormat.
+	Format changelog.Format
+	// Template is the path of a custom template for the entry in the repository. It replaces the template of Format.
+	Template string
+}
+
+// ChangelogPathData is available in the Path of a ChangelogFile.
+type ChangelogPathData struct {
+	// Version is the version number of the release without the tag prefix of the package, e.g. "v1.2.3".
+	Version string
+	// TagName is the name of the release tag, e.g. "v1.2.3".
+	TagName string
+	// Date of the release in the format YYYY-MM-DD.
+	Date string
+}
+
+// perRelease returns true if a new file is written for every release.
+func (f ChangelogFile) perRelease() bool {
+	return strings.Contains(f.Path, "{{")
+}
+
+func (f ChangelogFile) path(data ChangelogPathData) (string, error) {
+	if !f.perRelease() {
+		return f.Path, nil
+	}
+
+	tpl, err := template.New("path").Option("missingkey=error").Parse(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse changelog path %q: %w", f.Path, err)
+	}
+
+	var path strings.Builder
+	if err = tpl.Execute(&path, data); err != nil {
+		return "", fmt.Errorf("failed to render changelog path %q: %w", f.Path, err)
+	}
+
+	return path.String(), nil
+}
+
+func (f ChangelogFile) renderer(logger *slog.Logger, readFile func(path string) ([]byte, error)) (changelog.Renderer, error) {
+	if f.Template == "" {
+		return changelog.LoadRenderer(logger, f.Format, readFile)
+	}
+
+	tpl, err := changelog.LoadTemplateFile(readFile, f.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return changelog.NewTemplateRenderer(logger, tpl), nil
+}
+
+func (f ChangelogFile) updater() updater.NewUpdater {
+	switch {
+	case f.perRelease():
+		return updater.ChangelogEntry
+	case f.Format == changelog.FormatJSON:
+		return updater.ChangelogJSON
+	default:
+		return updater.Changelog
+	}
+}
+
+// writeChangelogFiles renders the changelog entry for every file and adds it to the file in the package.
+func writeChangelogFiles(ctx context.Context, logger *slog.Logger, repo *git.Repository, pkg Package, files []ChangelogFile, data changelog.Data, info updater.ReleaseInfo) error {
+	readFile := func(path string) ([]byte, error) { return repo.ReadFile(ctx, path) }
+	pathData := ChangelogPathData{Version: info.Version, TagName: info.TagName, Date: data.Date}
+
+	for _, file := range files {
+		renderer, err := file.renderer(logger, readFile)
+		if err != nil {
+			return err
+		}
+
+		info.ChangelogEntry, err = renderer.Render(data, changelog.Formatting{})
+		if err != nil {
+			return fmt.Errorf("failed to build changelog entry for %s: %w", file.Path, err)
+		}
+
+		path, err := file.path(pathData)
+		if err != nil {
+			return err
+		}
+
+		err = repo.UpdateFile(ctx, pkg.changelogFile(path), true, updater.WithInfo(info, file.updater()))
+		if err != nil {
+			return fmt.Errorf("failed to update changelog file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package rp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/updater"
+)
+
+func TestChangelogFile_path(t *testing.T) {
+	data := ChangelogPathData{Version: "1.2.0", TagName: "v1.2.0", Date: "2024-08-17"}
+
+	tests := []struct {
+		name           string
+		path           string
+		want           string
+		wantPerRelease bool
+		wantErr        assert.ErrorAssertionFunc
+	}{
+		{name: "plain", path: "docs/CHANGELOG.md", want: "docs/CHANGELOG.md", wantErr: assert.NoError},
+		{name: "version", path: "docs/changelog/{{ .Version }}.md", want: "docs/changelog/1.2.0.md", wantPerRelease: true, wantErr: assert.NoError},
+		{name: "date and tag", path: "news/{{ .Date }}-{{ .TagName }}.md", want: "news/2024-08-17-v1.2.0.md", wantPerRelease: true, wantErr: assert.NoError},
+		{name: "unknown field", path: "{{ .Foo }}.md", wantPerRelease: true, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ChangelogFile{Path: tt.path}
+			assert.Equal(t, tt.wantPerRelease, file.perRelease())
+
+			got, err := file.path(data)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_writeChangelogFiles(t *testing.T) {
+	dir := t.TempDir()
+	r, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".releaser-pleaser"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".releaser-pleaser", "hugo.md.tpl"), []byte("---\ntitle: {{ .Data.Version }}\n---\n\n{{ range .Data.Sections }}{{ range .Commits }}- {{ .Description }}\n{{ end }}{{ end }}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte("# Changelog\n\n## v1.1.0\n"), 0o644))
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	_, err = worktree.Commit("chore: init", &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+	require.NoError(t, err)
+
+	repo, err := git.OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	data := changelog.New([]commitparser.AnalyzedCommit{
+		{Type: "feat", Description: "Foobar!"},
+	}, changelog.DefaultSections, changelog.Scopes{}, "v1.2.0", "https://example.com/v1.2.0", "", "")
+	data.Date = "2024-08-17"
+
+	files := []ChangelogFile{
+		{Path: updater.ChangelogFile, Format: changelog.FormatMarkdown},
+		{Path: "docs/content/changelog/{{ .Version }}.md", Template: ".releaser-pleaser/hugo.md.tpl"},
+		{Path: "CHANGELOG.json", Format: changelog.FormatJSON},
+	}
+	err = writeChangelogFiles(context.Background(), slog.Default(), repo, Package{}, files, data, updater.ReleaseInfo{Version: "v1.2.0", TagName: "v1.2.0"})
+	require.NoError(t, err)
+
+	read := func(path string) string {
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Equal(t, "# Changelog\n\n## [v1.2.0](https://example.com/v1.2.0)\n\n### Features\n\n- Foobar!\n\n## v1.1.0\n", read("CHANGELOG.md"))
+	assert.Equal(t, "---\ntitle: v1.2.0\n---\n\n- Foobar!\n", read("docs/content/changelog/v1.2.0.md"))
+	assert.Contains(t, read("CHANGELOG.json"), `"version": "v1.2.0"`)
+}
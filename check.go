@@ -0,0 +1,104 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// CheckStatus is the outcome of checking a single commit.
+type CheckStatus string
+
+const (
+	// CheckStatusOK is used for commits that are listed in a section of the changelog.
+	CheckStatusOK CheckStatus = "ok"
+	// CheckStatusSkipped is used for commits that are intentionally left out of the changelog: merge commits, commits
+	// with the changelog skip trailer and commits with an excluded scope.
+	CheckStatusSkipped CheckStatus = "skipped"
+	// CheckStatusInvalid is used for commits that the commit parser can not parse.
+	CheckStatusInvalid CheckStatus = "invalid"
+	// CheckStatusHidden is used for valid commits whose type has no section in the changelog.
+	CheckStatusHidden CheckStatus = "hidden"
+)
+
+// CheckResult describes how a single commit would show up in the changelog.
+type CheckResult struct {
+	Hash string
+	// Subject is the first line of the commit message.
+	Subject string
+	Status  CheckStatus
+	// Type of the commit, empty for invalid commits.
+	Type string
+	// Section is the title of the changelog section that lists the commit, empty if it is not listed.
+	Section string
+	// Problem explains why the commit is not listed in the changelog.
+	Problem string
+}
+
+// CheckReport is the result of Check.
+type CheckReport struct {
+	Commits []CheckResult
+}
+
+// OK reports if all commits are either listed in the changelog or intentionally skipped.
+func (r CheckReport) OK() bool {
+	return !slices.ContainsFunc(r.Commits, func(result CheckResult) bool {
+		return result.Status == CheckStatusInvalid || result.Status == CheckStatusHidden
+	})
+}
+
+// Check validates that the commits, e.g. of a pull request that is not merged yet, can be parsed by the commit parser
+// and are listed in one of the sections of the changelog. Nothing on the forge is read or modified.
+func Check(parser commitparser.CommitParser, commits []git.Commit, sections []changelog.Section, scopes changelog.Scopes) CheckReport {
+	report := CheckReport{Commits: make([]CheckResult, 0, len(commits))}
+	for _, commit := range commits {
+		result := CheckResult{Hash: commit.Hash, Subject: subject(commit.Message)}
+
+		if strings.HasPrefix(result.Subject, "Merge ") {
+			// Merge commits created by git or the forge are ignored when building the changelog too.
+			result.Status = CheckStatusSkipped
+			result.Problem = "merge commit"
+			report.Commits = append(report.Commits, result)
+			continue
+		}
+
+		analyzed, err := parser.Parse(commit)
+		switch {
+		case errors.Is(err, commitparser.ErrSkipped):
+			result.Status = CheckStatusSkipped
+			result.Problem = "changelog skip trailer"
+		case err != nil:
+			result.Status = CheckStatusInvalid
+			result.Problem = fmt.Sprintf("invalid commit message: %v", err)
+		case analyzed.Scope != nil && slices.Contains(scopes.Exclude, *analyzed.Scope):
+			result.Type = analyzed.Type
+			result.Status = CheckStatusSkipped
+			result.Problem = fmt.Sprintf("scope %q is excluded", *analyzed.Scope)
+		default:
+			result.Type = analyzed.Type
+
+			data := changelog.New([]commitparser.AnalyzedCommit{analyzed}, sections, changelog.Scopes{}, "", "", "", "")
+			if len(data.Sections) > 0 {
+				result.Status = CheckStatusOK
+				result.Section = data.Sections[0].Title
+			} else {
+				result.Status = CheckStatusHidden
+				result.Problem = fmt.Sprintf("type %q has no section in the changelog", analyzed.Type)
+			}
+		}
+
+		report.Commits = append(report.Commits, result)
+	}
+
+	return report
+}
+
+func subject(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,112 @@
+package rp
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/conventionalcommits"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/gitmoji"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestCheck(t *testing.T) {
+	sections := []changelog.Section{
+		{Type: changelog.SectionTypeBreaking, Title: "Breaking Changes"},
+		{Type: "feat", Title: "Features"},
+		{Type: "fix", Title: "Bug Fixes"},
+	}
+
+	tests := []struct {
+		name   string
+		commit git.Commit
+		scopes changelog.Scopes
+		want   CheckResult
+	}{
+		{
+			name:   "listed",
+			commit: git.Commit{Hash: "abc", Message: "feat(api): add foo\n\nSome details."},
+			want:   CheckResult{Hash: "abc", Subject: "feat(api): add foo", Status: CheckStatusOK, Type: "feat", Section: "Features"},
+		},
+		{
+			name:   "breaking change",
+			commit: git.Commit{Hash: "abc", Message: "fix!: remove foo"},
+			want:   CheckResult{Hash: "abc", Subject: "fix!: remove foo", Status: CheckStatusOK, Type: "fix", Section: "Breaking Changes"},
+		},
+		{
+			name:   "type without section",
+			commit: git.Commit{Hash: "abc", Message: "chore: update tooling"},
+			want:   CheckResult{Hash: "abc", Subject: "chore: update tooling", Status: CheckStatusHidden, Type: "chore", Problem: `type "chore" has no section in the changelog`},
+		},
+		{
+			name:   "changelog skip trailer",
+			commit: git.Commit{Hash: "abc", Message: "feat: internal only\n\nChangelog: skip"},
+			want:   CheckResult{Hash: "abc", Subject: "feat: internal only", Status: CheckStatusSkipped, Problem: "changelog skip trailer"},
+		},
+		{
+			name:   "excluded scope",
+			commit: git.Commit{Hash: "abc", Message: "fix(deps): update foo"},
+			scopes: changelog.Scopes{Exclude: []string{"deps"}},
+			want:   CheckResult{Hash: "abc", Subject: "fix(deps): update foo", Status: CheckStatusSkipped, Type: "fix", Problem: `scope "deps" is excluded`},
+		},
+		{
+			name:   "merge commit",
+			commit: git.Commit{Hash: "abc", Message: "Merge branch 'main' into feature"},
+			want:   CheckResult{Hash: "abc", Subject: "Merge branch 'main' into feature", Status: CheckStatusSkipped, Problem: "merge commit"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Check(conventionalcommits.NewParser(slog.Default()), []git.Commit{tt.commit}, sections, tt.scopes)
+			assert.Equal(t, []CheckResult{tt.want}, report.Commits)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		report := Check(conventionalcommits.NewParser(slog.Default()), []git.Commit{{Hash: "abc", Message: "Update the foo"}}, sections, changelog.Scopes{})
+		if assert.Len(t, report.Commits, 1) {
+			assert.Equal(t, CheckStatusInvalid, report.Commits[0].Status)
+			assert.Contains(t, report.Commits[0].Problem, "invalid commit message")
+		}
+		assert.False(t, report.OK())
+	})
+
+	t.Run("configured parser", func(t *testing.T) {
+		commits := []git.Commit{
+			{Hash: "abc", Message: "✨ (api): add foo"},
+			{Hash: "def", Message: ":bug: fix bar"},
+			{Hash: "ghi", Message: "feat: add baz"},
+		}
+
+		report := Check(gitmoji.NewParser(slog.Default()), commits, sections, changelog.Scopes{})
+		if assert.Len(t, report.Commits, 3) {
+			assert.Equal(t, CheckResult{Hash: "abc", Subject: "✨ (api): add foo", Status: CheckStatusOK, Type: "feat", Section: "Features"}, report.Commits[0])
+			assert.Equal(t, CheckResult{Hash: "def", Subject: ":bug: fix bar", Status: CheckStatusOK, Type: "fix", Section: "Bug Fixes"}, report.Commits[1])
+			assert.Equal(t, CheckStatusInvalid, report.Commits[2].Status)
+		}
+	})
+}
+
+func TestCheckReport_OK(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []CheckStatus
+		want     bool
+	}{
+		{name: "empty", statuses: nil, want: true},
+		{name: "ok and skipped", statuses: []CheckStatus{CheckStatusOK, CheckStatusSkipped}, want: true},
+		{name: "hidden", statuses: []CheckStatus{CheckStatusOK, CheckStatusHidden}, want: false},
+		{name: "invalid", statuses: []CheckStatus{CheckStatusInvalid}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report CheckReport
+			for _, status := range tt.statuses {
+				report.Commits = append(report.Commits, CheckResult{Status: status})
+			}
+			assert.Equal(t, tt.want, report.OK())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// releaseBranchPrefix is the common prefix of PullRequestBranchFormat and PullRequestPackageBranchFormat.
+var releaseBranchPrefix = strings.SplitN(PullRequestBranchFormat, "%s", 2)[0]
+
+const supersededComment = "This release pull request was superseded and is closed by releaser-pleaser. Its branch `%s` is not the release branch of any package for `%s` anymore, e.g. because the branch was renamed or the package was removed. A new release pull request is opened for the pending changes."
+
+// runCleanup closes superseded release pull requests into the target branch and deletes release branches without an
+// open pull request. It requires a forge that implements forge.ReleaseBranchCleaner. Failures are only logged, as the
+// cleanup does not affect the release itself and is retried on the next run.
+func (rp *ReleaserPleaser) runCleanup(ctx context.Context) {
+	logger := rp.logger.With("method", "runCleanup")
+
+	cleaner, ok := rp.forge.(forge.ReleaseBranchCleaner)
+	if !ok {
+		logger.DebugContext(ctx, "forge does not support cleaning up release branches")
+		return
+	}
+
+	prs, err := cleaner.OpenPullRequests(ctx, releaseBranchPrefix)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to list open release pull requests, skipping cleanup", "err", err)
+		return
+	}
+
+	releaseBranches := make(map[string]bool, len(rp.packages))
+	for _, pkg := range rp.packages {
+		releaseBranches[pkg.branch(rp.targetBranch)] = true
+	}
+
+	openBranches := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		// Release pull requests into other branches, e.g. maintenance branches, are managed by their own runs
+		if pr.Base != rp.targetBranch || releaseBranches[pr.Head] {
+			openBranches[pr.Head] = true
+			continue
+		}
+
+		if err = rp.closeSupersededPullRequest(ctx, cleaner, pr); err != nil {
+			logger.WarnContext(ctx, "failed to close superseded release pull request", "pr.id", pr.ID, "err", err)
+			// The branch is kept until the pull request is closed
+			openBranches[pr.Head] = true
+		}
+	}
+
+	branches, err := git.RemoteBranches(ctx, rp.forge.CloneURL(), rp.forge.GitAuth(), releaseBranchPrefix)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to list release branches, skipping cleanup", "err", err)
+		return
+	}
+
+	for _, branch := range branches {
+		if openBranches[branch] {
+			continue
+		}
+
+		logger.InfoContext(ctx, "deleting release branch without open pull request", "branch", branch)
+		if err = cleaner.DeleteBranch(ctx, branch); err != nil {
+			logger.WarnContext(ctx, "failed to delete release branch", "branch", branch, "err", err)
+		}
+	}
+}
+
+func (rp *ReleaserPleaser) closeSupersededPullRequest(ctx context.Context, cleaner forge.ReleaseBranchCleaner, pr *releasepr.ReleasePullRequest) error {
+	rp.logger.InfoContext(ctx, "closing superseded release pull request", "pr.id", pr.ID, "pr.title", pr.Title, "branch", pr.Head)
+
+	err := cleaner.CommentOnPullRequest(ctx, pr, fmt.Sprintf(supersededComment, pr.Head, rp.targetBranch))
+	if err != nil {
+		return fmt.Errorf("failed to comment on superseded pull request %d: %w", pr.ID, err)
+	}
+
+	if err = rp.forge.ClosePullRequest(ctx, pr); err != nil {
+		return fmt.Errorf("failed to close superseded pull request %d: %w", pr.ID, err)
+	}
+	rp.emit(ctx, rp.pullRequestUpdated(Package{}, pr, PullRequestActionClosed))
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// fakeBranchCleaner serves the open pull requests and the branches of a local repository.
+type fakeBranchCleaner struct {
+	forge.Forge
+
+	dir string
+	prs []*releasepr.ReleasePullRequest
+	// failDelete is a branch that can not be deleted
+	failDelete string
+
+	comments map[int]string
+	closed   []int
+}
+
+func (f *fakeBranchCleaner) CloneURL() string              { return f.dir }
+func (f *fakeBranchCleaner) GitAuth() transport.AuthMethod { return nil }
+func (f *fakeBranchCleaner) PullRequestURL(int) string     { return "" }
+
+func (f *fakeBranchCleaner) OpenPullRequests(context.Context, string) ([]*releasepr.ReleasePullRequest, error) {
+	return f.prs, nil
+}
+
+func (f *fakeBranchCleaner) CommentOnPullRequest(_ context.Context, pr *releasepr.ReleasePullRequest, comment string) error {
+	f.comments[pr.ID] = comment
+	return nil
+}
+
+func (f *fakeBranchCleaner) DeleteBranch(_ context.Context, branch string) error {
+	if branch == f.failDelete {
+		return errors.New("branch is protected")
+	}
+
+	r, err := gogit.PlainOpen(f.dir)
+	if err != nil {
+		return err
+	}
+	return r.Storer.RemoveReference(plumbing.NewBranchReferenceName(branch))
+}
+
+func (f *fakeBranchCleaner) ClosePullRequest(_ context.Context, pr *releasepr.ReleasePullRequest) error {
+	f.closed = append(f.closed, pr.ID)
+	return nil
+}
+
+func TestReleaserPleaser_runCleanup(t *testing.T) {
+	tests := []struct {
+		name         string
+		packages     []Package
+		branches     []string
+		prs          []*releasepr.ReleasePullRequest
+		failDelete   string
+		wantClosed   []int
+		wantBranches []string
+	}{
+		{
+			name:         "up-to-date",
+			branches:     []string{"releaser-pleaser--branches--main"},
+			prs:          []*releasepr.ReleasePullRequest{{PullRequest: git.PullRequest{ID: 1}, Head: "releaser-pleaser--branches--main", Base: "main"}},
+			wantBranches: []string{"releaser-pleaser--branches--main"},
+		},
+		{
+			name:     "renamed base branch",
+			branches: []string{"releaser-pleaser--branches--main", "releaser-pleaser--branches--master"},
+			prs: []*releasepr.ReleasePullRequest{
+				{PullRequest: git.PullRequest{ID: 1}, Head: "releaser-pleaser--branches--main", Base: "main"},
+				{PullRequest: git.PullRequest{ID: 2}, Head: "releaser-pleaser--branches--master", Base: "main"},
+			},
+			wantClosed:   []int{2},
+			wantBranches: []string{"releaser-pleaser--branches--main"},
+		},
+		{
+			name:     "removed package",
+			packages: []Package{{Name: "api", TagPrefix: "api/v"}},
+			branches: []string{"releaser-pleaser--branches--main--api", "releaser-pleaser--branches--main--web"},
+			prs: []*releasepr.ReleasePullRequest{
+				{PullRequest: git.PullRequest{ID: 1}, Head: "releaser-pleaser--branches--main--api", Base: "main"},
+				{PullRequest: git.PullRequest{ID: 2}, Head: "releaser-pleaser--branches--main--web", Base: "main"},
+			},
+			wantClosed:   []int{2},
+			wantBranches: []string{"releaser-pleaser--branches--main--api"},
+		},
+		{
+			name:     "maintenance branch",
+			branches: []string{"releaser-pleaser--branches--release-1.x"},
+			prs: []*releasepr.ReleasePullRequest{
+				{PullRequest: git.PullRequest{ID: 1}, Head: "releaser-pleaser--branches--release-1.x", Base: "release-1.x"},
+			},
+			wantBranches: []string{"releaser-pleaser--branches--release-1.x"},
+		},
+		{
+			name:     "orphaned branches",
+			branches: []string{"releaser-pleaser--branches--main", "releaser-pleaser--branches--old"},
+		},
+		{
+			name:         "failed deletion",
+			branches:     []string{"releaser-pleaser--branches--main", "releaser-pleaser--branches--old"},
+			failDelete:   "releaser-pleaser--branches--main",
+			wantBranches: []string{"releaser-pleaser--branches--main"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := gogit.PlainInit(dir, false)
+			require.NoError(t, err)
+			worktree, err := r.Worktree()
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\n"), 0o644))
+			_, err = worktree.Add("README.md")
+			require.NoError(t, err)
+			head, err := worktree.Commit("feat: first", &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+			require.NoError(t, err)
+			for _, branch := range tt.branches {
+				require.NoError(t, r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head)))
+			}
+
+			f := &fakeBranchCleaner{dir: dir, prs: tt.prs, failDelete: tt.failDelete, comments: map[int]string{}}
+			rp := New(f, Options{Logger: slog.Default(), Packages: tt.packages})
+
+			rp.runCleanup(context.Background())
+
+			assert.Equal(t, tt.wantClosed, f.closed)
+			for _, id := range tt.wantClosed {
+				assert.Contains(t, f.comments[id], "superseded")
+			}
+
+			branches, err := git.RemoteBranches(context.Background(), dir, nil, releaseBranchPrefix)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBranches, branches)
+
+			// Other branches are not touched
+			_, err = r.Reference(plumbing.NewBranchReferenceName("master"), false)
+			assert.NoError(t, err)
+		})
+	}
+}
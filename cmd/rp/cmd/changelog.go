@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/config"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+const (
+	OutputText     = "text"
+	OutputMarkdown = "markdown"
+	OutputJSON     = "json"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Print the changelog entry for the commits in the local repository between two revisions",
+	Long: `Print the changelog entry for the commits in the local repository between two revisions.
+
+No branches, pull requests or releases are created. Pull request descriptions are not considered, the changelog
+is built from the commit messages only.`,
+	Args: cobra.NoArgs,
+	RunE: runChangelog,
+}
+
+var (
+	flagChangelogFrom    string
+	flagChangelogTo      string
+	flagChangelogVersion string
+	flagChangelogOutput  string
+	flagChangelogConfig  string
+)
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.Flags().StringVar(&flagChangelogFrom, "from", "", "Revision of the previous release, all commits are used if empty")
+	changelogCmd.Flags().StringVar(&flagChangelogTo, "to", "HEAD", "Revision of the new release")
+	changelogCmd.Flags().StringVar(&flagChangelogVersion, "version", "", "Version used as the title of the entry, the title is omitted if empty")
+	changelogCmd.Flags().StringVar(&flagChangelogOutput, "output", OutputMarkdown, "Output format: markdown or json")
+	changelogCmd.Flags().StringVar(&flagChangelogConfig, "config", config.DefaultPath, "")
+}
+
+func runChangelog(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := config.Load(flagChangelogConfig)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.OpenRepo(logger, ".")
+	if err != nil {
+		return err
+	}
+
+	commits, err := repo.CommitsBetween(ctx, flagChangelogFrom, flagChangelogTo, "")
+	if err != nil {
+		return err
+	}
+	commits = git.DropReverted(commits)
+
+	sections := changelogSectionsFromConfig(cfg.Changelog)
+
+	commitParser, err := cfg.Commits.CommitParser(logger, changelog.Types(sections)...)
+	if err != nil {
+		return err
+	}
+
+	analyzedCommits, err := commitParser.Analyze(commits)
+	if err != nil {
+		return err
+	}
+	changelog.Sort(analyzedCommits, cfg.Changelog.Sort)
+
+	tpl, err := changelog.LoadTemplate(func(path string) ([]byte, error) { return repo.ReadFile(ctx, path) })
+	if err != nil {
+		return err
+	}
+
+	data := changelog.New(analyzedCommits, sections, changelogScopesFromConfig(cfg.Changelog), flagChangelogVersion, "", "", "")
+
+	return writeChangelog(cmd.OutOrStdout(), tpl, data, flagChangelogOutput)
+}
+
+type changelogOutput struct {
+	Version  string                   `json:"version,omitempty"`
+	Sections []changelogSectionOutput `json:"sections"`
+}
+
+type changelogSectionOutput struct {
+	Title   string                  `json:"title"`
+	Commits []changelogCommitOutput `json:"commits"`
+}
+
+type changelogCommitOutput struct {
+	Hash           string  `json:"hash"`
+	Type           string  `json:"type"`
+	Scope          *string `json:"scope,omitempty"`
+	Description    string  `json:"description"`
+	BreakingChange bool    `json:"breaking_change"`
+}
+
+func writeChangelog(w io.Writer, tpl *template.Template, data changelog.Data, output string) error {
+	switch output {
+	case OutputMarkdown:
+		entry, err := changelog.Entry(logger, tpl, data, changelog.Formatting{HideVersionTitle: data.Version == ""})
+		if err != nil {
+			return fmt.Errorf("failed to build changelog entry: %w", err)
+		}
+
+		_, err = fmt.Fprint(w, entry)
+		return err
+	case OutputJSON:
+		out := changelogOutput{
+			Version:  data.Version,
+			Sections: make([]changelogSectionOutput, 0, len(data.Sections)),
+		}
+		for _, section := range data.Sections {
+			sectionOut := changelogSectionOutput{
+				Title:   section.Title,
+				Commits: make([]changelogCommitOutput, 0, len(section.Commits)),
+			}
+			for _, commit := range section.Commits {
+				sectionOut.Commits = append(sectionOut.Commits, changelogCommitOutput{
+					Hash:           commit.Hash,
+					Type:           commit.Type,
+					Scope:          commit.Scope,
+					Description:    commit.Description,
+					BreakingChange: commit.BreakingChange,
+				})
+			}
+			out.Sections = append(out.Sections, sectionOut)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("unknown --output: %s", output)
+	}
+}
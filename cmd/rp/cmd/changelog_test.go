@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func Test_writeChangelog(t *testing.T) {
+	scope := "api"
+	data := changelog.New([]commitparser.AnalyzedCommit{
+		{Commit: git.Commit{Hash: "abc"}, Type: "feat", Scope: &scope, Description: "Foobar!"},
+		{Commit: git.Commit{Hash: "def"}, Type: "fix", Description: "Fixed!"},
+	}, changelog.DefaultSections, changelog.Scopes{}, "", "", "", "")
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "markdown",
+			output:  OutputMarkdown,
+			want:    "### Features\n\n- **api**: Foobar!\n\n### Bug Fixes\n\n- Fixed!\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "json",
+			output: OutputJSON,
+			want: `{
+  "sections": [
+    {
+      "title": "Features",
+      "commits": [
+        {
+          "hash": "abc",
+          "type": "feat",
+          "scope": "api",
+          "description": "Foobar!",
+          "breaking_change": false
+        }
+      ]
+    },
+    {
+      "title": "Bug Fixes",
+      "commits": [
+        {
+          "hash": "def",
+          "type": "fix",
+          "description": "Fixed!",
+          "breaking_change": false
+        }
+      ]
+    }
+  ]
+}
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown",
+			output:  "yaml",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeChangelog(&buf, changelog.DefaultTemplate(), data, tt.output)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
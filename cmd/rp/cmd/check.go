@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	rp "github.com/apricote/releaser-pleaser"
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/config"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that commits are valid and listed in the changelog",
+	Long: `Check that commits are valid and listed in the changelog.
+
+The commits in the local repository between two revisions are checked, e.g. the commits of a pull request. With
+--message, the given messages are checked instead, e.g. the title of a pull request that is squash merged. The
+command fails if any commit can not be parsed by the configured commit parser or its type has no section in the changelog. Merge commits
+and commits with the "Changelog: skip" trailer are ignored.`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+var (
+	flagCheckFrom     string
+	flagCheckTo       string
+	flagCheckMessages []string
+	flagCheckOutput   string
+	flagCheckConfig   string
+)
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringVar(&flagCheckFrom, "from", "", "Revision of the target branch, all commits are checked if empty")
+	checkCmd.Flags().StringVar(&flagCheckTo, "to", "HEAD", "Revision of the last commit to check")
+	checkCmd.Flags().StringArrayVar(&flagCheckMessages, "message", nil, "Check the message instead of the commits in the repository, can be repeated")
+	checkCmd.Flags().StringVar(&flagCheckOutput, "output", OutputText, "Output format: text or json")
+	checkCmd.Flags().StringVar(&flagCheckConfig, "config", config.DefaultPath, "")
+}
+
+func runCheck(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	if flagCheckOutput != OutputText && flagCheckOutput != OutputJSON {
+		return fmt.Errorf("unknown --output: %s", flagCheckOutput)
+	}
+
+	cfg, err := config.Load(flagCheckConfig)
+	if err != nil {
+		return err
+	}
+
+	var commits []git.Commit
+	if len(flagCheckMessages) > 0 {
+		for _, message := range flagCheckMessages {
+			commits = append(commits, git.Commit{Message: message})
+		}
+	} else {
+		repo, err := git.OpenRepo(logger, ".")
+		if err != nil {
+			return err
+		}
+
+		commits, err = repo.CommitsBetween(ctx, flagCheckFrom, flagCheckTo, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	sections := changelogSectionsFromConfig(cfg.Changelog)
+
+	commitParser, err := cfg.Commits.CommitParser(logger, changelog.Types(sections)...)
+	if err != nil {
+		return err
+	}
+
+	report := rp.Check(commitParser, commits, sections, changelogScopesFromConfig(cfg.Changelog))
+
+	if err = writeCheckReport(cmd.OutOrStdout(), report, flagCheckOutput); err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		// The report already explains the problems, the usage is not helpful.
+		cmd.SilenceUsage = true
+		return fmt.Errorf("some commits are not listed in the changelog")
+	}
+
+	return nil
+}
+
+type checkOutput struct {
+	OK      bool                `json:"ok"`
+	Commits []checkCommitOutput `json:"commits"`
+}
+
+type checkCommitOutput struct {
+	Hash    string `json:"hash,omitempty"`
+	Subject string `json:"subject"`
+	Status  string `json:"status"`
+	Type    string `json:"type,omitempty"`
+	Section string `json:"section,omitempty"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// writeCheckReport prints one line per commit with its status, the short hash and the subject, followed by the
+// changelog section or the problem.
+func writeCheckReport(w io.Writer, report rp.CheckReport, output string) error {
+	switch output {
+	case OutputText:
+		for _, result := range report.Commits {
+			line := fmt.Sprintf("%-7s ", result.Status)
+			if result.Hash != "" {
+				line += shortHash(result.Hash) + " "
+			}
+			line += result.Subject
+
+			if result.Section != "" {
+				line += " (" + result.Section + ")"
+			} else if result.Problem != "" {
+				line += ": " + result.Problem
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case OutputJSON:
+		out := checkOutput{
+			OK:      report.OK(),
+			Commits: make([]checkCommitOutput, 0, len(report.Commits)),
+		}
+		for _, result := range report.Commits {
+			out.Commits = append(out.Commits, checkCommitOutput{
+				Hash:    result.Hash,
+				Subject: result.Subject,
+				Status:  string(result.Status),
+				Type:    result.Type,
+				Section: result.Section,
+				Problem: result.Problem,
+			})
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("unknown --output: %s", output)
+	}
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
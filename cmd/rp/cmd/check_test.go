@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rp "github.com/apricote/releaser-pleaser"
+)
+
+func Test_writeCheckReport(t *testing.T) {
+	report := rp.CheckReport{Commits: []rp.CheckResult{
+		{Hash: "1234567890abcdef", Subject: "feat: add foo", Status: rp.CheckStatusOK, Type: "feat", Section: "Features"},
+		{Hash: "abcdef1234567890", Subject: "Update the bar", Status: rp.CheckStatusInvalid, Problem: "not a conventional commit: missing colon"},
+		{Subject: "chore: tooling", Status: rp.CheckStatusHidden, Type: "chore", Problem: `type "chore" has no section in the changelog`},
+	}}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "text",
+			output: OutputText,
+			want: `ok      1234567 feat: add foo (Features)
+invalid abcdef1 Update the bar: not a conventional commit: missing colon
+hidden  chore: tooling: type "chore" has no section in the changelog
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "json",
+			output: OutputJSON,
+			want: `{
+  "ok": false,
+  "commits": [
+    {
+      "hash": "1234567890abcdef",
+      "subject": "feat: add foo",
+      "status": "ok",
+      "type": "feat",
+      "section": "Features"
+    },
+    {
+      "hash": "abcdef1234567890",
+      "subject": "Update the bar",
+      "status": "invalid",
+      "problem": "not a conventional commit: missing colon"
+    },
+    {
+      "subject": "chore: tooling",
+      "status": "hidden",
+      "type": "chore",
+      "problem": "type \"chore\" has no section in the changelog"
+    }
+  ]
+}
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown",
+			output:  "yaml",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeCheckReport(&buf, report, tt.output)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
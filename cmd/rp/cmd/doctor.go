@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	rp "github.com/apricote/releaser-pleaser"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the token has all permissions required by run",
+	Long: `Check that the token has all permissions required by run.
+
+Every action of run is checked: cloning the repository, reading tags, commits and pull requests, pushing branches
+and creating labels, pull requests and releases. Missing permissions are reported with the scope or permission that
+the token needs on the forge. Nothing is changed on the forge. The command fails if any permission is missing.
+
+The same checks run automatically at the start of run.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var flagDoctorOutput string
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	addForgeFlags(doctorCmd.Flags())
+	doctorCmd.Flags().StringVar(&flagDoctorOutput, "output", OutputText, "Output format: text or json")
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	if flagDoctorOutput != OutputText && flagDoctorOutput != OutputJSON {
+		return fmt.Errorf("unknown --output: %s", flagDoctorOutput)
+	}
+
+	releaserPleaser, err := newReleaserPleaser(ctx, logger, targetFromFlags())
+	if err != nil {
+		return err
+	}
+
+	checks, err := releaserPleaser.Doctor(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = writeDoctorReport(cmd.OutOrStdout(), checks, flagDoctorOutput); err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(checks, func(check rp.PermissionCheck) bool { return check.Err != nil }) {
+		// The report already explains the problems, the usage is not helpful.
+		cmd.SilenceUsage = true
+		return rp.ErrMissingPermissions
+	}
+
+	return nil
+}
+
+type doctorOutput struct {
+	OK     bool                `json:"ok"`
+	Checks []doctorCheckOutput `json:"checks"`
+}
+
+type doctorCheckOutput struct {
+	Action     string `json:"action"`
+	Permission string `json:"permission"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+}
+
+// writeDoctorReport prints one line per check with its status, the action and the required permission, followed by
+// the response of the forge for missing permissions.
+func writeDoctorReport(w io.Writer, checks []rp.PermissionCheck, output string) error {
+	switch output {
+	case OutputText:
+		for _, check := range checks {
+			line := fmt.Sprintf("%-7s %s (%s)", "ok", check.Action, check.Permission)
+			if check.Err != nil {
+				line = fmt.Sprintf("%-7s %s (%s): %v", "missing", check.Action, check.Permission, check.Err)
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case OutputJSON:
+		out := doctorOutput{
+			OK:     true,
+			Checks: make([]doctorCheckOutput, 0, len(checks)),
+		}
+		for _, check := range checks {
+			result := doctorCheckOutput{Action: check.Action, Permission: check.Permission, OK: check.Err == nil}
+			if check.Err != nil {
+				result.Error = check.Err.Error()
+				out.OK = false
+			}
+			out.Checks = append(out.Checks, result)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("unknown --output: %s", output)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rp "github.com/apricote/releaser-pleaser"
+)
+
+func Test_writeDoctorReport(t *testing.T) {
+	checks := []rp.PermissionCheck{
+		{Action: "read tags", Permission: "contents: read"},
+		{Action: "create pull requests", Permission: "pull-requests: write", Err: errors.New("403 Resource not accessible by integration")},
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "text",
+			output: OutputText,
+			want: `ok      read tags (contents: read)
+missing create pull requests (pull-requests: write): 403 Resource not accessible by integration
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "json",
+			output: OutputJSON,
+			want: `{
+  "ok": false,
+  "checks": [
+    {
+      "action": "read tags",
+      "permission": "contents: read",
+      "ok": true
+    },
+    {
+      "action": "create pull requests",
+      "permission": "pull-requests: write",
+      "ok": false,
+      "error": "403 Resource not accessible by integration"
+    }
+  ]
+}
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown",
+			output:  "yaml",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeDoctorReport(&buf, checks, tt.output)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
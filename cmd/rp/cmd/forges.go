@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	rp "github.com/apricote/releaser-pleaser"
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/forge/bitbucket"
+	"github.com/apricote/releaser-pleaser/internal/forge/github"
+	"github.com/apricote/releaser-pleaser/internal/forge/gitlab"
+)
+
+func init() {
+	rp.RegisterForge("github", func(ctx context.Context, logger *slog.Logger, options rp.ForgeOptions) (rp.Forge, error) {
+		logger.DebugContext(ctx, "using forge GitHub")
+		f, err := github.New(logger, &github.Options{
+			Options:    forge.Options{Repository: options.Repo, BaseBranch: options.BaseBranch, Concurrency: options.Concurrency},
+			Owner:      options.Owner,
+			Repo:       options.Repo,
+			APIURL:     flagGitHubBaseURL,
+			MaxRetries: flagMaxRetries,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create github client: %w", err)
+		}
+		return f, nil
+	})
+
+	rp.RegisterForge("gitlab", func(ctx context.Context, logger *slog.Logger, options rp.ForgeOptions) (rp.Forge, error) {
+		logger.DebugContext(ctx, "using forge GitLab")
+		f, err := gitlab.New(logger, &gitlab.Options{
+			Options: forge.Options{Repository: options.Repo, BaseBranch: options.BaseBranch, Concurrency: options.Concurrency},
+			Path:    fmt.Sprintf("%s/%s", options.Owner, options.Repo),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create client", "err", err)
+			return nil, fmt.Errorf("failed to create gitlab client: %w", err)
+		}
+		return f, nil
+	})
+
+	rp.RegisterForge("bitbucket", func(ctx context.Context, logger *slog.Logger, options rp.ForgeOptions) (rp.Forge, error) {
+		logger.DebugContext(ctx, "using forge Bitbucket")
+		return bitbucket.New(logger, &bitbucket.Options{
+			Options:   forge.Options{Repository: options.Repo, BaseBranch: options.BaseBranch, Concurrency: options.Concurrency},
+			Workspace: options.Owner,
+			RepoSlug:  options.Repo,
+		}), nil
+	})
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	rp "github.com/apricote/releaser-pleaser"
+)
+
+var nextVersionCmd = &cobra.Command{
+	Use:   "next-version",
+	Short: "Print the version of the next release",
+	Long: `Print the version of the next release, computed from the commits since the latest tag.
+
+Overrides from an open release pull request are considered. Nothing is changed on the forge. If there are no
+releasable changes, nothing is printed.`,
+	Args: cobra.NoArgs,
+	RunE: runNextVersion,
+}
+
+var (
+	flagNextVersionOutput   string
+	flagNextVersionPrevious bool
+)
+
+func init() {
+	rootCmd.AddCommand(nextVersionCmd)
+
+	addForgeFlags(nextVersionCmd.Flags())
+	nextVersionCmd.Flags().StringVar(&flagNextVersionOutput, "output", OutputText, "Output format: text or json")
+	nextVersionCmd.Flags().BoolVar(&flagNextVersionPrevious, "previous", false, "Also print the tag of the previous release (text output only)")
+}
+
+func runNextVersion(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	if flagNextVersionOutput != OutputText && flagNextVersionOutput != OutputJSON {
+		return fmt.Errorf("unknown --output: %s", flagNextVersionOutput)
+	}
+
+	releaserPleaser, err := newReleaserPleaser(ctx, logger, targetFromFlags())
+	if err != nil {
+		return err
+	}
+
+	versions, err := releaserPleaser.NextVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeNextVersions(cmd.OutOrStdout(), versions, flagNextVersionOutput, flagNextVersionPrevious)
+}
+
+type nextVersionOutput struct {
+	Package     string `json:"package,omitempty"`
+	PreviousTag string `json:"previous_tag,omitempty"`
+	Version     string `json:"version"`
+}
+
+// writeNextVersions prints one line per package. The lines are prefixed with the name of the package, if it has one.
+// With previous, the previous tag (or "-" for the first release) is printed before the version.
+func writeNextVersions(w io.Writer, versions []rp.NextVersion, output string, previous bool) error {
+	switch output {
+	case OutputText:
+		for _, version := range versions {
+			line := version.Version
+			if previous {
+				previousTag := version.PreviousTag
+				if previousTag == "" {
+					previousTag = "-"
+				}
+				line = previousTag + " " + line
+			}
+			if version.Package != "" {
+				line = version.Package + " " + line
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case OutputJSON:
+		out := make([]nextVersionOutput, 0, len(versions))
+		for _, version := range versions {
+			out = append(out, nextVersionOutput(version))
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("unknown --output: %s", output)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rp "github.com/apricote/releaser-pleaser"
+)
+
+func Test_writeNextVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []rp.NextVersion
+		output   string
+		previous bool
+		want     string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "no release",
+			versions: []rp.NextVersion{},
+			output:   OutputText,
+			want:     "",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "single package",
+			versions: []rp.NextVersion{{PreviousTag: "v1.2.0", Version: "v1.3.0"}},
+			output:   OutputText,
+			want:     "v1.3.0\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "previous",
+			versions: []rp.NextVersion{{PreviousTag: "v1.2.0", Version: "v1.3.0"}},
+			output:   OutputText,
+			previous: true,
+			want:     "v1.2.0 v1.3.0\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name: "packages",
+			versions: []rp.NextVersion{
+				{Package: "api", PreviousTag: "api/v1.2.0", Version: "api/v1.3.0"},
+				{Package: "web", Version: "web/v1.0.0"},
+			},
+			output:   OutputText,
+			previous: true,
+			want:     "api api/v1.2.0 api/v1.3.0\nweb - web/v1.0.0\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "json",
+			versions: []rp.NextVersion{{PreviousTag: "v1.2.0", Version: "v1.3.0"}},
+			output:   OutputJSON,
+			want:     "[\n  {\n    \"previous_tag\": \"v1.2.0\",\n    \"version\": \"v1.3.0\"\n  }\n]\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "unknown output",
+			versions: []rp.NextVersion{},
+			output:   "yaml",
+			want:     "",
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeNextVersions(&buf, tt.versions, tt.output, tt.previous)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	rp "github.com/apricote/releaser-pleaser"
+	"github.com/apricote/releaser-pleaser/internal/forge/github"
+)
+
+// writeActionOutputsFile appends the step outputs to the file from $GITHUB_OUTPUT, if running in GitHub Actions.
+func writeActionOutputsFile(result rp.Result) error {
+	path := os.Getenv(github.EnvOutput)
+	if !github.InActions() || path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open github actions output file: %w", err)
+	}
+	defer file.Close()
+
+	return writeActionOutputs(file, result)
+}
+
+// writeActionOutputs writes the step outputs in the format of $GITHUB_OUTPUT. In repositories with multiple packages,
+// the outputs describe the first created release and the first release pull request.
+func writeActionOutputs(w io.Writer, result rp.Result) error {
+	outputs := [][2]string{
+		{"release_created", strconv.FormatBool(len(result.Releases) > 0)},
+	}
+
+	if len(result.Releases) > 0 {
+		release := result.Releases[0]
+		outputs = append(outputs,
+			[2]string{"version", release.TagName},
+			[2]string{"upload_url", release.UploadURL},
+		)
+	}
+
+	if len(result.PullRequests) > 0 {
+		outputs = append(outputs, [2]string{"pr_number", strconv.Itoa(result.PullRequests[0].ID)})
+	}
+
+	for _, output := range outputs {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", output[0], output[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type runOutput struct {
+	ReleaseCreated bool                   `json:"release_created"`
+	Releases       []runReleaseOutput     `json:"releases"`
+	PullRequests   []runPullRequestOutput `json:"pull_requests"`
+}
+
+type runReleaseOutput struct {
+	TagName    string `json:"tag_name"`
+	URL        string `json:"url"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type runPullRequestOutput struct {
+	Package     string `json:"package,omitempty"`
+	Number      int    `json:"number"`
+	URL         string `json:"url"`
+	PreviousTag string `json:"previous_tag,omitempty"`
+	NextVersion string `json:"next_version"`
+	Commits     int    `json:"commits"`
+	Pushed      bool   `json:"pushed"`
+}
+
+// writeRunResult writes the summary of the run in the requested format. The text format is a no-op, the logs already
+// contain all information.
+func writeRunResult(w io.Writer, result rp.Result, output string) error {
+	switch output {
+	case OutputText:
+		return nil
+	case OutputJSON:
+		out := runOutput{
+			ReleaseCreated: len(result.Releases) > 0,
+			Releases:       make([]runReleaseOutput, 0, len(result.Releases)),
+			PullRequests:   make([]runPullRequestOutput, 0, len(result.PullRequests)),
+		}
+		for _, release := range result.Releases {
+			out.Releases = append(out.Releases, runReleaseOutput{
+				TagName:    release.TagName,
+				URL:        release.URL,
+				Prerelease: release.Prerelease,
+			})
+		}
+		for _, pr := range result.PullRequests {
+			out.PullRequests = append(out.PullRequests, runPullRequestOutput{
+				Package:     pr.Package,
+				Number:      pr.ID,
+				URL:         pr.URL,
+				PreviousTag: pr.PreviousTag,
+				NextVersion: pr.Version,
+				Commits:     pr.Commits,
+				Pushed:      pr.Pushed,
+			})
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("unknown --output: %s", output)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rp "github.com/apricote/releaser-pleaser"
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+func Test_writeActionOutputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		result rp.Result
+		want   string
+	}{
+		{
+			name:   "nothing changed",
+			result: rp.Result{},
+			want:   "release_created=false\n",
+		},
+		{
+			name: "release created",
+			result: rp.Result{
+				Releases: []forge.Release{{TagName: "v1.2.0", UploadURL: "https://uploads.github.com/repos/foo/bar/releases/1/assets{?name,label}"}},
+			},
+			want: "release_created=true\nversion=v1.2.0\nupload_url=https://uploads.github.com/repos/foo/bar/releases/1/assets{?name,label}\n",
+		},
+		{
+			name: "pull request opened",
+			result: rp.Result{
+				PullRequests: []rp.PullRequestResult{{ID: 42, Version: "v1.3.0"}},
+			},
+			want: "release_created=false\npr_number=42\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeActionOutputs(&buf, tt.result)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func Test_writeRunResult(t *testing.T) {
+	result := rp.Result{
+		Releases: []forge.Release{{TagName: "v1.2.0", URL: "https://example.com/releases/v1.2.0"}},
+		PullRequests: []rp.PullRequestResult{{
+			ID:          42,
+			URL:         "https://example.com/pulls/42",
+			PreviousTag: "v1.2.0",
+			Version:     "v1.3.0",
+			Commits:     3,
+			Pushed:      true,
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "text",
+			output:  OutputText,
+			want:    "",
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "json",
+			output: OutputJSON,
+			want: `{
+  "release_created": true,
+  "releases": [
+    {
+      "tag_name": "v1.2.0",
+      "url": "https://example.com/releases/v1.2.0",
+      "prerelease": false
+    }
+  ],
+  "pull_requests": [
+    {
+      "number": 42,
+      "url": "https://example.com/pulls/42",
+      "previous_tag": "v1.2.0",
+      "next_version": "v1.3.0",
+      "commits": 3,
+      "pushed": true
+    }
+  ]
+}
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown",
+			output:  "yaml",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeRunResult(&buf, result, tt.output)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
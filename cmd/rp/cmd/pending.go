@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ExitCodeNothingPending is the exit code of rp pending if there are no releasable changes.
+const ExitCodeNothingPending = 2
+
+var pendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "Check if there are releasable changes since the latest release",
+	Long: `Check if there are releasable changes since the latest release.
+
+If there are releasable changes, the version of the next release is printed like with next-version and the exit
+code is 0. If there is nothing to release, nothing is printed and the exit code is 2. Other errors exit with 1.
+Nothing is changed on the forge.`,
+	Args: cobra.NoArgs,
+	RunE: runPending,
+}
+
+var flagPendingOutput string
+
+func init() {
+	rootCmd.AddCommand(pendingCmd)
+
+	addForgeFlags(pendingCmd.Flags())
+	pendingCmd.Flags().StringVar(&flagPendingOutput, "output", OutputText, "Output format: text or json")
+}
+
+func runPending(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	if flagPendingOutput != OutputText && flagPendingOutput != OutputJSON {
+		return fmt.Errorf("unknown --output: %s", flagPendingOutput)
+	}
+
+	releaserPleaser, err := newReleaserPleaser(ctx, logger, targetFromFlags())
+	if err != nil {
+		return err
+	}
+
+	versions, err := releaserPleaser.NextVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		logger.InfoContext(ctx, "no releasable changes since the latest release")
+
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return exitCodeError{code: ExitCodeNothingPending}
+	}
+
+	return writeNextVersions(cmd.OutOrStdout(), versions, flagPendingOutput, false)
+}
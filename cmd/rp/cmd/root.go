@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"runtime/debug"
@@ -39,10 +42,37 @@ func version() string {
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	ctx := context.Background()
+
+	shutdownTracing, err := setupTracing(ctx)
 	if err != nil {
+		logger.ErrorContext(ctx, "failed to set up tracing", "err", err)
 		os.Exit(1)
 	}
+
+	err = rootCmd.ExecuteContext(ctx)
+
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		logger.WarnContext(ctx, "failed to export traces", "err", shutdownErr)
+	}
+
+	if err != nil {
+		var exitErr exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(1)
+	}
+}
+
+// exitCodeError ends the rp command with the exit code. Commands return it after they already reported the reason, so
+// they should also set cobra.Command.SilenceErrors.
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
 }
 
 func init() {
@@ -1,18 +1,34 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
 	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	rp "github.com/apricote/releaser-pleaser"
-	"github.com/apricote/releaser-pleaser/internal/commitparser/conventionalcommits"
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/config"
 	"github.com/apricote/releaser-pleaser/internal/forge"
 	"github.com/apricote/releaser-pleaser/internal/forge/github"
 	"github.com/apricote/releaser-pleaser/internal/forge/gitlab"
+	"github.com/apricote/releaser-pleaser/internal/forge/local"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/notify"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
 	"github.com/apricote/releaser-pleaser/internal/updater"
-	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+const (
+	EnvSigningKey           = "RELEASER_PLEASER_SIGNING_KEY"
+	EnvSigningKeyPassphrase = "RELEASER_PLEASER_SIGNING_KEY_PASSPHRASE"
 )
 
 var runCmd = &cobra.Command{
@@ -26,72 +42,335 @@ var (
 	flagOwner      string
 	flagRepo       string
 	flagExtraFiles string
+	flagConfig     string
+	flagCloneDepth int
+	flagCloneMode  string
+	flagCommitMode string
+
+	flagSigningKeyFile string
+	flagCommitterName  string
+	flagCommitterEmail string
+
+	flagDiscussionCategory string
+	flagMaxRetries         int
+	flagGitHubBaseURL      string
+	flagConcurrency        int
+
+	flagOutput string
+	flagLocal  string
 )
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 
-	runCmd.PersistentFlags().StringVar(&flagForge, "forge", "", "")
-	runCmd.PersistentFlags().StringVar(&flagBranch, "branch", "main", "")
-	runCmd.PersistentFlags().StringVar(&flagOwner, "owner", "", "")
-	runCmd.PersistentFlags().StringVar(&flagRepo, "repo", "", "")
-	runCmd.PersistentFlags().StringVar(&flagExtraFiles, "extra-files", "", "")
+	addForgeFlags(runCmd.PersistentFlags())
+	addReleaseFlags(runCmd.PersistentFlags())
+	runCmd.PersistentFlags().StringVar(&flagOutput, "output", OutputText, "Output format of the summary on stdout: text or json")
+	runCmd.Flags().StringVar(&flagLocal, "local", "", "Path of a local clone to preview the release pull request in, the release branch is written into it and the pull request is printed to stderr")
+}
+
+// addForgeFlags adds the flags to select the forge and repository. They are shared by all commands that access the
+// forge.
+func addForgeFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&flagForge, "forge", "", "Forge of the repository, defaults to gitlab in GitLab CI/CD")
+	flags.StringVar(&flagBranch, "branch", "", "Branch that is released, defaults to the default branch in GitLab CI/CD and main otherwise")
+	flags.StringVar(&flagOwner, "owner", "", "")
+	flags.StringVar(&flagRepo, "repo", "", "")
+	flags.StringVar(&flagConfig, "config", config.DefaultPath, "")
+	addAPIFlags(flags)
+}
+
+// addAPIFlags adds the flags that configure the access to the API of the forge, independent of the repository.
+func addAPIFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&flagGitHubBaseURL, "github-base-url", "", "Base URL of the GitHub Enterprise Server API, e.g. https://github.example.com/api/v3/, alternatively set "+github.EnvAPIURL)
+	flags.IntVar(&flagConcurrency, "concurrency", forge.DefaultConcurrency, "Number of API requests that are made at the same time to look up the pull requests of commits")
+	flags.IntVar(&flagMaxRetries, "max-retries", github.DefaultMaxRetries, "Number of retries for API requests that hit a rate limit, negative values disable retries (GitHub only)")
+}
+
+// addReleaseFlags adds the flags that configure how releases and release pull requests are created. They are shared
+// by all commands that change the repository.
+func addReleaseFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&flagExtraFiles, "extra-files", "", "")
+	flags.IntVar(&flagCloneDepth, "clone-depth", 0, "Number of commits to fetch per branch, 0 fetches the full history")
+	flags.StringVar(&flagCloneMode, "clone-mode", string(git.CloneModeDisk), "Where to store the cloned repository: disk or memory")
+	flags.StringVar(&flagCommitMode, "commit-mode", string(rp.CommitModeGit), "How the release commit is pushed: git, or api to create it through the API of the forge (GitHub and GitLab only)")
+	flags.StringVar(&flagSigningKeyFile, "signing-key-file", "", "GPG or SSH private key to sign release commits and tags, alternatively set "+EnvSigningKey)
+	flags.StringVar(&flagCommitterName, "committer-name", git.DefaultIdentity.Name, "Name used for release commits and tags")
+	flags.StringVar(&flagCommitterEmail, "committer-email", git.DefaultIdentity.Email, "Email used for release commits and tags")
+	flags.StringVar(&flagDiscussionCategory, "discussion-category", "", "Create a discussion in this category for every release (GitHub only)")
+}
+
+// target is the repository and branch that releaser-pleaser runs for.
+type target struct {
+	Forge  string
+	Owner  string
+	Repo   string
+	Branch string
+	// Config is the path of the config file.
+	Config string
+	// Local is the path of a local clone that is used instead of the forge, see --local.
+	Local string
+}
+
+// targetFromFlags returns the target that was selected with the flags of addForgeFlags. In GitLab CI/CD, the forge and
+// branch default to the project of the job. In GitHub Actions, the branch defaults to the branch of the workflow run.
+func targetFromFlags() target {
+	t := target{Forge: flagForge, Owner: flagOwner, Repo: flagRepo, Branch: flagBranch, Config: flagConfig, Local: flagLocal}
+
+	if t.Forge == "" && gitlab.InCI() {
+		t.Forge = "gitlab"
+	}
+	if t.Branch == "" && t.Forge == "gitlab" && gitlab.InCI() {
+		t.Branch = os.Getenv(gitlab.EnvDefaultBranch)
+	}
+	if t.Branch == "" && t.Forge == "github" && github.InActions() {
+		t.Branch = github.ActionsBranch()
+	}
+	if t.Branch == "" {
+		t.Branch = rp.DefaultTargetBranch
+	}
+
+	return t
 }
 
 func run(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 
-	var err error
-
 	logger.DebugContext(ctx, "run called",
 		"forge", flagForge,
 		"branch", flagBranch,
 		"owner", flagOwner,
 		"repo", flagRepo,
+		"config", flagConfig,
+		"clone-depth", flagCloneDepth,
+		"clone-mode", flagCloneMode,
+		"commit-mode", flagCommitMode,
+		"signing-key-file", flagSigningKeyFile,
+		"committer-name", flagCommitterName,
+		"committer-email", flagCommitterEmail,
+		"discussion-category", flagDiscussionCategory,
+		"concurrency", flagConcurrency,
+		"output", flagOutput,
+		"local", flagLocal,
 	)
 
-	var f forge.Forge
+	if flagOutput != OutputText && flagOutput != OutputJSON {
+		return fmt.Errorf("unknown --output: %s", flagOutput)
+	}
+
+	releaserPleaser, err := newReleaserPleaser(ctx, logger, targetFromFlags())
+	if err != nil {
+		return err
+	}
 
-	forgeOptions := forge.Options{
-		Repository: flagRepo,
-		BaseBranch: flagBranch,
+	err = releaserPleaser.Run(ctx)
+	if err != nil {
+		return err
 	}
 
-	switch flagForge {
-	case "gitlab":
-		logger.DebugContext(ctx, "using forge GitLab")
-		f, err = gitlab.New(logger, &gitlab.Options{
-			Options: forgeOptions,
-			Path:    fmt.Sprintf("%s/%s", flagOwner, flagRepo),
+	if err = writeRunResult(cmd.OutOrStdout(), releaserPleaser.Result(), flagOutput); err != nil {
+		return err
+	}
+
+	return writeActionOutputsFile(releaserPleaser.Result())
+}
+
+// newReleaserPleaser sets up the forge and the ReleaserPleaser for the target from the flags and the config file.
+func newReleaserPleaser(ctx context.Context, logger *slog.Logger, t target) (*rp.ReleaserPleaser, error) {
+	cfg, err := config.Load(t.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := forgeForTarget(ctx, logger, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var announcers []forge.ReleaseAnnouncer
+	if gh, ok := f.(*github.GitHub); ok && flagDiscussionCategory != "" {
+		announcers = append(announcers, gh.DiscussionAnnouncer(flagDiscussionCategory))
+	}
+	notifications, err := notificationsFromConfig(cfg.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	announcers = append(announcers, notifications...)
+
+	packages := packagesFromConfig(cfg)
+	if len(packages) == 0 {
+		extraFiles := releaseTypeFiles("", cfg.ReleaseType)
+		for _, path := range parseExtraFiles(flagExtraFiles) {
+			extraFiles = append(extraFiles, rp.ExtraFile{Path: path, Updaters: []updater.NewUpdater{updater.Generic}})
+		}
+		extraFiles = append(extraFiles, extraFilesFromConfig(cfg.ExtraFiles)...)
+
+		tagPrefix := rp.DefaultTagPrefix
+		if cfg.TagPrefix != nil {
+			tagPrefix = *cfg.TagPrefix
+		}
+
+		packages = []rp.Package{{
+			TagPrefix:       tagPrefix,
+			ExtraFiles:      extraFiles,
+			ReleaseCommands: cfg.ReleaseCommands,
+			Assets:          rp.Assets(cfg.Assets),
+		}}
+	}
+
+	sections := changelogSectionsFromConfig(cfg.Changelog)
+
+	versioningStrategy, err := cfg.Versioning.Strategy()
+	if err != nil {
+		return nil, err
+	}
+
+	commitParser, err := cfg.Commits.CommitParser(logger, changelog.Types(sections)...)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := signerFromFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	prTitleTemplate, err := titleTemplateFromConfig("pr-title-template", cfg.PullRequestTitleTemplate)
+	if err != nil {
+		return nil, err
+	}
+	releaseCommitTemplate, err := titleTemplateFromConfig("release-commit-template", cfg.ReleaseCommitTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.New(f, rp.Options{
+		Logger:                   logger,
+		TargetBranch:             t.Branch,
+		Versioning:               versioningStrategy,
+		RequireMajorLabel:        cfg.Versioning.RequireMajorLabel,
+		Packages:                 packages,
+		Sections:                 sections,
+		CommitParser:             commitParser,
+		Scopes:                   changelogScopesFromConfig(cfg.Changelog),
+		LinkedIssues:             cfg.Changelog.LinkedIssues,
+		ChangelogPreamble:        cfg.Changelog.Preamble,
+		ChangelogFormat:          cfg.Changelog.Format,
+		ChangelogOrder:           cfg.Changelog.Sort,
+		Authors:                  cfg.Changelog.Authors,
+		NewContributors:          cfg.Changelog.NewContributors,
+		Dependencies:             dependencyUpdatesFromConfig(cfg.Changelog.Dependencies),
+		ChangelogPath:            cfg.Changelog.Path,
+		ChangelogFiles:           changelogFilesFromConfig(cfg.Changelog.Files),
+		Announcers:               announcers,
+		Maintenance:              cfg.IsMaintenanceBranch(t.Branch),
+		PullRequestTitleTemplate: prTitleTemplate,
+		ReleaseCommitTemplate:    releaseCommitTemplate,
+		Clone:                    git.CloneOptions{Mode: git.CloneMode(flagCloneMode), Depth: flagCloneDepth},
+		CommitMode:               rp.CommitMode(flagCommitMode),
+		Commit: git.CommitOptions{
+			Identity: git.Identity{Name: flagCommitterName, Email: flagCommitterEmail},
+			Signer:   signer,
+		},
+	}), nil
+}
+
+// forgeForTarget creates the forge of the target. With --local, the local clone is used instead.
+func forgeForTarget(ctx context.Context, logger *slog.Logger, t target) (rp.Forge, error) {
+	if t.Local != "" {
+		logger.DebugContext(ctx, "using local repository", "path", t.Local)
+		f, err := local.New(logger, &local.Options{
+			Options: forge.Options{BaseBranch: t.Branch},
+			Path:    t.Local,
+			Preview: os.Stderr,
 		})
 		if err != nil {
-			logger.ErrorContext(ctx, "failed to create client", "err", err)
-			return fmt.Errorf("failed to create gitlab client: %w", err)
+			return nil, fmt.Errorf("failed to open local repository: %w", err)
 		}
-	case "github":
-		logger.DebugContext(ctx, "using forge GitHub")
-		f = github.New(logger, &github.Options{
-			Options: forgeOptions,
-			Owner:   flagOwner,
-			Repo:    flagRepo,
-		})
-	default:
-		return fmt.Errorf("unknown --forge: %s", flagForge)
+		return f, nil
 	}
 
-	extraFiles := parseExtraFiles(flagExtraFiles)
+	return rp.NewForge(ctx, logger, t.Forge, rp.ForgeOptions{
+		Owner:       t.Owner,
+		Repo:        t.Repo,
+		BaseBranch:  t.Branch,
+		Concurrency: flagConcurrency,
+	})
+}
 
-	releaserPleaser := rp.New(
-		f,
-		logger,
-		flagBranch,
-		conventionalcommits.NewParser(logger),
-		versioning.SemVer,
-		extraFiles,
-		[]updater.NewUpdater{updater.Generic},
-	)
+// titleTemplateFromConfig parses a template of the config file. It returns nil if the template is not set, so the
+// default is used.
+func titleTemplateFromConfig(name, raw string) (*template.Template, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	tpl, err := releasepr.ParseTitleTemplate(name, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return tpl, nil
+}
+
+// notificationsFromConfig returns an announcer for every notification. The secrets are read from the environment.
+func notificationsFromConfig(cfg []config.Notification) ([]forge.ReleaseAnnouncer, error) {
+	announcers := make([]forge.ReleaseAnnouncer, 0, len(cfg))
+	for i, notification := range cfg {
+		tpl, err := notify.ParseTemplate(notification.Template)
+		if err != nil {
+			return nil, fmt.Errorf("notifications[%d]: invalid template: %w", i, err)
+		}
+
+		url := notification.URL
+		if notification.URLEnv != "" {
+			url = os.Getenv(notification.URLEnv)
+			if url == "" {
+				return nil, fmt.Errorf("notifications[%d]: environment variable %s is not set", i, notification.URLEnv)
+			}
+		}
+
+		switch notification.Type {
+		case config.NotificationTypeSlack:
+			announcers = append(announcers, notify.NewSlack(http.DefaultClient, url, tpl))
+		case config.NotificationTypeMatrix:
+			token := os.Getenv(notification.TokenEnv)
+			if token == "" {
+				return nil, fmt.Errorf("notifications[%d]: environment variable %s is not set", i, notification.TokenEnv)
+			}
+			announcers = append(announcers, notify.NewMatrix(http.DefaultClient, notification.Homeserver, notification.Room, token, tpl))
+		case config.NotificationTypeWebhook:
+			announcers = append(announcers, notify.NewWebhook(http.DefaultClient, url, tpl))
+		default:
+			return nil, fmt.Errorf("notifications[%d]: unknown type %q", i, notification.Type)
+		}
+	}
+
+	return announcers, nil
+}
+
+// signerFromFlags reads the signing key from --signing-key-file or the environment. It returns nil if no key is
+// configured.
+func signerFromFlags() (git.Signer, error) {
+	key := []byte(os.Getenv(EnvSigningKey))
+	if flagSigningKeyFile != "" {
+		var err error
+		key, err = os.ReadFile(flagSigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+	}
+
+	if len(key) == 0 {
+		return nil, nil
+	}
 
-	return releaserPleaser.Run(ctx)
+	signer, err := git.NewSigner(key, []byte(os.Getenv(EnvSigningKeyPassphrase)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	return signer, nil
 }
 
 func parseExtraFiles(input string) []string {
@@ -113,3 +392,87 @@ func parseExtraFiles(input string) []string {
 
 	return extraFiles
 }
+
+func packagesFromConfig(cfg config.Config) []rp.Package {
+	packages := make([]rp.Package, 0, len(cfg.Packages))
+	for _, pkg := range cfg.Packages {
+		packages = append(packages, rp.Package{
+			Name:            pkg.Name,
+			Path:            pkg.Path,
+			TagPrefix:       pkg.TagPrefix,
+			ExtraFiles:      append(releaseTypeFiles(pkg.Path, pkg.ReleaseType), extraFilesFromConfig(pkg.ExtraFiles)...),
+			ReleaseCommands: pkg.ReleaseCommands,
+			Assets:          rp.Assets(pkg.Assets),
+		})
+	}
+
+	return packages
+}
+
+func extraFilesFromConfig(files []config.ExtraFile) []rp.ExtraFile {
+	extraFiles := make([]rp.ExtraFile, 0, len(files))
+	for _, file := range files {
+		var newUpdater updater.NewUpdater
+		switch file.Type {
+		case config.ExtraFileTypeJSON:
+			newUpdater = updater.JSON(file.Key)
+		case config.ExtraFileTypeYAML:
+			newUpdater = updater.YAML(file.Key)
+		case config.ExtraFileTypeText:
+			newUpdater = updater.Text
+		default:
+			newUpdater = updater.Generic
+		}
+
+		extraFiles = append(extraFiles, rp.ExtraFile{Path: file.Path, Updaters: []updater.NewUpdater{newUpdater}})
+	}
+
+	return extraFiles
+}
+
+func releaseTypeFiles(packagePath, releaseType string) []rp.ExtraFile {
+	files := updater.ReleaseTypes[releaseType]
+
+	extraFiles := make([]rp.ExtraFile, 0, len(files))
+	for _, file := range files {
+		extraFiles = append(extraFiles, rp.ExtraFile{
+			Path:     path.Join(packagePath, file.Path),
+			Updaters: file.Updaters,
+			Optional: true,
+		})
+	}
+
+	return extraFiles
+}
+
+func changelogScopesFromConfig(cfg config.Changelog) changelog.Scopes {
+	return changelog.Scopes{Group: cfg.GroupByScope, Exclude: cfg.ExcludeScopes}
+}
+
+func changelogFilesFromConfig(cfg []config.ChangelogFile) []rp.ChangelogFile {
+	files := make([]rp.ChangelogFile, 0, len(cfg))
+	for _, file := range cfg {
+		files = append(files, rp.ChangelogFile{Path: file.Path, Format: file.Format, Template: file.Template})
+	}
+	return files
+}
+
+func dependencyUpdatesFromConfig(cfg config.ChangelogDependencies) rp.DependencyUpdates {
+	return rp.DependencyUpdates{Group: cfg.Group, Authors: cfg.Authors, BranchPrefixes: cfg.BranchPrefixes}
+}
+
+func changelogSectionsFromConfig(cfg config.Changelog) []changelog.Section {
+	if len(cfg.Sections) == 0 {
+		if cfg.Format == changelog.FormatKeepAChangelog {
+			return changelog.KeepAChangelogSections
+		}
+		return changelog.DefaultSections
+	}
+
+	sections := make([]changelog.Section, 0, len(cfg.Sections))
+	for _, section := range cfg.Sections {
+		sections = append(sections, changelog.Section{Type: section.Type, Title: section.Title})
+	}
+
+	return sections
+}
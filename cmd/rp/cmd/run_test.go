@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/config"
 )
 
 func Test_parseExtraFiles(t *testing.T) {
@@ -57,3 +59,118 @@ dir/Chart.yaml"`,
 		})
 	}
 }
+
+func Test_targetFromFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		forge  string
+		branch string
+		want   target
+	}{
+		{
+			name:  "defaults",
+			forge: "github",
+			want:  target{Forge: "github", Branch: "main", Config: config.DefaultPath},
+		},
+		{
+			name:   "flags",
+			env:    map[string]string{"GITLAB_CI": "true", "CI_DEFAULT_BRANCH": "develop"},
+			forge:  "gitlab",
+			branch: "release",
+			want:   target{Forge: "gitlab", Branch: "release", Config: config.DefaultPath},
+		},
+		{
+			name: "gitlab ci",
+			env:  map[string]string{"GITLAB_CI": "true", "CI_DEFAULT_BRANCH": "develop"},
+			want: target{Forge: "gitlab", Branch: "develop", Config: config.DefaultPath},
+		},
+		{
+			name:  "github actions",
+			env:   map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/develop"},
+			forge: "github",
+			want:  target{Forge: "github", Branch: "develop", Config: config.DefaultPath},
+		},
+		{
+			name:  "github actions triggered by a pull request",
+			env:   map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/12/merge"},
+			forge: "github",
+			want:  target{Forge: "github", Branch: "main", Config: config.DefaultPath},
+		},
+		{
+			name:   "branch flag in github actions",
+			env:    map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/develop"},
+			forge:  "github",
+			branch: "release",
+			want:   target{Forge: "github", Branch: "release", Config: config.DefaultPath},
+		},
+		{
+			name:  "other forge in gitlab ci",
+			env:   map[string]string{"GITLAB_CI": "true", "CI_DEFAULT_BRANCH": "develop"},
+			forge: "github",
+			want:  target{Forge: "github", Branch: "main", Config: config.DefaultPath},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITLAB_CI", "")
+			t.Setenv("CI_DEFAULT_BRANCH", "")
+			t.Setenv("GITHUB_ACTIONS", "")
+			t.Setenv("GITHUB_REF", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			flagForge, flagBranch = tt.forge, tt.branch
+			t.Cleanup(func() { flagForge, flagBranch = "", "" })
+
+			assert.Equal(t, tt.want, targetFromFlags())
+		})
+	}
+}
+
+func Test_notificationsFromConfig(t *testing.T) {
+	t.Setenv("RP_TEST_SLACK_URL", "https://hooks.slack.com/services/foo")
+	t.Setenv("RP_TEST_MATRIX_TOKEN", "secret")
+
+	tests := []struct {
+		name    string
+		cfg     []config.Notification
+		want    int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "none",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "all types",
+			cfg: []config.Notification{
+				{Type: config.NotificationTypeSlack, URLEnv: "RP_TEST_SLACK_URL"},
+				{Type: config.NotificationTypeMatrix, Homeserver: "https://matrix.org", Room: "!abc:matrix.org", TokenEnv: "RP_TEST_MATRIX_TOKEN"},
+				{Type: config.NotificationTypeWebhook, URL: "https://example.com/hook"},
+			},
+			want:    3,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "missing url",
+			cfg:     []config.Notification{{Type: config.NotificationTypeSlack, URLEnv: "RP_TEST_MISSING"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing token",
+			cfg:     []config.Notification{{Type: config.NotificationTypeMatrix, Homeserver: "https://matrix.org", Room: "!abc:matrix.org", TokenEnv: "RP_TEST_MISSING"}},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := notificationsFromConfig(tt.cfg)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Len(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/apricote/releaser-pleaser/internal/config"
+	"github.com/apricote/releaser-pleaser/internal/server"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run releaser-pleaser for every webhook of the forge",
+	Long: `Listen for webhooks of the forge and run releaser-pleaser for the repository whenever the released branch is
+pushed to or a pull request is merged into it.
+
+The repositories are listed in the server config file. Webhooks are verified with the secret of the repository and
+bursts of webhooks are coalesced into a single run.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	flagServeListen        string
+	flagServeConfig        string
+	flagServeCoalesceDelay time.Duration
+	flagServeMetricsListen string
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	addAPIFlags(serveCmd.Flags())
+	addReleaseFlags(serveCmd.Flags())
+	serveCmd.Flags().StringVar(&flagServeListen, "listen", ":8080", "Address to listen on for webhooks")
+	serveCmd.Flags().StringVar(&flagServeConfig, "server-config", server.DefaultConfigPath, "Path of the server config file that lists the repositories")
+	serveCmd.Flags().DurationVar(&flagServeCoalesceDelay, "coalesce-delay", server.DefaultCoalesceDelay, "Time to wait for more webhooks of a repository before running")
+	serveCmd.Flags().StringVar(&flagServeMetricsListen, "metrics-listen", "", "Address to serve Prometheus metrics on at /metrics, e.g. :9090, disabled if empty")
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := server.LoadConfig(flagServeConfig)
+	if err != nil {
+		return err
+	}
+
+	srv, err := server.New(logger, cfg, serveRun, flagServeCoalesceDelay)
+	if err != nil {
+		return err
+	}
+
+	if flagServeMetricsListen != "" {
+		if err = serveMetrics(ctx, flagServeMetricsListen); err != nil {
+			return err
+		}
+	}
+
+	return srv.ListenAndServe(ctx, flagServeListen)
+}
+
+// serveRun runs releaser-pleaser for a repository of the server config.
+func serveRun(ctx context.Context, repo server.Repository) error {
+	configPath := repo.Config
+	if configPath == "" {
+		configPath = config.DefaultPath
+	}
+
+	releaserPleaser, err := newReleaserPleaser(ctx, logger.With("repository", repo.Key()), target{
+		Forge:  repo.Forge,
+		Owner:  repo.Owner,
+		Repo:   repo.Repo,
+		Branch: repo.Branch,
+		Config: configPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	return releaserPleaser.Run(ctx)
+}
+
+// serveMetrics serves the Prometheus metrics on addr until ctx is canceled.
+func serveMetrics(ctx context.Context, addr string) error {
+	handler, err := setupMetrics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set up metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", handler)
+
+	metricsServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.InfoContext(ctx, "serving metrics", "addr", addr)
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.ErrorContext(ctx, "failed to serve metrics", "err", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = metricsServer.Close()
+	}()
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	EnvOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+
+	serviceName = "releaser-pleaser"
+)
+
+// telemetryResource describes the process in the exported spans and metrics. The attributes can be overridden with
+// the standard environment variables OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES.
+func telemetryResource(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion(version())),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+}
+
+// setupTracing exports all spans with OTLP over HTTP, if an endpoint is configured in the environment. Everything else
+// is configured with the standard environment variables of the exporter, e.g. OTEL_EXPORTER_OTLP_HEADERS. The
+// returned function flushes the remaining spans and must be called before the process exits.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv(EnvOTLPEndpoint) == "" && os.Getenv(EnvOTLPTracesEndpoint) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := telemetryResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// setupMetrics records all metrics and returns a handler that serves them in the Prometheus format.
+func setupMetrics(ctx context.Context) (http.Handler, error) {
+	registry := prometheus.NewRegistry()
+
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := telemetryResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(exporter),
+		sdkmetric.WithResource(res),
+	))
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
@@ -0,0 +1,48 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// runCommand runs the command with "sh -c" in the directory. The tag and version of the release are passed in
+// EnvAssetsTag and EnvAssetsVersion.
+func runCommand(ctx context.Context, command, dir string, output io.Writer, tag, version string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), EnvAssetsTag+"="+tag, EnvAssetsVersion+"="+version)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	return cmd.Run()
+}
+
+// runReleaseCommands runs the commands in the worktree of the release branch and stages all changes they made, so
+// they are part of the release commit. The output of the commands is written to output.
+func runReleaseCommands(ctx context.Context, logger *slog.Logger, repo *git.Repository, commands []string, output io.Writer, tag, version string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	dir := repo.Dir()
+	if dir == "" {
+		return errors.New("release commands require a worktree on disk, use clone mode disk")
+	}
+
+	for _, command := range commands {
+		logger.InfoContext(ctx, "running release command", "command", command)
+
+		if err := runCommand(ctx, command, dir, output, tag, version); err != nil {
+			return fmt.Errorf("release command %q failed: %w", command, err)
+		}
+	}
+
+	return repo.AddAll(ctx)
+}
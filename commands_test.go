@@ -0,0 +1,112 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func Test_runReleaseCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		commands   []string
+		cloneMode  git.CloneMode
+		wantFiles  []string
+		wantOutput string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "no commands",
+			cloneMode: git.CloneModeMemory,
+			wantErr:   assert.NoError,
+		},
+		{
+			name: "commands",
+			commands: []string{
+				`echo "$RELEASER_PLEASER_TAG $RELEASER_PLEASER_VERSION" > docs/version.txt`,
+				"rm old.txt",
+				"echo ignored > build.log",
+				"echo generated",
+			},
+			cloneMode:  git.CloneModeDisk,
+			wantFiles:  []string{"docs/version.txt", "old.txt"},
+			wantOutput: "generated\n",
+			wantErr:    assert.NoError,
+		},
+		{
+			name:      "failing command",
+			commands:  []string{"exit 1"},
+			cloneMode: git.CloneModeDisk,
+			wantErr:   assert.Error,
+		},
+		{
+			name:      "memory clone",
+			commands:  []string{"true"},
+			cloneMode: git.CloneModeMemory,
+			wantErr:   assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := gogit.PlainInit(dir, false)
+			require.NoError(t, err)
+			worktree, err := r.Worktree()
+			require.NoError(t, err)
+
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "docs"), 0o755))
+			for path, content := range map[string]string{".gitignore": "*.log\n", "docs/version.txt": "v1.0.0 1.0.0\n", "old.txt": "old\n"} {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+				_, err = worktree.Add(path)
+				require.NoError(t, err)
+			}
+			_, err = worktree.Commit("feat: first", &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+			require.NoError(t, err)
+
+			repo, err := git.CloneRepo(context.Background(), slog.Default(), dir, "master", nil, git.CloneOptions{Mode: tt.cloneMode})
+			require.NoError(t, err)
+
+			var output bytes.Buffer
+			err = runReleaseCommands(context.Background(), slog.Default(), repo, tt.commands, &output, "v1.2.3", "1.2.3")
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.wantOutput, output.String())
+			if len(tt.commands) == 0 {
+				return
+			}
+
+			_, err = repo.Commit(context.Background(), "chore(main): release v1.2.3", git.CommitOptions{})
+			require.NoError(t, err)
+
+			// Files changed by the release commit
+			clone, err := gogit.PlainOpen(repo.Dir())
+			require.NoError(t, err)
+			head, err := clone.Head()
+			require.NoError(t, err)
+			commit, err := clone.CommitObject(head.Hash())
+			require.NoError(t, err)
+			stats, err := commit.Stats()
+			require.NoError(t, err)
+			files := []string{}
+			for _, stat := range stats {
+				files = append(files, stat.Name)
+			}
+			assert.ElementsMatch(t, tt.wantFiles, files)
+
+			content, err := repo.ReadFile(context.Background(), "docs/version.txt")
+			require.NoError(t, err)
+			assert.Equal(t, "v1.2.3 1.2.3\n", string(content))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// attributeAuthors credits every changelog entry to the author of its pull request. Commits without a pull request
+// are credited to the author of the commit, if the forge knows them.
+func attributeAuthors(commits []commitparser.AnalyzedCommit) {
+	for i, commit := range commits {
+		if commit.PullRequest != nil && commit.PullRequest.Author != "" {
+			commits[i].Author = commit.PullRequest.Author
+		} else {
+			commits[i].Author = commit.AuthorLogin
+		}
+	}
+}
+
+// newContributors returns the authors whose first merged pull requests are all part of the release, sorted by their
+// first pull request. Commits without a pull request are not considered, as the forges can only count pull requests.
+func newContributors(ctx context.Context, counter forge.ContributionCounter, commits []git.Commit, pullRequestURL func(id int) string) ([]changelog.Contributor, error) {
+	// The pull requests of every author in the release, a pull request may have multiple commits.
+	prsByAuthor := make(map[string][]int)
+	for _, commit := range commits {
+		if commit.PullRequest == nil || commit.PullRequest.Author == "" {
+			continue
+		}
+
+		author, id := commit.PullRequest.Author, commit.PullRequest.ID
+		if !slices.Contains(prsByAuthor[author], id) {
+			prsByAuthor[author] = append(prsByAuthor[author], id)
+		}
+	}
+
+	var contributors []changelog.Contributor
+	for author, prs := range prsByAuthor {
+		count, err := counter.MergedPullRequestCount(ctx, author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count pull requests of %s: %w", author, err)
+		}
+
+		if count > len(prs) {
+			continue
+		}
+
+		first := slices.Min(prs)
+		contributors = append(contributors, changelog.Contributor{
+			Login:          author,
+			PullRequestID:  first,
+			PullRequestURL: pullRequestURL(first),
+		})
+	}
+
+	slices.SortFunc(contributors, func(a, b changelog.Contributor) int {
+		return a.PullRequestID - b.PullRequestID
+	})
+
+	return contributors, nil
+}
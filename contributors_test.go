@@ -0,0 +1,112 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// fakeContributionCounter returns the number of merged pull requests from the map, and an error for unknown users.
+type fakeContributionCounter map[string]int
+
+func (f fakeContributionCounter) MergedPullRequestCount(_ context.Context, login string) (int, error) {
+	count, ok := f[login]
+	if !ok {
+		return 0, errors.New("unknown user")
+	}
+	return count, nil
+}
+
+func Test_attributeAuthors(t *testing.T) {
+	commits := []commitparser.AnalyzedCommit{
+		{Commit: git.Commit{AuthorLogin: "committer", PullRequest: &git.PullRequest{ID: 1, Author: "jane"}}},
+		{Commit: git.Commit{AuthorLogin: "committer", PullRequest: &git.PullRequest{ID: 2}}},
+		{Commit: git.Commit{AuthorLogin: "bob"}},
+		{Commit: git.Commit{}},
+	}
+
+	attributeAuthors(commits)
+
+	var authors []string
+	for _, commit := range commits {
+		authors = append(authors, commit.Author)
+	}
+	assert.Equal(t, []string{"jane", "committer", "bob", ""}, authors)
+}
+
+func Test_newContributors(t *testing.T) {
+	pullRequestURL := func(id int) string { return fmt.Sprintf("https://example.com/pull/%d", id) }
+
+	tests := []struct {
+		name    string
+		commits []git.Commit
+		counter fakeContributionCounter
+		want    []changelog.Contributor
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "first pull request",
+			commits: []git.Commit{
+				{Hash: "aaa", PullRequest: &git.PullRequest{ID: 3, Author: "jane"}},
+				{Hash: "bbb", PullRequest: &git.PullRequest{ID: 2, Author: "bob"}},
+			},
+			counter: fakeContributionCounter{"jane": 1, "bob": 7},
+			want: []changelog.Contributor{
+				{Login: "jane", PullRequestID: 3, PullRequestURL: "https://example.com/pull/3"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "multiple pull requests in release",
+			commits: []git.Commit{
+				{Hash: "aaa", PullRequest: &git.PullRequest{ID: 5, Author: "jane"}},
+				// Merge commit and commit of the same pull request
+				{Hash: "bbb", PullRequest: &git.PullRequest{ID: 4, Author: "jane"}},
+				{Hash: "ccc", PullRequest: &git.PullRequest{ID: 4, Author: "jane"}},
+				{Hash: "ddd", PullRequest: &git.PullRequest{ID: 2, Author: "bob"}},
+			},
+			counter: fakeContributionCounter{"jane": 2, "bob": 1},
+			want: []changelog.Contributor{
+				{Login: "bob", PullRequestID: 2, PullRequestURL: "https://example.com/pull/2"},
+				{Login: "jane", PullRequestID: 4, PullRequestURL: "https://example.com/pull/4"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "commits without pull request",
+			commits: []git.Commit{
+				{Hash: "aaa", AuthorLogin: "jane"},
+				{Hash: "bbb", PullRequest: &git.PullRequest{ID: 2}},
+			},
+			counter: fakeContributionCounter{},
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "error",
+			commits: []git.Commit{
+				{Hash: "aaa", PullRequest: &git.PullRequest{ID: 1, Author: "jane"}},
+			},
+			counter: fakeContributionCounter{},
+			want:    nil,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newContributors(context.Background(), tt.counter, tt.commits, pullRequestURL)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
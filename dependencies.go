@@ -0,0 +1,164 @@
+package rp
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+var (
+	// DefaultDependencyAuthors are the users of Renovate and Dependabot on GitHub, GitLab and Bitbucket.
+	DefaultDependencyAuthors = []string{"renovate[bot]", "dependabot[bot]", "renovate-bot", "renovate", "dependabot"}
+	// DefaultDependencyBranchPrefixes are the default branch prefixes of Renovate and Dependabot.
+	DefaultDependencyBranchPrefixes = []string{"renovate/", "dependabot/"}
+)
+
+var (
+	// dependabotRegex matches the titles of Dependabot, e.g. "Bump golang.org/x/net from 0.30.0 to 0.31.0 in /tools".
+	dependabotRegex = regexp.MustCompile(`(?i)^bump (\S+) from (\S+) to (\S+)(?: in (\S+))?$`)
+	// renovateRegex matches the titles of Renovate, e.g. "Update module golang.org/x/net to v0.31.0".
+	renovateRegex = regexp.MustCompile(`(?i)^update (?:module |dependency )?(\S+)(?: digest| docker tag| action)? to (\S+)$`)
+)
+
+// DependencyUpdates configures how the updates by dependency bots are listed in the changelog.
+type DependencyUpdates struct {
+	// Group collapses the updates into a single changelog.DependenciesSection with one entry per dependency.
+	Group bool
+	// Authors are the users of the dependency bots, defaults to DefaultDependencyAuthors.
+	Authors []string
+	// BranchPrefixes of the pull requests by the dependency bots, defaults to DefaultDependencyBranchPrefixes.
+	BranchPrefixes []string
+}
+
+// isUpdate returns true if the commit was authored by a dependency bot, based on the author or source branch of its
+// pull request, or the author of the commit if it has no pull request.
+func (d DependencyUpdates) isUpdate(commit git.Commit) bool {
+	pr := commit.PullRequest
+	if pr == nil {
+		return commit.AuthorLogin != "" && slices.Contains(d.Authors, commit.AuthorLogin)
+	}
+
+	if pr.Author != "" && slices.Contains(d.Authors, pr.Author) {
+		return true
+	}
+
+	return pr.Branch != "" && slices.ContainsFunc(d.BranchPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(pr.Branch, prefix)
+	})
+}
+
+// dependencyUpdate is a single update parsed from the title of a pull request or the subject of a commit.
+type dependencyUpdate struct {
+	name, from, to, dir string
+}
+
+func (u dependencyUpdate) String() string {
+	var s string
+	if u.from != "" {
+		s = fmt.Sprintf("bumped %s from %s to %s", u.name, u.from, u.to)
+	} else {
+		s = fmt.Sprintf("bumped %s to %s", u.name, u.to)
+	}
+	if u.dir != "" {
+		s += " in " + u.dir
+	}
+	return s
+}
+
+// parseDependencyUpdate parses the titles of Dependabot and Renovate. It returns false if the title has an unknown
+// format, e.g. for grouped updates.
+func parseDependencyUpdate(title string) (dependencyUpdate, bool) {
+	if match := dependabotRegex.FindStringSubmatch(title); match != nil {
+		return dependencyUpdate{name: match[1], from: match[2], to: match[3], dir: match[4]}, true
+	}
+	if match := renovateRegex.FindStringSubmatch(title); match != nil {
+		return dependencyUpdate{name: match[1], to: match[2]}, true
+	}
+	return dependencyUpdate{}, false
+}
+
+// groupDependencyUpdates replaces the changelog entries and omitted changes of the dependency updates with one entry
+// of the type changelog.SectionTypeDependencies per dependency. Multiple updates of the same dependency are combined
+// into one, e.g. "bumped foo from 1.0.0 to 1.2.0". Updates with breaking changes and skipped commits are kept as is.
+//
+// The commits are expected in the order of git.Repository.CommitsBetween, newest first.
+func groupDependencyUpdates(commits []git.Commit, analyzedCommits []commitparser.AnalyzedCommit, omitted []releasepr.OmittedChange, deps DependencyUpdates) ([]commitparser.AnalyzedCommit, []releasepr.OmittedChange) {
+	breaking := make(map[string]bool)
+	analyzedCommits = slices.DeleteFunc(slices.Clone(analyzedCommits), func(commit commitparser.AnalyzedCommit) bool {
+		if !deps.isUpdate(commit.Commit) {
+			return false
+		}
+		if commit.BreakingChange {
+			breaking[commit.Hash] = true
+		}
+		return !commit.BreakingChange
+	})
+
+	var updates []git.Commit
+	for _, commit := range commits {
+		if deps.isUpdate(commit) && !breaking[commit.Hash] {
+			updates = append(updates, commit)
+		}
+	}
+	if len(updates) == 0 {
+		return analyzedCommits, omitted
+	}
+
+	omitted = slices.DeleteFunc(slices.Clone(omitted), func(change releasepr.OmittedChange) bool {
+		return !change.Skipped && slices.ContainsFunc(updates, func(commit git.Commit) bool {
+			if commit.PullRequest != nil {
+				return change.PullRequestID == commit.PullRequest.ID
+			}
+			return change.PullRequestID == 0 && change.Hash != "" && strings.HasPrefix(commit.Hash, change.Hash)
+		})
+	})
+
+	var entries []commitparser.AnalyzedCommit
+	var parsed []dependencyUpdate
+	seenPRs := make(map[int]bool)
+	for _, commit := range updates {
+		title, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		if commit.PullRequest != nil {
+			// A pull request may have multiple commits, only the first one is listed.
+			if seenPRs[commit.PullRequest.ID] {
+				continue
+			}
+			seenPRs[commit.PullRequest.ID] = true
+			title = commit.PullRequest.Title
+		}
+		title = strings.TrimSpace(title)
+		if match := conventionalTitleRegex.FindStringSubmatch(title); match != nil {
+			title = match[2]
+		}
+
+		update, ok := parseDependencyUpdate(title)
+		if !ok {
+			// Keep the title as is, but do not repeat it.
+			if !slices.ContainsFunc(entries, func(entry commitparser.AnalyzedCommit) bool { return entry.Description == title }) {
+				entries = append(entries, commitparser.AnalyzedCommit{Commit: commit, Type: changelog.SectionTypeDependencies, Description: title})
+				parsed = append(parsed, dependencyUpdate{})
+			}
+			continue
+		}
+
+		// The commits are newest first, so an earlier entry for the same dependency is a newer update.
+		if i := slices.IndexFunc(parsed, func(newer dependencyUpdate) bool {
+			return newer.name != "" && newer.name == update.name && newer.dir == update.dir
+		}); i >= 0 {
+			parsed[i].from = update.from
+			entries[i].Description = parsed[i].String()
+			continue
+		}
+
+		entries = append(entries, commitparser.AnalyzedCommit{Commit: commit, Type: changelog.SectionTypeDependencies, Description: update.String()})
+		parsed = append(parsed, update)
+	}
+
+	return append(analyzedCommits, entries...), omitted
+}
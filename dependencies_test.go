@@ -0,0 +1,109 @@
+package rp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+func Test_parseDependencyUpdate(t *testing.T) {
+	tests := []struct {
+		title  string
+		want   string
+		wantOK bool
+	}{
+		{title: "Bump golang.org/x/net from 0.30.0 to 0.31.0", want: "bumped golang.org/x/net from 0.30.0 to 0.31.0", wantOK: true},
+		{title: "bump actions/setup-go from 4 to 5 in /.github/workflows", want: "bumped actions/setup-go from 4 to 5 in /.github/workflows", wantOK: true},
+		{title: "Update module github.com/spf13/cobra to v1.8.1", want: "bumped github.com/spf13/cobra to v1.8.1", wantOK: true},
+		{title: "update dependency typescript to v5.6.3", want: "bumped typescript to v5.6.3", wantOK: true},
+		{title: "Update golang docker tag to v1.23", want: "bumped golang to v1.23", wantOK: true},
+		{title: "Update actions/checkout action to v4", want: "bumped actions/checkout to v4", wantOK: true},
+		{title: "Update all non-major dependencies", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, ok := parseDependencyUpdate(tt.title)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func Test_groupDependencyUpdates(t *testing.T) {
+	deps := DependencyUpdates{Authors: DefaultDependencyAuthors, BranchPrefixes: DefaultDependencyBranchPrefixes}
+
+	feature := git.Commit{Hash: "aaaaaaaaaa", Message: "feat: foo", PullRequest: &git.PullRequest{ID: 1, Title: "feat: foo", Author: "jane"}}
+	netNew := git.Commit{Hash: "bbbbbbbbbb", Message: "fix(deps): bump golang.org/x/net from 0.30.0 to 0.31.0", PullRequest: &git.PullRequest{ID: 2, Title: "fix(deps): bump golang.org/x/net from 0.30.0 to 0.31.0", Author: "dependabot[bot]"}}
+	netOld := git.Commit{Hash: "cccccccccc", Message: "chore(deps): bump golang.org/x/net from 0.29.0 to 0.30.0", PullRequest: &git.PullRequest{ID: 3, Title: "chore(deps): bump golang.org/x/net from 0.29.0 to 0.30.0", Author: "dependabot[bot]"}}
+	cobra := git.Commit{Hash: "dddddddddd", Message: "chore(deps): update module github.com/spf13/cobra to v1.8.1", PullRequest: &git.PullRequest{ID: 4, Title: "chore(deps): update module github.com/spf13/cobra to v1.8.1", Author: "jane", Branch: "renovate/github.com-spf13-cobra-1.x"}}
+	group := git.Commit{Hash: "eeeeeeeeee", Message: "chore(deps): update all non-major dependencies", AuthorLogin: "renovate[bot]"}
+	breaking := git.Commit{Hash: "ffffffffff", Message: "feat(deps)!: update dependency node to v22", PullRequest: &git.PullRequest{ID: 5, Title: "feat(deps)!: update dependency node to v22", Author: "renovate[bot]"}}
+
+	tests := []struct {
+		name         string
+		commits      []git.Commit
+		analyzed     []commitparser.AnalyzedCommit
+		omitted      []releasepr.OmittedChange
+		wantAnalyzed []commitparser.AnalyzedCommit
+		wantOmitted  []releasepr.OmittedChange
+	}{
+		{
+			name:         "no updates",
+			commits:      []git.Commit{feature},
+			analyzed:     []commitparser.AnalyzedCommit{{Commit: feature, Type: "feat", Description: "foo"}},
+			wantAnalyzed: []commitparser.AnalyzedCommit{{Commit: feature, Type: "feat", Description: "foo"}},
+		},
+		{
+			name:    "grouped",
+			commits: []git.Commit{feature, netNew, cobra, netOld, group},
+			analyzed: []commitparser.AnalyzedCommit{
+				{Commit: feature, Type: "feat", Description: "foo"},
+				{Commit: netNew, Type: "fix", Description: "bump golang.org/x/net from 0.30.0 to 0.31.0"},
+			},
+			omitted: []releasepr.OmittedChange{
+				{Title: "chore(deps): update module github.com/spf13/cobra to v1.8.1", PullRequestID: 4},
+				{Title: "chore(deps): bump golang.org/x/net from 0.29.0 to 0.30.0", PullRequestID: 3},
+				{Title: "chore(deps): update all non-major dependencies", Hash: "eeeeeee"},
+				{Title: "chore: skipped", PullRequestID: 6, Skipped: true},
+			},
+			wantAnalyzed: []commitparser.AnalyzedCommit{
+				{Commit: feature, Type: "feat", Description: "foo"},
+				{Commit: netNew, Type: changelog.SectionTypeDependencies, Description: "bumped golang.org/x/net from 0.29.0 to 0.31.0"},
+				{Commit: cobra, Type: changelog.SectionTypeDependencies, Description: "bumped github.com/spf13/cobra to v1.8.1"},
+				{Commit: group, Type: changelog.SectionTypeDependencies, Description: "update all non-major dependencies"},
+			},
+			wantOmitted: []releasepr.OmittedChange{
+				{Title: "chore: skipped", PullRequestID: 6, Skipped: true},
+			},
+		},
+		{
+			name:    "breaking changes are kept",
+			commits: []git.Commit{breaking, cobra},
+			analyzed: []commitparser.AnalyzedCommit{
+				{Commit: breaking, Type: "feat", Description: "update dependency node to v22", BreakingChange: true},
+			},
+			omitted: []releasepr.OmittedChange{
+				{Title: "chore(deps): update module github.com/spf13/cobra to v1.8.1", PullRequestID: 4},
+			},
+			wantAnalyzed: []commitparser.AnalyzedCommit{
+				{Commit: breaking, Type: "feat", Description: "update dependency node to v22", BreakingChange: true},
+				{Commit: cobra, Type: changelog.SectionTypeDependencies, Description: "bumped github.com/spf13/cobra to v1.8.1"},
+			},
+			wantOmitted: []releasepr.OmittedChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, omitted := groupDependencyUpdates(tt.commits, tt.analyzed, tt.omitted, deps)
+			assert.Equal(t, tt.wantAnalyzed, analyzed)
+			assert.Equal(t, tt.wantOmitted, omitted)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// ErrMissingPermissions is returned by Run if the token is missing permissions that are required for the run.
+var ErrMissingPermissions = errors.New("token is missing permissions")
+
+// Doctor checks that the token has all permissions that are required to run releaser-pleaser. Nothing is changed on
+// the forge. The repository is always checked for read access with git, the permissions for the API are only checked
+// if the forge implements forge.PermissionChecker.
+func (rp *ReleaserPleaser) Doctor(ctx context.Context) ([]PermissionCheck, error) {
+	clone := PermissionCheck{Action: "clone repository", Permission: "git read access"}
+	_, err := git.RemoteBranches(ctx, rp.forge.CloneURL(), rp.forge.GitAuth(), "")
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrRepositoryNotFound):
+		clone.Err = err
+	case err != nil:
+		return nil, err
+	}
+	checks := []PermissionCheck{clone}
+
+	checker, ok := rp.forge.(forge.PermissionChecker)
+	if !ok {
+		rp.logger.DebugContext(ctx, "forge does not support checking the permissions of the token")
+		return checks, nil
+	}
+
+	forgeChecks, err := checker.CheckPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(checks, forgeChecks...), nil
+}
+
+// runPreflight fails the run before anything is changed if the token is missing a permission. Otherwise the run would
+// fail midway with a generic error, e.g. after the release branch was already pushed.
+func (rp *ReleaserPleaser) runPreflight(ctx context.Context) error {
+	logger := rp.logger.With("method", "runPreflight")
+
+	checks, err := rp.Doctor(ctx)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, check := range checks {
+		if check.Err != nil {
+			logger.ErrorContext(ctx, "token is missing a permission", "action", check.Action, "permission", check.Permission, "err", check.Err)
+			missing = append(missing, fmt.Sprintf("%s (%s)", check.Action, check.Permission))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w, run rp doctor for details: %s", ErrMissingPermissions, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
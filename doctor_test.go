@@ -0,0 +1,115 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+// fakeCloneForge serves a repository on the local filesystem.
+type fakeCloneForge struct {
+	forge.Forge
+
+	dir string
+}
+
+func (f *fakeCloneForge) CloneURL() string              { return f.dir }
+func (f *fakeCloneForge) GitAuth() transport.AuthMethod { return nil }
+
+// fakePermissionChecker returns the same checks on every call.
+type fakePermissionChecker struct {
+	fakeCloneForge
+
+	checks []forge.PermissionCheck
+}
+
+func (f *fakePermissionChecker) CheckPermissions(context.Context) ([]forge.PermissionCheck, error) {
+	return f.checks, nil
+}
+
+func TestReleaserPleaser_runPreflight(t *testing.T) {
+	dir := t.TempDir()
+	_, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	denied := errors.New("resource not accessible by integration")
+
+	tests := []struct {
+		name       string
+		forge      forge.Forge
+		wantChecks []PermissionCheck
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "forge without permission checks",
+			forge:      &fakeCloneForge{dir: dir},
+			wantChecks: []PermissionCheck{{Action: "clone repository", Permission: "git read access"}},
+			wantErr:    assert.NoError,
+		},
+		{
+			name: "all permissions",
+			forge: &fakePermissionChecker{
+				fakeCloneForge: fakeCloneForge{dir: dir},
+				checks:         []forge.PermissionCheck{{Action: "create pull requests", Permission: "pull-requests: write"}},
+			},
+			wantChecks: []PermissionCheck{
+				{Action: "clone repository", Permission: "git read access"},
+				{Action: "create pull requests", Permission: "pull-requests: write"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "missing permission",
+			forge: &fakePermissionChecker{
+				fakeCloneForge: fakeCloneForge{dir: dir},
+				checks: []forge.PermissionCheck{
+					{Action: "read pull requests", Permission: "pull-requests: read"},
+					{Action: "create pull requests", Permission: "pull-requests: write", Err: denied},
+				},
+			},
+			wantChecks: []PermissionCheck{
+				{Action: "clone repository", Permission: "git read access"},
+				{Action: "read pull requests", Permission: "pull-requests: read"},
+				{Action: "create pull requests", Permission: "pull-requests: write", Err: denied},
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrMissingPermissions) && assert.ErrorContains(t, err, "create pull requests (pull-requests: write)")
+			},
+		},
+		{
+			name:  "repository not found",
+			forge: &fakeCloneForge{dir: filepath.Join(t.TempDir(), "missing")},
+			wantChecks: []PermissionCheck{
+				{Action: "clone repository", Permission: "git read access", Err: transport.ErrRepositoryNotFound},
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrMissingPermissions)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := New(tt.forge, Options{Logger: slog.Default()})
+
+			checks, err := rp.Doctor(context.Background())
+			require.NoError(t, err)
+			require.Len(t, checks, len(tt.wantChecks))
+			for i, want := range tt.wantChecks {
+				assert.Equal(t, want.Action, checks[i].Action)
+				assert.Equal(t, want.Permission, checks[i].Permission)
+				assert.ErrorIs(t, checks[i].Err, want.Err)
+			}
+
+			tt.wantErr(t, rp.runPreflight(context.Background()))
+		})
+	}
+}
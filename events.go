@@ -0,0 +1,109 @@
+package rp
+
+import (
+	"context"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// Event is emitted to the EventSink while a Run progresses. It is one of TagFound, CommitsDiscovered,
+// VersionComputed, BranchPushed, PullRequestUpdated or ReleaseCreated.
+type Event interface {
+	// EventName returns the name of the event, e.g. "tag_found".
+	EventName() string
+}
+
+// EventSink receives the events of a Run, e.g. to show the progress in a UI or to send notifications. Events are
+// emitted synchronously, so the sink should not block for long.
+type EventSink interface {
+	Event(ctx context.Context, event Event)
+}
+
+// EventSinkFunc is an EventSink that calls the function for every event.
+type EventSinkFunc func(ctx context.Context, event Event)
+
+func (f EventSinkFunc) Event(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// TagFound is emitted when the latest release of a package was found. Tag is nil if the package was never released.
+type TagFound struct {
+	// Package is the name of the package, empty for single package repositories.
+	Package string
+	Tag     *git.Tag
+}
+
+func (TagFound) EventName() string { return "tag_found" }
+
+// CommitsDiscovered is emitted with the commits since the latest release of a package.
+type CommitsDiscovered struct {
+	Package string
+	Commits []git.Commit
+	// Analyzed is the number of commits that are listed in the changelog.
+	Analyzed int
+}
+
+func (CommitsDiscovered) EventName() string { return "commits_discovered" }
+
+// VersionComputed is emitted when the next version of a package is known. Version is empty if there are no releasable
+// changes.
+type VersionComputed struct {
+	Package string
+	// PreviousVersion is the tag of the release the changes are compared to, empty for the first release.
+	PreviousVersion string
+	Version         string
+}
+
+func (VersionComputed) EventName() string { return "version_computed" }
+
+// BranchPushed is emitted when the release commit was pushed to the branch of the release pull request.
+type BranchPushed struct {
+	Package string
+	Branch  string
+	Commit  git.Commit
+}
+
+func (BranchPushed) EventName() string { return "branch_pushed" }
+
+// PullRequestAction describes what happened to the release pull request.
+type PullRequestAction string
+
+const (
+	PullRequestActionOpened  PullRequestAction = "opened"
+	PullRequestActionUpdated PullRequestAction = "updated"
+	PullRequestActionClosed  PullRequestAction = "closed"
+)
+
+// PullRequestUpdated is emitted when the release pull request was opened, updated or closed. It is not emitted if the
+// pull request is already up-to-date.
+type PullRequestUpdated struct {
+	Package string
+	Action  PullRequestAction
+	ID      int
+	URL     string
+	Title   string
+}
+
+func (PullRequestUpdated) EventName() string { return "pull_request_updated" }
+
+// ReleaseCreated is emitted when the release of a merged release pull request exists on the forge, before it is
+// announced.
+type ReleaseCreated struct {
+	Package string
+	Release forge.Release
+}
+
+func (ReleaseCreated) EventName() string { return "release_created" }
+
+func (rp *ReleaserPleaser) pullRequestUpdated(pkg Package, pr *releasepr.ReleasePullRequest, action PullRequestAction) PullRequestUpdated {
+	return PullRequestUpdated{Package: pkg.Name, Action: action, ID: pr.ID, URL: rp.forge.PullRequestURL(pr.ID), Title: pr.Title}
+}
+
+// emit sends the event to the EventSink, if one is configured.
+func (rp *ReleaserPleaser) emit(ctx context.Context, event Event) {
+	if rp.events != nil {
+		rp.events.Event(ctx, event)
+	}
+}
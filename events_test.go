@@ -0,0 +1,35 @@
+package rp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+func TestReleaserPleaser_emit(t *testing.T) {
+	t.Run("without sink", func(t *testing.T) {
+		rp := New(nil, Options{})
+		assert.NotPanics(t, func() { rp.emit(context.Background(), TagFound{}) })
+	})
+
+	t.Run("release created", func(t *testing.T) {
+		var events []Event
+		finder := &fakeReleaseFinder{}
+		rp := New(finder, Options{
+			Events: EventSinkFunc(func(_ context.Context, event Event) { events = append(events, event) }),
+		})
+
+		err := rp.createPendingRelease(context.Background(), mergedReleasePR(t, 1, "v1.1.0", releasepr.LabelReleasePending))
+		require.NoError(t, err)
+
+		assert.Equal(t, []Event{
+			ReleaseCreated{Release: forge.Release{TagName: "v1.1.0", Changelog: "### Features\n\n- foo\n"}},
+		}, events)
+		assert.Equal(t, "release_created", events[0].EventName())
+	})
+}
@@ -0,0 +1,107 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+// The aliases expose the types that are required to implement a Forge outside of this module.
+type (
+	Forge                = forge.Forge
+	Release              = forge.Release
+	ReleaseAnnouncer     = forge.ReleaseAnnouncer
+	DescriptionLimiter   = forge.DescriptionLimiter
+	ContributionCounter  = forge.ContributionCounter
+	CommitCreator        = forge.CommitCreator
+	ReleaseBranchCleaner = forge.ReleaseBranchCleaner
+	PermissionChecker    = forge.PermissionChecker
+	PermissionCheck      = forge.PermissionCheck
+	ReleasePullRequest   = releasepr.ReleasePullRequest
+	Label                = releasepr.Label
+	Commit               = git.Commit
+	PullRequest          = git.PullRequest
+	FileChange           = git.FileChange
+	Tag                  = git.Tag
+	Releases             = git.Releases
+	VersioningStrategy   = versioning.Strategy
+)
+
+// ForgeOptions are passed to the ForgeFactory. They are set from the command line flags.
+type ForgeOptions struct {
+	// Owner of the repository, e.g. the user, organization, group or workspace.
+	Owner string
+	// Repo is the name of the repository.
+	Repo string
+	// BaseBranch is the branch that is released.
+	BaseBranch string
+	// Concurrency is the number of requests that should be made at the same time, e.g. to look up the pull requests
+	// of commits.
+	Concurrency int
+}
+
+// ForgeFactory creates the Forge for the repository. It is called once per run.
+type ForgeFactory func(ctx context.Context, logger *slog.Logger, options ForgeOptions) (Forge, error)
+
+var (
+	forgesMu sync.RWMutex
+	forges   = make(map[string]ForgeFactory)
+)
+
+// RegisterForge makes the Forge available under the name, which is selected with the --forge flag of the rp command.
+// It is meant to be called from an init function, before the command is executed. It panics if the name is already
+// registered or the factory is nil.
+func RegisterForge(name string, factory ForgeFactory) {
+	forgesMu.Lock()
+	defer forgesMu.Unlock()
+
+	if factory == nil {
+		panic("rp: RegisterForge factory is nil")
+	}
+	if _, exists := forges[name]; exists {
+		panic("rp: RegisterForge called twice for forge " + name)
+	}
+
+	forges[name] = factory
+}
+
+// NewForge creates the Forge that was registered under the name.
+func NewForge(ctx context.Context, logger *slog.Logger, name string, options ForgeOptions) (Forge, error) {
+	forgesMu.RLock()
+	factory, ok := forges[name]
+	forgesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown forge %q, available forges: %v", name, ForgeNames())
+	}
+
+	return factory(ctx, logger, options)
+}
+
+// ForgeNames returns the names of all registered forges in alphabetical order.
+func ForgeNames() []string {
+	forgesMu.RLock()
+	defer forgesMu.RUnlock()
+
+	names := make([]string, 0, len(forges))
+	for name := range forges {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// LatestReleases implements Forge.LatestTags for forges that can list all tags of the repository, in any order, and
+// check if a tag is reachable from the base branch. It returns the highest versions that match the prefix and the
+// versioning strategy.
+func LatestReleases(ctx context.Context, logger *slog.Logger, tags []Tag, tagPrefix string, strategy VersioningStrategy, reachable func(context.Context, *Tag) (bool, error)) (Releases, error) {
+	return forge.LatestReleases(ctx, logger, tags, tagPrefix, strategy, reachable)
+}
@@ -0,0 +1,39 @@
+package rp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterForge(t *testing.T) {
+	var gotOptions ForgeOptions
+	RegisterForge("test-register", func(_ context.Context, _ *slog.Logger, options ForgeOptions) (Forge, error) {
+		gotOptions = options
+		return nil, nil
+	})
+
+	assert.Contains(t, ForgeNames(), "test-register")
+
+	options := ForgeOptions{Owner: "apricote", Repo: "releaser-pleaser", BaseBranch: "main"}
+	_, err := NewForge(context.Background(), slog.Default(), "test-register", options)
+	require.NoError(t, err)
+	assert.Equal(t, options, gotOptions)
+
+	assert.Panics(t, func() {
+		RegisterForge("test-register", func(_ context.Context, _ *slog.Logger, _ ForgeOptions) (Forge, error) {
+			return nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		RegisterForge("test-nil", nil)
+	})
+}
+
+func TestNewForge_Unknown(t *testing.T) {
+	_, err := NewForge(context.Background(), slog.Default(), "test-unknown", ForgeOptions{})
+	assert.ErrorContains(t, err, `unknown forge "test-unknown"`)
+}
@@ -3,16 +3,29 @@ package changelog
 import (
 	"bytes"
 	_ "embed"
-	"html/template"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/apricote/releaser-pleaser/internal/commitparser"
 	"github.com/apricote/releaser-pleaser/internal/markdown"
 )
 
+// TemplatePath is the path of the changelog entry template in the repository. If the file exists, it is used instead
+// of the DefaultTemplate.
+const TemplatePath = ".releaser-pleaser/changelog.md.tpl"
+
 var (
 	changelogTemplate *template.Template
+
+	templateCache   = map[string]*template.Template{}
+	templateCacheMu sync.Mutex
 )
 
 //go:embed changelog.md.tpl
@@ -20,7 +33,7 @@ var rawChangelogTemplate string
 
 func init() {
 	var err error
-	changelogTemplate, err = template.New("changelog").Parse(rawChangelogTemplate)
+	changelogTemplate, err = parseTemplate(rawChangelogTemplate)
 	if err != nil {
 		log.Fatalf("failed to parse changelog template: %v", err)
 	}
@@ -30,17 +43,202 @@ func DefaultTemplate() *template.Template {
 	return changelogTemplate
 }
 
+func parseTemplate(raw string) (*template.Template, error) {
+	return template.New("changelog").Funcs(templateFuncs).Parse(raw)
+}
+
+// Template parses a custom changelog entry template. Parsed templates are cached by their content, so loading the
+// same template for multiple packages is cheap.
+func Template(raw string) (*template.Template, error) {
+	templateCacheMu.Lock()
+	defer templateCacheMu.Unlock()
+
+	if tpl, ok := templateCache[raw]; ok {
+		return tpl, nil
+	}
+
+	tpl, err := parseTemplate(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse changelog template: %w", err)
+	}
+	templateCache[raw] = tpl
+
+	return tpl, nil
+}
+
+// LoadTemplate reads the template at TemplatePath with readFile. If the file does not exist, the DefaultTemplate is
+// returned.
+func LoadTemplate(readFile func(path string) ([]byte, error)) (*template.Template, error) {
+	return loadTemplate(readFile, DefaultTemplate())
+}
+
+// LoadTemplateFile reads the template at the path with readFile. Unlike LoadTemplate, the file must exist.
+func LoadTemplateFile(readFile func(path string) ([]byte, error), path string) (*template.Template, error) {
+	raw, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changelog template %s: %w", path, err)
+	}
+
+	return Template(string(raw))
+}
+
+func loadTemplate(readFile func(path string) ([]byte, error), fallback *template.Template) (*template.Template, error) {
+	raw, err := readFile(TemplatePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fallback, nil
+		}
+		return nil, fmt.Errorf("failed to read changelog template %s: %w", TemplatePath, err)
+	}
+
+	return Template(string(raw))
+}
+
+// Section configures a heading in the changelog that lists all commits of the type.
+type Section struct {
+	Type  string
+	Title string
+}
+
+const (
+	// SectionTypeBreaking is a special Section type that matches all commits with breaking changes, regardless of
+	// their actual type. Commits listed in this section are not repeated in the section of their type.
+	SectionTypeBreaking = "breaking"
+	// SectionTypeIncluded is the type of the pull requests that are listed because of the "rp-changelog::include"
+	// label. If it is not configured, the IncludedSection is added after all other sections.
+	SectionTypeIncluded = "included"
+	// SectionTypeDependencies is the type of the grouped updates by dependency bots like Renovate and Dependabot. If
+	// it is not configured, the DependenciesSection is added after all other sections.
+	SectionTypeDependencies = "dependencies"
+)
+
+// IncludedSection lists the pull requests with the "rp-changelog::include" label.
+var IncludedSection = Section{Type: SectionTypeIncluded, Title: "Other Changes"}
+
+// DependenciesSection lists the grouped updates by dependency bots.
+var DependenciesSection = Section{Type: SectionTypeDependencies, Title: "Dependencies"}
+
+// DefaultSections are used if the repository does not configure its own sections.
+var DefaultSections = []Section{
+	{Type: "feat", Title: "Features"},
+	{Type: "fix", Title: "Bug Fixes"},
+}
+
 type Data struct {
-	Commits     map[string][]commitparser.AnalyzedCommit
+	Sections    []SectionData
 	Version     string
 	VersionLink string
+	// PreviousVersion is the tag of the last release, empty for the first release.
+	PreviousVersion string
+	// CompareLink links to the changes between PreviousVersion and Version, empty for the first release.
+	CompareLink string
 	Prefix      string
 	Suffix      string
+	// Date of the release in the format YYYY-MM-DD, the day the release pull request was last updated.
+	Date string
+	// NewContributors made their first contribution to the repository in this release.
+	NewContributors []Contributor
+}
+
+// Contributor is a user that made their first contribution to the repository.
+type Contributor struct {
+	// Login is the username on the forge.
+	Login string
+	// PullRequestID and PullRequestURL identify the first pull request of the user in the release.
+	PullRequestID  int
+	PullRequestURL string
+}
+
+type SectionData struct {
+	Title   string
+	Commits []commitparser.AnalyzedCommit
+	// Scopes are the Commits grouped by their scope. Only set if Scopes.Group is enabled.
+	Scopes []ScopeData
+}
+
+// ScopeData lists the commits of a section with the same scope. The Scope of the commits is cleared, as it is already
+// shown in the heading.
+type ScopeData struct {
+	// Scope is empty for commits without a scope.
+	Scope   string
+	Commits []commitparser.AnalyzedCommit
+}
+
+// Scopes configures how the scopes of commits are handled in the changelog.
+type Scopes struct {
+	// Group lists the commits of every section grouped by their scope. Commits without a scope are listed first.
+	Group bool
+	// Exclude removes commits with one of these scopes from the changelog. They are still considered for the next
+	// version.
+	Exclude []string
 }
 
-func New(commits map[string][]commitparser.AnalyzedCommit, version, versionLink, prefix, suffix string) Data {
+// New groups the commits into the sections. Commits with a type that has no section are dropped, empty sections are
+// omitted. Commits of the types SectionTypeDependencies and SectionTypeIncluded are always listed, in the
+// DependenciesSection and IncludedSection if they are not configured.
+// Sections with the same title are merged, to list multiple types under one heading.
+func New(commits []commitparser.AnalyzedCommit, sections []Section, scopes Scopes, version, versionLink, prefix, suffix string) Data {
+	if len(scopes.Exclude) > 0 {
+		commits = slices.DeleteFunc(slices.Clone(commits), func(commit commitparser.AnalyzedCommit) bool {
+			return commit.Scope != nil && slices.Contains(scopes.Exclude, *commit.Scope)
+		})
+	}
+
+	breakingSection := slices.ContainsFunc(sections, func(section Section) bool {
+		return section.Type == SectionTypeBreaking
+	})
+
+	var breaking []commitparser.AnalyzedCommit
+	if breakingSection {
+		breaking = make([]commitparser.AnalyzedCommit, 0)
+		commits = slices.DeleteFunc(slices.Clone(commits), func(commit commitparser.AnalyzedCommit) bool {
+			if commit.BreakingChange {
+				breaking = append(breaking, commit)
+			}
+			return commit.BreakingChange
+		})
+	}
+
+	byType := commitparser.ByType(commits)
+
+	for _, implicit := range []Section{DependenciesSection, IncludedSection} {
+		if len(byType[implicit.Type]) > 0 && !slices.ContainsFunc(sections, func(section Section) bool {
+			return section.Type == implicit.Type
+		}) {
+			sections = append(slices.Clone(sections), implicit)
+		}
+	}
+
+	sectionData := make([]SectionData, 0, len(sections))
+	for _, section := range sections {
+		sectionCommits := byType[section.Type]
+		if section.Type == SectionTypeBreaking {
+			sectionCommits = breaking
+		}
+
+		if len(sectionCommits) == 0 {
+			continue
+		}
+
+		if i := slices.IndexFunc(sectionData, func(data SectionData) bool { return data.Title == section.Title }); i >= 0 {
+			sectionData[i].Commits = slices.Concat(sectionData[i].Commits, sectionCommits)
+			continue
+		}
+
+		sectionData = append(sectionData, SectionData{
+			Title:   section.Title,
+			Commits: sectionCommits,
+		})
+	}
+
+	if scopes.Group {
+		for i := range sectionData {
+			sectionData[i].Scopes = groupByScope(sectionData[i].Commits)
+		}
+	}
+
 	return Data{
-		Commits:     commits,
+		Sections:    sectionData,
 		Version:     version,
 		VersionLink: versionLink,
 		Prefix:      prefix,
@@ -48,6 +246,46 @@ func New(commits map[string][]commitparser.AnalyzedCommit, version, versionLink,
 	}
 }
 
+// groupByScope keeps the order of the commits inside each scope. Commits without a scope come first, the other scopes
+// are sorted alphabetically.
+func groupByScope(commits []commitparser.AnalyzedCommit) []ScopeData {
+	byScope := map[string][]commitparser.AnalyzedCommit{}
+	for _, commit := range commits {
+		scope := ""
+		if commit.Scope != nil {
+			scope = *commit.Scope
+		}
+
+		commit.Scope = nil
+		byScope[scope] = append(byScope[scope], commit)
+	}
+
+	names := make([]string, 0, len(byScope))
+	for scope := range byScope {
+		names = append(names, scope)
+	}
+	slices.Sort(names)
+
+	scopes := make([]ScopeData, 0, len(names))
+	for _, scope := range names {
+		scopes = append(scopes, ScopeData{Scope: scope, Commits: byScope[scope]})
+	}
+
+	return scopes
+}
+
+// Types returns the commit types that have a section.
+func Types(sections []Section) []string {
+	types := make([]string, 0, len(sections))
+	for _, section := range sections {
+		if section.Type != SectionTypeBreaking {
+			types = append(types, section.Type)
+		}
+	}
+
+	return types
+}
+
 type Formatting struct {
 	HideVersionTitle bool
 }
@@ -62,11 +300,38 @@ func Entry(logger *slog.Logger, tpl *template.Template, data Data, formatting Fo
 		return "", err
 	}
 
-	formatted, err := markdown.Format(changelog.String())
+	// Front matter, e.g. for static site generators, is not markdown and is kept as is.
+	frontMatter, body := splitFrontMatter(changelog.String())
+
+	formatted, err := markdown.Format(body)
 	if err != nil {
 		logger.Warn("failed to format changelog entry, using unformatted", "error", err)
 		return changelog.String(), nil
 	}
 
-	return formatted, nil
+	if frontMatter != "" && formatted != "" {
+		frontMatter += "\n"
+	}
+
+	return frontMatter + formatted, nil
+}
+
+// splitFrontMatter returns the YAML ("---") or TOML ("+++") front matter at the start of the entry and the rest of the
+// entry.
+func splitFrontMatter(entry string) (string, string) {
+	for _, delimiter := range []string{"---\n", "+++\n"} {
+		if !strings.HasPrefix(entry, delimiter) {
+			continue
+		}
+
+		end := strings.Index(entry[len(delimiter):], "\n"+delimiter)
+		if end < 0 {
+			return "", entry
+		}
+		end += len(delimiter) + len("\n"+delimiter)
+
+		return entry[:end], entry[end:]
+	}
+
+	return "", entry
 }
@@ -1,22 +1,43 @@
 {{define "entry" -}}
-- {{ if .Scope }}**{{.Scope}}**: {{end}}{{.Description}}
+- {{ if .Scope }}**{{ escapeMarkdown .Scope }}**: {{end}}{{ escapeMarkdown .Description }}{{ with .Author }} by @{{ . }}{{ end }}
+{{- with .PullRequest }}{{ if .LinkedIssues }} (closes {{ range $i, $issue := .LinkedIssues }}{{ if $i }}, {{ end }}{{ $issue }}{{ end }}){{ end }}{{ end }}
+{{- if .CoAuthors }} (co-authored by {{ range $i, $author := .CoAuthors }}{{ if $i }}, {{ end }}{{ escapeMarkdown $author }}{{ end }}){{ end }}
+{{- with .CommitURL }} ([{{ shortHash $.Hash }}]({{ . }})){{ end }}
+{{ with .Details }}
+{{ indent 2 . }}
+
 {{ end }}
+{{- end }}
 
 {{- if not .Formatting.HideVersionTitle }}
 ## [{{.Data.Version}}]({{.Data.VersionLink}})
 {{ end -}}
+{{- with .Data.CompareLink }}
+[{{ $.Data.PreviousVersion }}...{{ $.Data.Version }}]({{ . }})
+{{ end -}}
 {{- if .Data.Prefix }}
 {{ .Data.Prefix }}
 {{ end -}}
-{{- with .Data.Commits.feat }}
-### Features
+{{- range .Data.Sections }}
+### {{ .Title }}
+{{ if .Scopes }}
+{{- range .Scopes }}
+{{ if .Scope }}#### {{ escapeMarkdown .Scope }}
 
-{{ range . -}}{{template "entry" .}}{{end}}
+{{ end -}}
+{{ range .Commits -}}{{template "entry" .}}{{end}}
+{{- end }}
+{{- else }}
+{{ range .Commits -}}{{template "entry" .}}{{end}}
+{{- end }}
 {{- end -}}
-{{- with .Data.Commits.fix }}
-### Bug Fixes
 
-{{ range . -}}{{template "entry" .}}{{end}}
+{{- if .Data.NewContributors }}
+### New Contributors
+
+{{ range .Data.NewContributors -}}
+- @{{ .Login }} made their first contribution in [#{{ .PullRequestID }}]({{ .PullRequestURL }})
+{{ end -}}
 {{- end -}}
 
 {{- if .Data.Suffix }}
@@ -1,10 +1,13 @@
 package changelog
 
 import (
+	"errors"
+	"io/fs"
 	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/apricote/releaser-pleaser/internal/commitparser"
 	"github.com/apricote/releaser-pleaser/internal/git"
@@ -21,6 +24,11 @@ func Test_NewChangelogEntry(t *testing.T) {
 		link            string
 		prefix          string
 		suffix          string
+		sections        []Section
+		scopes          Scopes
+		newContributors []Contributor
+		previousVersion string
+		compareLink     string
 	}
 	tests := []struct {
 		name    string
@@ -70,6 +78,71 @@ func Test_NewChangelogEntry(t *testing.T) {
 			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Bug Fixes\n\n- Foobar!\n",
 			wantErr: assert.NoError,
 		},
+		{
+			name: "html in description",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "support Option<T> & <details> blocks",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- support Option\\<T\\> & \\<details\\> blocks\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "code span in description",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "fix",
+						Scope:       ptr("<api>"),
+						Description: "handle `a < b && c > d` in `Map<K, V>`",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Bug Fixes\n\n- **\\<api\\>**: handle `a < b && c > d` in `Map<K, V>`\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "group by scope",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{Type: "feat", Scope: ptr("cli"), Description: "Add command"},
+					{Type: "feat", Description: "Unscoped"},
+					{Type: "feat", Scope: ptr("api"), Description: "Add endpoint"},
+					{Type: "feat", Scope: ptr("cli"), Description: "Add flag"},
+					{Type: "fix", Scope: ptr("api"), Description: "Fix endpoint"},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+				scopes:  Scopes{Group: true},
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Unscoped\n\n#### api\n\n- Add endpoint\n\n#### cli\n\n- Add command\n- Add flag\n\n### Bug Fixes\n\n#### api\n\n- Fix endpoint\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "exclude scopes",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{Type: "feat", Scope: ptr("api"), Description: "Add endpoint"},
+					{Type: "fix", Scope: ptr("deps"), Description: "Update dependency"},
+					{Type: "fix", Scope: ptr("internal"), Description: "Refactor"},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+				scopes:  Scopes{Exclude: []string{"deps", "internal"}},
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- **api**: Add endpoint\n",
+			wantErr: assert.NoError,
+		},
 		{
 			name: "multiple commits with scopes",
 			args: args{
@@ -161,6 +234,286 @@ func Test_NewChangelogEntry(t *testing.T) {
 ### Compatibility
 
 This version is compatible with flux-compensator v2.2 - v2.9.
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "linked issues",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit: git.Commit{
+							PullRequest: &git.PullRequest{ID: 45, LinkedIssues: []string{"#12", "apricote/hcloud-upload-image#3"}},
+						},
+						Type:        "feat",
+						Description: "new thing (#45)",
+					},
+					{
+						Commit: git.Commit{
+							PullRequest: &git.PullRequest{ID: 46},
+						},
+						Type:        "fix",
+						Description: "Foobar!",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want: `## [1.0.0](https://example.com/1.0.0)
+
+### Features
+
+- new thing (#45) (closes #12, apricote/hcloud-upload-image#3)
+
+### Bug Fixes
+
+- Foobar!
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "co-authors",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "fix",
+						Description: "Foobar!",
+						CoAuthors:   []string{"Jane Doe", "Bob"},
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Bug Fixes\n\n- Foobar! (co-authored by Jane Doe, Bob)\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "authors",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobar!",
+						Author:      "apricote",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "fix",
+						Description: "Bump dependency",
+						Author:      "renovate[bot]",
+						CoAuthors:   []string{"Jane Doe"},
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Foobar! by @apricote\n\n### Bug Fixes\n\n- Bump dependency by @renovate[bot] (co-authored by Jane Doe)\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "new contributors",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobar!",
+						Author:      "jane",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+				newContributors: []Contributor{
+					{Login: "jane", PullRequestID: 12, PullRequestURL: "https://example.com/pull/12"},
+				},
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Foobar! by @jane\n\n### New Contributors\n\n- @jane made their first contribution in [#12](https://example.com/pull/12)\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "compare and commit links",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{Hash: "1234567890abcdef"},
+						Type:        "feat",
+						Description: "Foobar!",
+						CommitURL:   "https://example.com/commit/1234567890abcdef",
+					},
+					{
+						Commit:      git.Commit{Hash: "abcdef1234567890", PullRequest: &git.PullRequest{ID: 12}},
+						Type:        "feat",
+						Description: "Foobaz!",
+					},
+				},
+				version:         "v1.1.0",
+				link:            "https://example.com/v1.1.0",
+				previousVersion: "v1.0.0",
+				compareLink:     "https://example.com/compare/v1.0.0...v1.1.0",
+			},
+			want:    "## [v1.1.0](https://example.com/v1.1.0)\n\n[v1.0.0...v1.1.0](https://example.com/compare/v1.0.0...v1.1.0)\n\n### Features\n\n- Foobar! ([1234567](https://example.com/commit/1234567890abcdef))\n- Foobaz!\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "details",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobar!",
+						Details:     "The foo can now bar.\n\nUse `--baz` to enable it:\n\n```shell\nfoo --baz\n```",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobaz!",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Foobar!\n\n  The foo can now bar.\n\n  Use `--baz` to enable it:\n\n  ```shell\n  foo --baz\n  ```\n\n- Foobaz!\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "included pull requests",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        SectionTypeIncluded,
+						Description: "Document the new feature",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobar!",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Foobar!\n\n### Other Changes\n\n- Document the new feature\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "dependency updates",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        SectionTypeIncluded,
+						Description: "Document the new feature",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        SectionTypeDependencies,
+						Description: "bumped golang.org/x/net from 0.30.0 to 0.31.0",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "feat",
+						Description: "Foobar!",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+			},
+			want:    "## [1.0.0](https://example.com/1.0.0)\n\n### Features\n\n- Foobar!\n\n### Dependencies\n\n- bumped golang.org/x/net from 0.30.0 to 0.31.0\n\n### Other Changes\n\n- Document the new feature\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "custom sections",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "fix",
+						Description: "Foobar!",
+					},
+					{
+						Commit:         git.Commit{},
+						Type:           "feat",
+						Description:    "Everything is different!",
+						BreakingChange: true,
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "perf",
+						Description: "So fast!",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "chore",
+						Description: "Hidden",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+				sections: []Section{
+					{Type: SectionTypeBreaking, Title: "Breaking Changes"},
+					{Type: "feat", Title: "Features"},
+					{Type: "fix", Title: "Bug Fixes"},
+					{Type: "perf", Title: "Performance Improvements"},
+				},
+			},
+			want: `## [1.0.0](https://example.com/1.0.0)
+
+### Breaking Changes
+
+- Everything is different!
+
+### Bug Fixes
+
+- Foobar!
+
+### Performance Improvements
+
+- So fast!
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "sections with the same title",
+			args: args{
+				analyzedCommits: []commitparser.AnalyzedCommit{
+					{
+						Commit:      git.Commit{},
+						Type:        "perf",
+						Description: "So fast!",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "fix",
+						Description: "Foobar!",
+					},
+					{
+						Commit:      git.Commit{},
+						Type:        "refactor",
+						Description: "Cleaner code",
+					},
+				},
+				version: "1.0.0",
+				link:    "https://example.com/1.0.0",
+				sections: []Section{
+					{Type: "perf", Title: "Changed"},
+					{Type: "fix", Title: "Fixed"},
+					{Type: "refactor", Title: "Changed"},
+				},
+			},
+			want: `## [1.0.0](https://example.com/1.0.0)
+
+### Changed
+
+- So fast!
+- Cleaner code
+
+### Fixed
+
+- Foobar!
 `,
 			wantErr: assert.NoError,
 		},
@@ -168,7 +521,15 @@ This version is compatible with flux-compensator v2.2 - v2.9.
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data := New(commitparser.ByType(tt.args.analyzedCommits), tt.args.version, tt.args.link, tt.args.prefix, tt.args.suffix)
+			sections := tt.args.sections
+			if sections == nil {
+				sections = DefaultSections
+			}
+
+			data := New(tt.args.analyzedCommits, sections, tt.args.scopes, tt.args.version, tt.args.link, tt.args.prefix, tt.args.suffix)
+			data.NewContributors = tt.args.newContributors
+			data.PreviousVersion = tt.args.previousVersion
+			data.CompareLink = tt.args.compareLink
 			got, err := Entry(slog.Default(), DefaultTemplate(), data, Formatting{})
 			if !tt.wantErr(t, err) {
 				return
@@ -177,3 +538,88 @@ This version is compatible with flux-compensator v2.2 - v2.9.
 		})
 	}
 }
+
+func TestTemplate(t *testing.T) {
+	raw := `{{ range .Data.Sections }}{{ range .Commits }}- {{ .Description | upper }} ({{ .Hash | shortHash }})
+{{ end }}{{ end }}`
+
+	tpl, err := Template(raw)
+	require.NoError(t, err)
+
+	cached, err := Template(raw)
+	require.NoError(t, err)
+	assert.Same(t, tpl, cached)
+
+	data := New([]commitparser.AnalyzedCommit{
+		{Commit: git.Commit{Hash: "1234567890abcdef"}, Type: "feat", Description: "Foobar!"},
+	}, DefaultSections, Scopes{}, "v1.0.0", "", "", "")
+
+	got, err := Entry(slog.Default(), tpl, data, Formatting{})
+	require.NoError(t, err)
+	assert.Equal(t, "- FOOBAR! (1234567)\n", got)
+
+	_, err = Template("{{ .Data")
+	assert.Error(t, err)
+}
+
+func TestEntry_FrontMatter(t *testing.T) {
+	tpl, err := Template(`---
+title: "{{ .Data.Version }}"
+---
+
+{{ range .Data.Sections }}### {{ .Title }}
+{{ range .Commits }}
+- {{ .Description }}
+{{ end }}{{ end }}`)
+	require.NoError(t, err)
+
+	data := New([]commitparser.AnalyzedCommit{
+		{Commit: git.Commit{}, Type: "feat", Description: "Foobar!"},
+	}, DefaultSections, Scopes{}, "v1.0.0", "", "", "")
+
+	got, err := Entry(slog.Default(), tpl, data, Formatting{})
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: \"v1.0.0\"\n---\n\n### Features\n\n- Foobar!\n", got)
+}
+
+func TestLoadTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		readFile    func(path string) ([]byte, error)
+		wantDefault bool
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name: "custom template",
+			readFile: func(path string) ([]byte, error) {
+				return []byte("{{ .Data.Version }}"), nil
+			},
+			wantDefault: false,
+			wantErr:     assert.NoError,
+		},
+		{
+			name: "missing file",
+			readFile: func(path string) ([]byte, error) {
+				return nil, fs.ErrNotExist
+			},
+			wantDefault: true,
+			wantErr:     assert.NoError,
+		},
+		{
+			name: "read error",
+			readFile: func(path string) ([]byte, error) {
+				return nil, errors.New("permission denied")
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadTemplate(tt.readFile)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.wantDefault, got == DefaultTemplate())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available in all changelog templates. The arguments follow the order of the sprig library
+// (https://masterminds.github.io/sprig/), so the value can be passed through a pipeline: {{ .Title | trimPrefix "v" }}.
+var templateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	"default": func(fallback, value any) any {
+		if v := reflect.ValueOf(value); !v.IsValid() || v.IsZero() {
+			return fallback
+		}
+		return value
+	},
+	"escapeMarkdown": escapeMarkdown,
+	"shortHash": func(hash string) string {
+		if len(hash) > 7 {
+			return hash[:7]
+		}
+		return hash
+	},
+}
+
+// escapeMarkdown escapes characters in user provided text that would otherwise be interpreted as HTML by the
+// markdown renderer of the forge, e.g. "<T>" in a commit message. Code spans are kept as they are, because
+// backslashes are not interpreted inside of them. Other markdown syntax (emphasis, links) is intentionally kept.
+func escapeMarkdown(value any) string {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return ""
+	}
+	s := fmt.Sprint(v.Interface())
+
+	var b strings.Builder
+	inCodeSpan := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '`':
+			inCodeSpan = !inCodeSpan
+			b.WriteByte(c)
+		case c == '\\' && !inCodeSpan && i+1 < len(s):
+			// Already escaped character
+			b.WriteByte(c)
+			b.WriteByte(s[i+1])
+			i++
+		case (c == '<' || c == '>') && !inCodeSpan:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,45 @@
+{{define "entry" -}}
+- {{ if .Scope }}**{{ escapeMarkdown .Scope }}**: {{end}}{{ escapeMarkdown .Description }}{{ with .Author }} by @{{ . }}{{ end }}
+{{- with .PullRequest }}{{ if .LinkedIssues }} (closes {{ range $i, $issue := .LinkedIssues }}{{ if $i }}, {{ end }}{{ $issue }}{{ end }}){{ end }}{{ end }}
+{{- if .CoAuthors }} (co-authored by {{ range $i, $author := .CoAuthors }}{{ if $i }}, {{ end }}{{ escapeMarkdown $author }}{{ end }}){{ end }}
+{{- with .CommitURL }} ([{{ shortHash $.Hash }}]({{ . }})){{ end }}
+{{ with .Details }}
+{{ indent 2 . }}
+
+{{ end }}
+{{- end }}
+
+{{- if not .Formatting.HideVersionTitle }}
+## [{{ .Data.Version | trimPrefix "v" }}] - {{ .Data.Date }}
+{{ end -}}
+{{- with .Data.CompareLink }}
+[{{ $.Data.PreviousVersion }}...{{ $.Data.Version }}]({{ . }})
+{{ end -}}
+{{- if .Data.Prefix }}
+{{ .Data.Prefix }}
+{{ end -}}
+{{- range .Data.Sections }}
+### {{ .Title }}
+{{ if .Scopes }}
+{{- range .Scopes }}
+{{ if .Scope }}#### {{ escapeMarkdown .Scope }}
+
+{{ end -}}
+{{ range .Commits -}}{{template "entry" .}}{{end}}
+{{- end }}
+{{- else }}
+{{ range .Commits -}}{{template "entry" .}}{{end}}
+{{- end }}
+{{- end -}}
+
+{{- if .Data.NewContributors }}
+### New Contributors
+
+{{ range .Data.NewContributors -}}
+- @{{ .Login }} made their first contribution in [#{{ .PullRequestID }}]({{ .PullRequestURL }})
+{{ end -}}
+{{- end -}}
+
+{{- if .Data.Suffix }}
+{{ .Data.Suffix }}
+{{ end }}
@@ -0,0 +1,186 @@
+package changelog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"text/template"
+)
+
+// Format of the changelog file.
+type Format string
+
+const (
+	// FormatMarkdown renders the entries with the DefaultTemplate, or the custom template at TemplatePath.
+	FormatMarkdown Format = "markdown"
+	// FormatKeepAChangelog renders the entries in the format of https://keepachangelog.com. It should be used together
+	// with the KeepAChangelogSections.
+	FormatKeepAChangelog Format = "keep-a-changelog"
+	// FormatJSON renders every entry as a JSON object, to process the changelog in release pipelines.
+	FormatJSON Format = "json"
+)
+
+// KeepAChangelogSections map the commit types to the headings of https://keepachangelog.com.
+var KeepAChangelogSections = []Section{
+	{Type: "feat", Title: "Added"},
+	{Type: SectionTypeBreaking, Title: "Changed"},
+	{Type: "perf", Title: "Changed"},
+	{Type: "refactor", Title: "Changed"},
+	{Type: "revert", Title: "Removed"},
+	{Type: "fix", Title: "Fixed"},
+}
+
+// KeepAChangelogPreamble is added below the header of new changelog files with the FormatKeepAChangelog.
+const KeepAChangelogPreamble = `All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).`
+
+var keepAChangelogTemplate *template.Template
+
+//go:embed keepachangelog.md.tpl
+var rawKeepAChangelogTemplate string
+
+func init() {
+	var err error
+	keepAChangelogTemplate, err = parseTemplate(rawKeepAChangelogTemplate)
+	if err != nil {
+		log.Fatalf("failed to parse keep a changelog template: %v", err)
+	}
+}
+
+// Renderer renders the changelog entry of a release.
+type Renderer interface {
+	Render(data Data, formatting Formatting) (string, error)
+}
+
+// LoadRenderer returns the Renderer for the format. The markdown formats use the custom template at TemplatePath if it
+// exists, see LoadTemplate.
+func LoadRenderer(logger *slog.Logger, format Format, readFile func(path string) ([]byte, error)) (Renderer, error) {
+	switch format {
+	case FormatMarkdown, "":
+		tpl, err := LoadTemplate(readFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewTemplateRenderer(logger, tpl), nil
+	case FormatKeepAChangelog:
+		tpl, err := loadTemplate(readFile, keepAChangelogTemplate)
+		if err != nil {
+			return nil, err
+		}
+		return NewTemplateRenderer(logger, tpl), nil
+	case FormatJSON:
+		return JSONRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown changelog format %q", format)
+	}
+}
+
+type templateRenderer struct {
+	logger *slog.Logger
+	tpl    *template.Template
+}
+
+// NewTemplateRenderer returns a Renderer that executes the markdown template, see Entry.
+func NewTemplateRenderer(logger *slog.Logger, tpl *template.Template) Renderer {
+	return templateRenderer{logger: logger, tpl: tpl}
+}
+
+func (r templateRenderer) Render(data Data, formatting Formatting) (string, error) {
+	return Entry(r.logger, r.tpl, data, formatting)
+}
+
+// JSONRenderer renders the entry as an indented JSON object. The Formatting is ignored.
+type JSONRenderer struct{}
+
+type jsonEntry struct {
+	Version         string            `json:"version"`
+	URL             string            `json:"url,omitempty"`
+	PreviousVersion string            `json:"previous_version,omitempty"`
+	CompareURL      string            `json:"compare_url,omitempty"`
+	Date            string            `json:"date,omitempty"`
+	Prefix          string            `json:"prefix,omitempty"`
+	Suffix          string            `json:"suffix,omitempty"`
+	Sections        []jsonSection     `json:"sections"`
+	NewContributors []jsonContributor `json:"new_contributors,omitempty"`
+}
+
+type jsonSection struct {
+	Title   string       `json:"title"`
+	Commits []jsonCommit `json:"commits"`
+}
+
+type jsonCommit struct {
+	Type           string   `json:"type"`
+	Scope          *string  `json:"scope,omitempty"`
+	Description    string   `json:"description"`
+	BreakingChange bool     `json:"breaking_change"`
+	Hash           string   `json:"hash"`
+	Author         string   `json:"author,omitempty"`
+	CoAuthors      []string `json:"co_authors,omitempty"`
+	PullRequest    int      `json:"pull_request,omitempty"`
+	LinkedIssues   []string `json:"linked_issues,omitempty"`
+	CommitURL      string   `json:"commit_url,omitempty"`
+	Details        string   `json:"details,omitempty"`
+}
+
+type jsonContributor struct {
+	Login          string `json:"login"`
+	PullRequest    int    `json:"pull_request"`
+	PullRequestURL string `json:"pull_request_url"`
+}
+
+func (JSONRenderer) Render(data Data, _ Formatting) (string, error) {
+	entry := jsonEntry{
+		Version:         data.Version,
+		URL:             data.VersionLink,
+		PreviousVersion: data.PreviousVersion,
+		CompareURL:      data.CompareLink,
+		Date:            data.Date,
+		Prefix:          data.Prefix,
+		Suffix:          data.Suffix,
+		Sections:        make([]jsonSection, 0, len(data.Sections)),
+	}
+
+	for _, section := range data.Sections {
+		commits := make([]jsonCommit, 0, len(section.Commits))
+		for _, commit := range section.Commits {
+			out := jsonCommit{
+				Type:           commit.Type,
+				Scope:          commit.Scope,
+				Description:    commit.Description,
+				BreakingChange: commit.BreakingChange,
+				Hash:           commit.Hash,
+				Author:         commit.Author,
+				CoAuthors:      commit.CoAuthors,
+				CommitURL:      commit.CommitURL,
+				Details:        commit.Details,
+			}
+			if commit.PullRequest != nil {
+				out.PullRequest = commit.PullRequest.ID
+				out.LinkedIssues = commit.PullRequest.LinkedIssues
+			}
+
+			commits = append(commits, out)
+		}
+
+		entry.Sections = append(entry.Sections, jsonSection{Title: section.Title, Commits: commits})
+	}
+
+	for _, contributor := range data.NewContributors {
+		entry.NewContributors = append(entry.NewContributors, jsonContributor{
+			Login:          contributor.Login,
+			PullRequest:    contributor.PullRequestID,
+			PullRequestURL: contributor.PullRequestURL,
+		})
+	}
+
+	out, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out) + "\n", nil
+}
@@ -0,0 +1,198 @@
+package changelog
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestLoadRenderer(t *testing.T) {
+	missing := func(path string) ([]byte, error) { return nil, fs.ErrNotExist }
+	custom := func(path string) ([]byte, error) { return []byte("custom {{ .Data.Version }}\n"), nil }
+
+	data := Data{Version: "v1.0.0", VersionLink: "https://example.com/v1.0.0", Date: "2024-08-17"}
+
+	tests := []struct {
+		name     string
+		format   Format
+		readFile func(path string) ([]byte, error)
+		want     string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "default",
+			format:   "",
+			readFile: missing,
+			want:     "## [v1.0.0](https://example.com/v1.0.0)\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "markdown",
+			format:   FormatMarkdown,
+			readFile: missing,
+			want:     "## [v1.0.0](https://example.com/v1.0.0)\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "keep a changelog",
+			format:   FormatKeepAChangelog,
+			readFile: missing,
+			want:     "## [1.0.0] - 2024-08-17\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "keep a changelog with custom template",
+			format:   FormatKeepAChangelog,
+			readFile: custom,
+			want:     "custom v1.0.0\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "json ignores custom template",
+			format:   FormatJSON,
+			readFile: custom,
+			want:     "{\n  \"version\": \"v1.0.0\",\n  \"url\": \"https://example.com/v1.0.0\",\n  \"date\": \"2024-08-17\",\n  \"sections\": []\n}\n",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "read error",
+			format:   FormatMarkdown,
+			readFile: func(path string) ([]byte, error) { return nil, errors.New("permission denied") },
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "unknown format",
+			format:   "asciidoc",
+			readFile: missing,
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := LoadRenderer(slog.Default(), tt.format, tt.readFile)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			got, err := renderer.Render(data, Formatting{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKeepAChangelog(t *testing.T) {
+	commits := []commitparser.AnalyzedCommit{
+		{Commit: git.Commit{}, Type: "feat", Description: "Foobar!"},
+		{Commit: git.Commit{}, Type: "fix", Description: "Fixed the foo"},
+		{Commit: git.Commit{}, Type: "feat", Description: "Removed the bar", BreakingChange: true},
+		{Commit: git.Commit{}, Type: "perf", Description: "So fast!"},
+		{Commit: git.Commit{}, Type: "revert", Description: "Undo the baz"},
+	}
+
+	data := New(commits, KeepAChangelogSections, Scopes{}, "v1.0.0", "https://example.com/v1.0.0", "", "")
+	data.Date = "2024-08-17"
+
+	renderer, err := LoadRenderer(slog.Default(), FormatKeepAChangelog, func(path string) ([]byte, error) { return nil, fs.ErrNotExist })
+	require.NoError(t, err)
+
+	got, err := renderer.Render(data, Formatting{})
+	require.NoError(t, err)
+	assert.Equal(t, `## [1.0.0] - 2024-08-17
+
+### Added
+
+- Foobar!
+
+### Changed
+
+- Removed the bar
+- So fast!
+
+### Removed
+
+- Undo the baz
+
+### Fixed
+
+- Fixed the foo
+`, got)
+}
+
+func TestJSONRenderer_Render(t *testing.T) {
+	data := New([]commitparser.AnalyzedCommit{
+		{
+			Commit:      git.Commit{Hash: "1234567890abcdef", PullRequest: &git.PullRequest{ID: 12, LinkedIssues: []string{"#10"}}},
+			Type:        "feat",
+			Scope:       ptr("api"),
+			Description: "Foobar!",
+			Author:      "jane",
+		},
+		{
+			Commit:         git.Commit{Hash: "abcdef1234567890"},
+			Type:           "fix",
+			Description:    "Fixed the foo",
+			BreakingChange: true,
+			CommitURL:      "https://example.com/commit/abcdef1234567890",
+		},
+	}, DefaultSections, Scopes{}, "v1.0.0", "https://example.com/v1.0.0", "Prefix text", "")
+	data.PreviousVersion = "v0.9.0"
+	data.CompareLink = "https://example.com/compare/v0.9.0...v1.0.0"
+	data.NewContributors = []Contributor{{Login: "jane", PullRequestID: 12, PullRequestURL: "https://example.com/pull/12"}}
+
+	got, err := JSONRenderer{}.Render(data, Formatting{HideVersionTitle: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "version": "v1.0.0",
+  "url": "https://example.com/v1.0.0",
+  "previous_version": "v0.9.0",
+  "compare_url": "https://example.com/compare/v0.9.0...v1.0.0",
+  "prefix": "Prefix text",
+  "sections": [
+    {
+      "title": "Features",
+      "commits": [
+        {
+          "type": "feat",
+          "scope": "api",
+          "description": "Foobar!",
+          "breaking_change": false,
+          "hash": "1234567890abcdef",
+          "author": "jane",
+          "pull_request": 12,
+          "linked_issues": [
+            "#10"
+          ]
+        }
+      ]
+    },
+    {
+      "title": "Bug Fixes",
+      "commits": [
+        {
+          "type": "fix",
+          "description": "Fixed the foo",
+          "breaking_change": true,
+          "hash": "abcdef1234567890",
+          "commit_url": "https://example.com/commit/abcdef1234567890"
+        }
+      ]
+    }
+  ],
+  "new_contributors": [
+    {
+      "login": "jane",
+      "pull_request": 12,
+      "pull_request_url": "https://example.com/pull/12"
+    }
+  ]
+}
+`, got)
+}
@@ -0,0 +1,59 @@
+package changelog
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// Order of the entries in every section of the changelog.
+type Order string
+
+const (
+	// OrderMergeTime lists the entries in the order they were merged into the branch, oldest first. This is the
+	// default.
+	OrderMergeTime Order = "merge-time"
+	// OrderPullRequest lists the entries by the number of their pull request, lowest first. Entries without a pull
+	// request are listed last.
+	OrderPullRequest Order = "pull-request"
+	// OrderScope lists the entries alphabetically by their scope. Entries without a scope are listed first.
+	OrderScope Order = "scope"
+)
+
+// Sort orders the commits in place. Commits that are equal in the order are sorted by merge time, so the result does
+// not depend on the order in which the forge returned the commits. Commits with the same date keep their order.
+func Sort(commits []commitparser.AnalyzedCommit, order Order) {
+	slices.SortStableFunc(commits, func(a, b commitparser.AnalyzedCommit) int {
+		var c int
+		switch order {
+		case OrderPullRequest:
+			c = comparePullRequests(a.PullRequest, b.PullRequest)
+		case OrderScope:
+			c = cmp.Compare(scope(a), scope(b))
+		}
+
+		return cmp.Or(c, a.Date.Compare(b.Date))
+	})
+}
+
+func comparePullRequests(a, b *git.PullRequest) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return cmp.Compare(a.ID, b.ID)
+	}
+}
+
+func scope(commit commitparser.AnalyzedCommit) string {
+	if commit.Scope == nil {
+		return ""
+	}
+	return *commit.Scope
+}
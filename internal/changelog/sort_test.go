@@ -0,0 +1,79 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestSort(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 8, d, 12, 0, 0, 0, time.UTC) }
+
+	// In the order of the forge, newest first
+	commits := []commitparser.AnalyzedCommit{
+		{Commit: git.Commit{Hash: "e", Date: day(5), PullRequest: &git.PullRequest{ID: 3}}, Scope: ptr("cli")},
+		{Commit: git.Commit{Hash: "d", Date: day(4)}, Scope: ptr("cli")},
+		{Commit: git.Commit{Hash: "c", Date: day(3), PullRequest: &git.PullRequest{ID: 7}}},
+		{Commit: git.Commit{Hash: "b", Date: day(2), PullRequest: &git.PullRequest{ID: 12}}, Scope: ptr("api")},
+		{Commit: git.Commit{Hash: "a", Date: day(1), PullRequest: &git.PullRequest{ID: 5}}, Scope: ptr("api")},
+	}
+
+	tests := []struct {
+		name  string
+		order Order
+		want  []string
+	}{
+		{
+			name:  "default",
+			order: "",
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:  "merge time",
+			order: OrderMergeTime,
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:  "pull request",
+			order: OrderPullRequest,
+			want:  []string{"e", "a", "c", "b", "d"},
+		},
+		{
+			name:  "scope",
+			order: OrderScope,
+			want:  []string{"c", "a", "b", "d", "e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The result must not depend on the order of the input
+			for _, input := range [][]commitparser.AnalyzedCommit{
+				commits,
+				{commits[4], commits[3], commits[2], commits[1], commits[0]},
+				{commits[2], commits[0], commits[4], commits[1], commits[3]},
+			} {
+				sorted := make([]commitparser.AnalyzedCommit, len(input))
+				copy(sorted, input)
+				Sort(sorted, tt.order)
+
+				hashes := make([]string, 0, len(sorted))
+				for _, commit := range sorted {
+					hashes = append(hashes, commit.Hash)
+				}
+				assert.Equal(t, tt.want, hashes)
+			}
+		})
+	}
+
+	t.Run("same date", func(t *testing.T) {
+		// Commits without a date keep their order
+		sorted := []commitparser.AnalyzedCommit{{Commit: git.Commit{Hash: "b"}}, {Commit: git.Commit{Hash: "a"}}}
+		Sort(sorted, OrderMergeTime)
+		assert.Equal(t, "b", sorted[0].Hash)
+		assert.Equal(t, "a", sorted[1].Hash)
+	})
+}
@@ -1,11 +1,20 @@
 package commitparser
 
 import (
+	"errors"
+
 	"github.com/apricote/releaser-pleaser/internal/git"
 )
 
+// ErrSkipped is returned by CommitParser.Parse for commits with the changelog skip trailer.
+var ErrSkipped = errors.New("commit has the changelog skip trailer")
+
 type CommitParser interface {
 	Analyze(commits []git.Commit) ([]AnalyzedCommit, error)
+	// Parse parses the message of a single commit. Unlike Analyze, it returns an error if the message can not be
+	// parsed, instead of skipping the commit, and it keeps commits of all types. Commits with the changelog skip
+	// trailer return ErrSkipped.
+	Parse(commit git.Commit) (AnalyzedCommit, error)
 }
 
 type AnalyzedCommit struct {
@@ -14,6 +23,17 @@ type AnalyzedCommit struct {
 	Description    string
 	Scope          *string
 	BreakingChange bool
+
+	// CoAuthors are the names from the Co-authored-by trailers of the commit.
+	CoAuthors []string
+	// Author is the username that is credited for the change in the changelog. It is only set if the attribution
+	// is enabled.
+	Author string
+	// CommitURL links to the commit on the forge. It is only set for commits without a pull request.
+	CommitURL string
+	// Details are the release notes from the description of the pull request, in Markdown. They are rendered below
+	// the entry in the changelog.
+	Details string
 }
 
 // ByType groups the Commits by the type field. Used by the Changelog.
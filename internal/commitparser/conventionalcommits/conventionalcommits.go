@@ -3,6 +3,7 @@ package conventionalcommits
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/leodido/go-conventionalcommits"
@@ -12,20 +13,36 @@ import (
 	"github.com/apricote/releaser-pleaser/internal/git"
 )
 
+// Trailers in the commit message footer that change how the commit is shown in the changelog, see the trailers in
+// package commitparser. The parser returns the trailer keys in lower case.
+const (
+	TrailerReleaseNote   = commitparser.TrailerReleaseNote
+	TrailerChangelog     = commitparser.TrailerChangelog
+	TrailerChangelogSkip = commitparser.TrailerChangelogSkip
+	TrailerCoAuthoredBy  = commitparser.TrailerCoAuthoredBy
+)
+
+// ErrSkipped is returned by Parser.Parse for commits with the changelog skip trailer.
+var ErrSkipped = commitparser.ErrSkipped
+
 type Parser struct {
-	machine conventionalcommits.Machine
-	logger  *slog.Logger
+	machine         conventionalcommits.Machine
+	logger          *slog.Logger
+	additionalTypes []string
 }
 
-func NewParser(logger *slog.Logger) *Parser {
+// NewParser returns a Parser that keeps all releasable commits. Commits of the additionalTypes are kept too, even
+// though they do not cause a version bump on their own.
+func NewParser(logger *slog.Logger, additionalTypes ...string) *Parser {
 	parserMachine := parser.NewMachine(
 		parser.WithBestEffort(),
 		parser.WithTypes(conventionalcommits.TypesConventional),
 	)
 
 	return &Parser{
-		machine: parserMachine,
-		logger:  logger,
+		machine:         parserMachine,
+		logger:          logger,
+		additionalTypes: additionalTypes,
 	}
 }
 
@@ -54,19 +71,54 @@ func (c *Parser) Analyze(commits []git.Commit) ([]commitparser.AnalyzedCommit, e
 			continue
 		}
 
+		if skipChangelog(conventionalCommit) {
+			c.logger.Debug("commit has changelog skip trailer, skipping", "commit.hash", commit.Hash)
+			continue
+		}
+
 		commitVersionBump := conventionalCommit.VersionBump(conventionalcommits.DefaultStrategy)
-		if commitVersionBump > conventionalcommits.UnknownVersion {
-			// We only care about releasable commits
-			analyzedCommits = append(analyzedCommits, commitparser.AnalyzedCommit{
-				Commit:         commit,
-				Type:           conventionalCommit.Type,
-				Description:    conventionalCommit.Description,
-				Scope:          conventionalCommit.Scope,
-				BreakingChange: conventionalCommit.IsBreakingChange(),
-			})
+		if commitVersionBump > conventionalcommits.UnknownVersion || slices.Contains(c.additionalTypes, conventionalCommit.Type) {
+			// We only care about releasable commits and those the user wants to see in the changelog
+			analyzedCommits = append(analyzedCommits, analyzedCommit(commit, conventionalCommit))
 		}
 
 	}
 
 	return analyzedCommits, nil
 }
+
+// Parse parses the message of a single commit. Unlike Analyze, it returns an error if the message is not a valid
+// conventional commit, instead of using as much as possible, and it keeps commits of all types. Commits with the
+// changelog skip trailer return ErrSkipped.
+func (c *Parser) Parse(commit git.Commit) (commitparser.AnalyzedCommit, error) {
+	msg, err := c.machine.Parse([]byte(strings.TrimSpace(commit.Message)))
+	if err != nil {
+		return commitparser.AnalyzedCommit{}, err
+	}
+
+	conventionalCommit, ok := msg.(*conventionalcommits.ConventionalCommit)
+	if !ok {
+		return commitparser.AnalyzedCommit{}, fmt.Errorf("unable to get ConventionalCommit from parser result: %T", msg)
+	}
+
+	if skipChangelog(conventionalCommit) {
+		return commitparser.AnalyzedCommit{}, ErrSkipped
+	}
+
+	return analyzedCommit(commit, conventionalCommit), nil
+}
+
+func skipChangelog(conventionalCommit *conventionalcommits.ConventionalCommit) bool {
+	return commitparser.SkipChangelog(conventionalCommit.Footers)
+}
+
+func analyzedCommit(commit git.Commit, conventionalCommit *conventionalcommits.ConventionalCommit) commitparser.AnalyzedCommit {
+	return commitparser.AnalyzedCommit{
+		Commit:         commit,
+		Type:           conventionalCommit.Type,
+		Description:    commitparser.ReleaseNote(conventionalCommit.Footers, conventionalCommit.Description),
+		Scope:          conventionalCommit.Scope,
+		BreakingChange: conventionalCommit.IsBreakingChange(),
+		CoAuthors:      commitparser.CoAuthors(conventionalCommit.Footers[TrailerCoAuthoredBy]),
+	}
+}
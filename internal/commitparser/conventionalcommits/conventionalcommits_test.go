@@ -8,6 +8,7 @@ import (
 
 	"github.com/apricote/releaser-pleaser/internal/commitparser"
 	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/pointer"
 )
 
 func TestAnalyzeCommits(t *testing.T) {
@@ -46,6 +47,49 @@ func TestAnalyzeCommits(t *testing.T) {
 			expectedCommits: []commitparser.AnalyzedCommit{},
 			wantErr:         assert.NoError,
 		},
+		{
+			name: "release note trailer",
+			commits: []git.Commit{
+				{
+					Message: "fix: foo\n\nRelease-Note: Fixed the foo in bar",
+				},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{
+					Commit:      git.Commit{Message: "fix: foo\n\nRelease-Note: Fixed the foo in bar"},
+					Type:        "fix",
+					Description: "Fixed the foo in bar",
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog skip trailer",
+			commits: []git.Commit{
+				{
+					Message: "feat: internal only\n\nChangelog: skip",
+				},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+			wantErr:         assert.NoError,
+		},
+		{
+			name: "co-authored-by trailers",
+			commits: []git.Commit{
+				{
+					Message: "feat: foo\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Bob <bob@example.com>",
+				},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{
+					Commit:      git.Commit{Message: "feat: foo\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Bob <bob@example.com>"},
+					Type:        "feat",
+					Description: "foo",
+					CoAuthors:   []string{"Jane Doe", "Bob"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "drops unreleasable",
 			commits: []git.Commit{
@@ -155,3 +199,98 @@ func TestAnalyzeCommits(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeCommits_AdditionalTypes(t *testing.T) {
+	tests := []struct {
+		name            string
+		additionalTypes []string
+		commits         []git.Commit
+		expectedCommits []commitparser.AnalyzedCommit
+		wantErr         assert.ErrorAssertionFunc
+	}{
+		{
+			name:            "keeps additional types",
+			additionalTypes: []string{"perf", "docs"},
+			commits: []git.Commit{
+				{
+					Message: "chore: foobar",
+				},
+				{
+					Message: "perf: faster",
+				},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{
+					Commit:      git.Commit{Message: "perf: faster"},
+					Type:        "perf",
+					Description: "faster",
+				},
+			},
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzedCommits, err := NewParser(slog.Default(), tt.additionalTypes...).Analyze(tt.commits)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedCommits, analyzedCommits)
+		})
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  git.Commit
+		want    commitparser.AnalyzedCommit
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "valid",
+			commit: git.Commit{Message: "feat(api): foo\n"},
+			want: commitparser.AnalyzedCommit{
+				Commit:      git.Commit{Message: "feat(api): foo\n"},
+				Type:        "feat",
+				Scope:       pointer.Pointer("api"),
+				Description: "foo",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "keeps types that are not released",
+			commit:  git.Commit{Message: "chore: foo"},
+			want:    commitparser.AnalyzedCommit{Commit: git.Commit{Message: "chore: foo"}, Type: "chore", Description: "foo"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "malformed",
+			commit:  git.Commit{Message: "Update the foo"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "missing description",
+			commit:  git.Commit{Message: "fix:"},
+			wantErr: assert.Error,
+		},
+		{
+			name:   "changelog skip trailer",
+			commit: git.Commit{Message: "feat: internal only\n\nChangelog: skip"},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrSkipped)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewParser(slog.Default()).Parse(tt.commit)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gitmoji
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// Types maps the gitmojis to the conventional commit types that are used for the changelog sections and the version
+// bump. Every gitmoji is listed as emoji and as shortcode. Gitmojis that are not listed here are ignored.
+var Types = map[string]string{
+	"✨": "feat", ":sparkles:": "feat",
+	"💥": "feat", ":boom:": "feat",
+	"🐛": "fix", ":bug:": "fix",
+	"🚑": "fix", ":ambulance:": "fix",
+	"🩹": "fix", ":adhesive_bandage:": "fix",
+	"🔒": "fix", ":lock:": "fix",
+	"⚡": "perf", ":zap:": "perf",
+	"📝": "docs", ":memo:": "docs",
+	"♻": "refactor", ":recycle:": "refactor",
+	"🔥": "refactor", ":fire:": "refactor",
+	"🎨": "style", ":art:": "style",
+	"✅": "test", ":white_check_mark:": "test",
+	"👷": "ci", ":construction_worker:": "ci",
+	"💚": "ci", ":green_heart:": "ci",
+	"📦": "build", ":package:": "build",
+	"⬆": "build", ":arrow_up:": "build",
+	"⬇": "build", ":arrow_down:": "build",
+	"➕": "build", ":heavy_plus_sign:": "build",
+	"➖": "build", ":heavy_minus_sign:": "build",
+	"🔧": "chore", ":wrench:": "chore",
+	"🔖": "chore", ":bookmark:": "chore",
+	"⏪": "revert", ":rewind:": "revert",
+}
+
+// breaking are the gitmojis for breaking changes.
+var breaking = []string{"💥", ":boom:"}
+
+// subjectRegex matches the format of gitmoji commits "<intention> [(scope)][:] <message>", e.g.
+// "✨ (api): add movie endpoints" or ":bug: fix login".
+var subjectRegex = regexp.MustCompile(`^(:[a-z0-9_+-]+:|[^\s(:]+)\s*(?:\(([^)]*)\))?:?\s+(.+)$`)
+
+type Parser struct {
+	logger          *slog.Logger
+	additionalTypes []string
+}
+
+// NewParser returns a Parser that keeps all releasable commits. Commits of the additionalTypes are kept too, even
+// though they do not cause a version bump on their own.
+func NewParser(logger *slog.Logger, additionalTypes ...string) *Parser {
+	return &Parser{
+		logger:          logger,
+		additionalTypes: additionalTypes,
+	}
+}
+
+// errUnknownGitmoji is returned by Parser.Parse for commits with a gitmoji that is not listed in Types.
+var errUnknownGitmoji = errors.New("unknown gitmoji")
+
+func (p *Parser) Analyze(commits []git.Commit) ([]commitparser.AnalyzedCommit, error) {
+	analyzedCommits := make([]commitparser.AnalyzedCommit, 0, len(commits))
+
+	for _, commit := range commits {
+		analyzed, err := p.Parse(commit)
+		switch {
+		case errors.Is(err, commitparser.ErrSkipped):
+			p.logger.Debug("commit has changelog skip trailer, skipping", "commit.hash", commit.Hash)
+			continue
+		case errors.Is(err, errUnknownGitmoji):
+			p.logger.Debug("commit has unknown gitmoji, skipping", "commit.hash", commit.Hash, "err", err)
+			continue
+		case err != nil:
+			p.logger.Warn("failed to parse message of commit, skipping", "commit.hash", commit.Hash)
+			continue
+		}
+
+		if analyzed.BreakingChange || analyzed.Type == "feat" || analyzed.Type == "fix" || slices.Contains(p.additionalTypes, analyzed.Type) {
+			// We only care about releasable commits and those the user wants to see in the changelog
+			analyzedCommits = append(analyzedCommits, analyzed)
+		}
+	}
+
+	return analyzedCommits, nil
+}
+
+// Parse parses the message of a single commit. Unlike Analyze, it returns an error if the subject does not start with
+// a known gitmoji, and it keeps commits of all types. Commits with the changelog skip trailer return
+// commitparser.ErrSkipped.
+func (p *Parser) Parse(commit git.Commit) (commitparser.AnalyzedCommit, error) {
+	message := strings.TrimSpace(commit.Message)
+	subject, _, _ := strings.Cut(message, "\n")
+
+	match := subjectRegex.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return commitparser.AnalyzedCommit{}, errors.New("subject does not match the format \"<gitmoji> [(scope)][:] <message>\"")
+	}
+
+	// Emojis are written with and without the variation selector, e.g. "⚡️" and "⚡".
+	intention := strings.ReplaceAll(match[1], "\ufe0f", "")
+	commitType, ok := Types[intention]
+	if !ok {
+		return commitparser.AnalyzedCommit{}, fmt.Errorf("%w %q", errUnknownGitmoji, match[1])
+	}
+
+	trailers := commitparser.Trailers(message)
+	if commitparser.SkipChangelog(trailers) {
+		return commitparser.AnalyzedCommit{}, commitparser.ErrSkipped
+	}
+
+	analyzed := commitparser.AnalyzedCommit{
+		Commit:         commit,
+		Type:           commitType,
+		Description:    commitparser.ReleaseNote(trailers, match[3]),
+		BreakingChange: slices.Contains(breaking, intention) || len(trailers[commitparser.TrailerBreakingChange]) > 0,
+		CoAuthors:      commitparser.CoAuthors(trailers[commitparser.TrailerCoAuthoredBy]),
+	}
+	if match[2] != "" {
+		analyzed.Scope = &match[2]
+	}
+
+	return analyzed, nil
+}
@@ -0,0 +1,146 @@
+package gitmoji
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/pointer"
+)
+
+func TestAnalyzeCommits(t *testing.T) {
+	tests := []struct {
+		name            string
+		commits         []git.Commit
+		additionalTypes []string
+		expectedCommits []commitparser.AnalyzedCommit
+	}{
+		{
+			name:            "empty commits",
+			commits:         []git.Commit{},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name:            "skips message without gitmoji",
+			commits:         []git.Commit{{Message: "Add movie endpoints"}},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name:            "skips unknown gitmoji",
+			commits:         []git.Commit{{Message: "🙈 Ignore build output"}},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name:    "emoji",
+			commits: []git.Commit{{Message: "✨ Add movie endpoints"}},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "✨ Add movie endpoints"}, Type: "feat", Description: "Add movie endpoints"},
+			},
+		},
+		{
+			name:    "shortcode with scope",
+			commits: []git.Commit{{Message: ":bug: (db): Fix invalid schema"}},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: ":bug: (db): Fix invalid schema"}, Type: "fix", Scope: pointer.Pointer("db"), Description: "Fix invalid schema"},
+			},
+		},
+		{
+			name:            "variation selector",
+			commits:         []git.Commit{{Message: "⚡️ Cache responses"}},
+			additionalTypes: []string{"perf"},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "⚡️ Cache responses"}, Type: "perf", Description: "Cache responses"},
+			},
+		},
+		{
+			name:            "drops unreleasable",
+			commits:         []git.Commit{{Message: "📝 Update README"}},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name: "breaking changes",
+			commits: []git.Commit{
+				{Message: "💥 Remove v1 API"},
+				{Message: "♻️ Rename config keys\n\nBREAKING CHANGE: the config keys use kebab-case"},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "💥 Remove v1 API"}, Type: "feat", Description: "Remove v1 API", BreakingChange: true},
+				{Commit: git.Commit{Message: "♻️ Rename config keys\n\nBREAKING CHANGE: the config keys use kebab-case"}, Type: "refactor", Description: "Rename config keys", BreakingChange: true},
+			},
+		},
+		{
+			name: "trailers",
+			commits: []git.Commit{
+				{Message: "🐛 Fix foo\n\nRelease-Note: Fixed the foo in bar\nCo-authored-by: Jane Doe <jane@example.com>"},
+				{Message: "✨ Internal only\n\nChangelog: skip"},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{
+					Commit:      git.Commit{Message: "🐛 Fix foo\n\nRelease-Note: Fixed the foo in bar\nCo-authored-by: Jane Doe <jane@example.com>"},
+					Type:        "fix",
+					Description: "Fixed the foo in bar",
+					CoAuthors:   []string{"Jane Doe"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzedCommits, err := NewParser(slog.Default(), tt.additionalTypes...).Analyze(tt.commits)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCommits, analyzedCommits)
+		})
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  git.Commit
+		want    commitparser.AnalyzedCommit
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid",
+			commit:  git.Commit{Message: "✨ (api): foo"},
+			want:    commitparser.AnalyzedCommit{Commit: git.Commit{Message: "✨ (api): foo"}, Type: "feat", Scope: pointer.Pointer("api"), Description: "foo"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "keeps types that are not released",
+			commit:  git.Commit{Message: ":memo: foo"},
+			want:    commitparser.AnalyzedCommit{Commit: git.Commit{Message: ":memo: foo"}, Type: "docs", Description: "foo"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "malformed",
+			commit:  git.Commit{Message: "✨"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown gitmoji",
+			commit:  git.Commit{Message: "feat: foo"},
+			wantErr: assert.Error,
+		},
+		{
+			name:   "changelog skip trailer",
+			commit: git.Commit{Message: "✨ internal only\n\nChangelog: skip"},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, commitparser.ErrSkipped)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewParser(slog.Default()).Parse(tt.commit)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
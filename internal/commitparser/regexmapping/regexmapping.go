@@ -0,0 +1,154 @@
+package regexmapping
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// Names of the capture groups that can be used in the Pattern of a Rule.
+const (
+	GroupType        = "type"
+	GroupScope       = "scope"
+	GroupDescription = "description"
+)
+
+// Rule maps the commits with a subject matching the Pattern to a commit type.
+type Rule struct {
+	// Pattern is matched against the subject of the commit. The named capture groups GroupType, GroupScope and
+	// GroupDescription set the respective field of the commit. Without GroupDescription, the description is the
+	// subject without the matched text.
+	Pattern *regexp.Regexp
+	// Type of the matched commits, e.g. "feat". Required unless the Pattern has the capture group GroupType.
+	Type string
+	// Breaking marks all matched commits as breaking changes.
+	Breaking bool
+}
+
+// NewRule compiles the pattern and validates the Rule.
+func NewRule(pattern, commitType string, breaking bool) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	if commitType == "" && !slices.Contains(re.SubexpNames(), GroupType) {
+		return Rule{}, errors.New("type is required if the pattern has no type group")
+	}
+
+	return Rule{Pattern: re, Type: commitType, Breaking: breaking}, nil
+}
+
+type Parser struct {
+	logger          *slog.Logger
+	rules           []Rule
+	additionalTypes []string
+}
+
+// NewParser returns a Parser that maps the commits with the first matching rule and keeps all releasable commits.
+// Commits of the additionalTypes are kept too, even though they do not cause a version bump on their own.
+func NewParser(logger *slog.Logger, rules []Rule, additionalTypes ...string) *Parser {
+	return &Parser{
+		logger:          logger,
+		rules:           rules,
+		additionalTypes: additionalTypes,
+	}
+}
+
+func (p *Parser) Analyze(commits []git.Commit) ([]commitparser.AnalyzedCommit, error) {
+	analyzedCommits := make([]commitparser.AnalyzedCommit, 0, len(commits))
+
+	for _, commit := range commits {
+		analyzed, err := p.Parse(commit)
+		if errors.Is(err, commitparser.ErrSkipped) {
+			p.logger.Debug("commit has changelog skip trailer, skipping", "commit.hash", commit.Hash)
+			continue
+		}
+		if err != nil {
+			p.logger.Debug("commit does not match any rule, skipping", "commit.hash", commit.Hash)
+			continue
+		}
+
+		if analyzed.BreakingChange || analyzed.Type == "feat" || analyzed.Type == "fix" || slices.Contains(p.additionalTypes, analyzed.Type) {
+			// We only care about releasable commits and those the user wants to see in the changelog
+			analyzedCommits = append(analyzedCommits, analyzed)
+		}
+	}
+
+	return analyzedCommits, nil
+}
+
+// Parse maps a single commit with the first matching rule. Unlike Analyze, it returns an error if no rule matches,
+// and it keeps commits of all types. Commits with the changelog skip trailer return commitparser.ErrSkipped.
+func (p *Parser) Parse(commit git.Commit) (commitparser.AnalyzedCommit, error) {
+	message := strings.TrimSpace(commit.Message)
+	subject, _, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	analyzed, ok := p.match(subject)
+	if !ok {
+		return commitparser.AnalyzedCommit{}, errors.New("subject does not match any rule")
+	}
+
+	trailers := commitparser.Trailers(message)
+	if commitparser.SkipChangelog(trailers) {
+		return commitparser.AnalyzedCommit{}, commitparser.ErrSkipped
+	}
+
+	analyzed.Commit = commit
+	analyzed.Description = commitparser.ReleaseNote(trailers, analyzed.Description)
+	analyzed.BreakingChange = analyzed.BreakingChange || len(trailers[commitparser.TrailerBreakingChange]) > 0
+	analyzed.CoAuthors = commitparser.CoAuthors(trailers[commitparser.TrailerCoAuthoredBy])
+
+	return analyzed, nil
+}
+
+// match returns the type, scope, description and breaking change of the first rule that matches the subject.
+func (p *Parser) match(subject string) (commitparser.AnalyzedCommit, bool) {
+	for _, rule := range p.rules {
+		loc := rule.Pattern.FindStringSubmatchIndex(subject)
+		if loc == nil {
+			continue
+		}
+
+		analyzed := commitparser.AnalyzedCommit{
+			Type:           rule.Type,
+			Description:    strings.TrimSpace(subject[:loc[0]] + subject[loc[1]:]),
+			BreakingChange: rule.Breaking,
+		}
+
+		for i, name := range rule.Pattern.SubexpNames() {
+			if name == "" || loc[2*i] < 0 {
+				continue
+			}
+
+			value := strings.TrimSpace(subject[loc[2*i]:loc[2*i+1]])
+			switch name {
+			case GroupType:
+				if value != "" {
+					analyzed.Type = strings.ToLower(value)
+				}
+			case GroupScope:
+				if value != "" {
+					analyzed.Scope = &value
+				}
+			case GroupDescription:
+				analyzed.Description = value
+			}
+		}
+
+		if analyzed.Type == "" || analyzed.Description == "" {
+			continue
+		}
+
+		return analyzed, true
+	}
+
+	return commitparser.AnalyzedCommit{}, false
+}
@@ -0,0 +1,167 @@
+package regexmapping
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/pointer"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		commitType string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{name: "with type", pattern: `^\[Feature\]`, commitType: "feat", wantErr: assert.NoError},
+		{name: "with type group", pattern: `^(?P<type>\w+):`, wantErr: assert.NoError},
+		{name: "without type", pattern: `^\[Feature\]`, wantErr: assert.Error},
+		{name: "invalid pattern", pattern: `^[Feature`, commitType: "feat", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRule(tt.pattern, tt.commitType, false)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestAnalyzeCommits(t *testing.T) {
+	rule := func(pattern, commitType string, breaking bool) Rule {
+		r, err := NewRule(pattern, commitType, breaking)
+		require.NoError(t, err)
+		return r
+	}
+
+	rules := []Rule{
+		rule(`^\[Breaking\]`, "feat", true),
+		rule(`^\[Feature\]`, "feat", false),
+		rule(`^(?i)fix(ed|es)?\b`, "fix", false),
+		rule(`^(?P<scope>[a-z]+) \| (?P<type>[A-Z]+) \| (?P<description>.+)$`, "", false),
+		rule(`^Docs:`, "docs", false),
+	}
+
+	tests := []struct {
+		name            string
+		commits         []git.Commit
+		expectedCommits []commitparser.AnalyzedCommit
+	}{
+		{
+			name:            "empty commits",
+			commits:         []git.Commit{},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name:            "skips commits without matching rule",
+			commits:         []git.Commit{{Message: "Update dependencies"}},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name: "removes matched text",
+			commits: []git.Commit{
+				{Message: "[Feature] Add movie endpoints"},
+				{Message: "Fixed crash on startup"},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "[Feature] Add movie endpoints"}, Type: "feat", Description: "Add movie endpoints"},
+				{Commit: git.Commit{Message: "Fixed crash on startup"}, Type: "fix", Description: "crash on startup"},
+			},
+		},
+		{
+			name:    "capture groups",
+			commits: []git.Commit{{Message: "api | FEAT | Add movie endpoints"}},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "api | FEAT | Add movie endpoints"}, Type: "feat", Scope: pointer.Pointer("api"), Description: "Add movie endpoints"},
+			},
+		},
+		{
+			name: "breaking changes",
+			commits: []git.Commit{
+				{Message: "[Breaking] Remove v1 API"},
+				{Message: "Docs: Rename config keys\n\nBREAKING CHANGE: the config keys use kebab-case"},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "[Breaking] Remove v1 API"}, Type: "feat", Description: "Remove v1 API", BreakingChange: true},
+				{Commit: git.Commit{Message: "Docs: Rename config keys\n\nBREAKING CHANGE: the config keys use kebab-case"}, Type: "docs", Description: "Rename config keys", BreakingChange: true},
+			},
+		},
+		{
+			name:            "drops unreleasable",
+			commits:         []git.Commit{{Message: "Docs: Update README"}},
+			expectedCommits: []commitparser.AnalyzedCommit{},
+		},
+		{
+			name: "trailers",
+			commits: []git.Commit{
+				{Message: "Fix foo\n\nRelease-Note: Fixed the foo in bar"},
+				{Message: "[Feature] Internal only\n\nChangelog: skip"},
+			},
+			expectedCommits: []commitparser.AnalyzedCommit{
+				{Commit: git.Commit{Message: "Fix foo\n\nRelease-Note: Fixed the foo in bar"}, Type: "fix", Description: "Fixed the foo in bar"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzedCommits, err := NewParser(slog.Default(), rules).Analyze(tt.commits)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCommits, analyzedCommits)
+		})
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	rules := []Rule{
+		{Pattern: regexp.MustCompile(`^\[Feature\]`), Type: "feat"},
+		{Pattern: regexp.MustCompile(`^Docs:`), Type: "docs"},
+	}
+
+	tests := []struct {
+		name    string
+		commit  git.Commit
+		want    commitparser.AnalyzedCommit
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid",
+			commit:  git.Commit{Message: "[Feature] foo"},
+			want:    commitparser.AnalyzedCommit{Commit: git.Commit{Message: "[Feature] foo"}, Type: "feat", Description: "foo"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "keeps types that are not released",
+			commit:  git.Commit{Message: "Docs: foo"},
+			want:    commitparser.AnalyzedCommit{Commit: git.Commit{Message: "Docs: foo"}, Type: "docs", Description: "foo"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "no matching rule",
+			commit:  git.Commit{Message: "feat: foo"},
+			wantErr: assert.Error,
+		},
+		{
+			name:   "changelog skip trailer",
+			commit: git.Commit{Message: "[Feature] internal only\n\nChangelog: skip"},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, commitparser.ErrSkipped)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewParser(slog.Default(), rules).Parse(tt.commit)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
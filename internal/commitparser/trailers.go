@@ -0,0 +1,80 @@
+package commitparser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Trailers in the commit message footer that change how the commit is shown in the changelog. Trailers returns the
+// keys in lower case.
+const (
+	// TrailerReleaseNote replaces the description of the commit in the changelog.
+	TrailerReleaseNote = "release-note"
+	// TrailerChangelog with the value TrailerChangelogSkip removes the commit from the changelog and version
+	// calculation.
+	TrailerChangelog     = "changelog"
+	TrailerChangelogSkip = "skip"
+	// TrailerCoAuthoredBy lists additional authors of the commit.
+	TrailerCoAuthoredBy = "co-authored-by"
+	// TrailerBreakingChange marks the commit as a breaking change.
+	TrailerBreakingChange = "breaking change"
+)
+
+var trailerRegex = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][A-Za-z0-9-]*): ?(.*)$`)
+
+// Trailers returns the trailers from the last paragraph of the commit message, e.g. "Changelog: skip". The keys are
+// returned in lower case. The last paragraph is only used if every line in it is a trailer.
+func Trailers(message string) map[string][]string {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		// The only paragraph is the subject.
+		return nil
+	}
+
+	trailers := map[string][]string{}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		match := trailerRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			return nil
+		}
+
+		key := strings.ReplaceAll(strings.ToLower(match[1]), "breaking-change", TrailerBreakingChange)
+		trailers[key] = append(trailers[key], strings.TrimSpace(match[2]))
+	}
+
+	return trailers
+}
+
+// SkipChangelog returns true if the trailers contain the changelog skip trailer.
+func SkipChangelog(trailers map[string][]string) bool {
+	return slices.ContainsFunc(trailers[TrailerChangelog], func(value string) bool {
+		return strings.EqualFold(strings.TrimSpace(value), TrailerChangelogSkip)
+	})
+}
+
+// ReleaseNote returns the value of the last release note trailer, or the description if there is none.
+func ReleaseNote(trailers map[string][]string, description string) string {
+	if releaseNotes := trailers[TrailerReleaseNote]; len(releaseNotes) > 0 {
+		return strings.TrimSpace(releaseNotes[len(releaseNotes)-1])
+	}
+	return description
+}
+
+// CoAuthors returns the names from Co-authored-by trailers in the form "Name <email>".
+func CoAuthors(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		name, _, _ := strings.Cut(value, "<")
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
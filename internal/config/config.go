@@ -0,0 +1,587 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/conventionalcommits"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/gitmoji"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/regexmapping"
+	"github.com/apricote/releaser-pleaser/internal/notify"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/updater"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+const (
+	DefaultPath = ".releaser-pleaser.yaml"
+)
+
+// Config is the repository-level configuration of releaser-pleaser. It is read from a YAML file, usually
+// DefaultPath in the root of the repository.
+type Config struct {
+	// ReleaseType selects well-known files of an ecosystem that are updated with the version, see
+	// updater.ReleaseTypes. Only used if no Packages are configured.
+	ReleaseType string `yaml:"release-type"`
+	// ExtraFiles lists files that are scanned for version references. Only used if no Packages are configured.
+	ExtraFiles []ExtraFile `yaml:"extra-files"`
+	// TagPrefix is prepended to the version number to get the tag name, e.g. "release-" for "release-1.2.3". An empty
+	// prefix results in bare version tags. Defaults to "v" if not set. Only used if no Packages are configured.
+	TagPrefix *string `yaml:"tag-prefix"`
+
+	// ReleaseCommands are run in the worktree of the release branch before the release commit is created, e.g. to
+	// regenerate docs. Their changes are part of the release commit. Only used if no Packages are configured.
+	ReleaseCommands []string `yaml:"release-commands"`
+	// Assets are built and attached to every release. Only used if no Packages are configured.
+	Assets Assets `yaml:"assets"`
+
+	Changelog  Changelog  `yaml:"changelog"`
+	Versioning Versioning `yaml:"versioning"`
+	Commits    Commits    `yaml:"commits"`
+
+	// MaintenanceBranches are patterns (see path.Match) of branches that are used to release patches for older
+	// versions, e.g. "release-*".
+	MaintenanceBranches []string `yaml:"maintenance-branches"`
+
+	// Packages that are released independently of each other. If empty, the whole repository is treated as a single
+	// package.
+	Packages []Package `yaml:"packages"`
+
+	// PullRequestTitleTemplate is a Go template of the release pull request title, see releasepr.TitleData for the
+	// available variables. Defaults to releasepr.DefaultTitleTemplate.
+	PullRequestTitleTemplate string `yaml:"pr-title-template"`
+	// ReleaseCommitTemplate is a Go template of the release commit message, with the same variables as the
+	// PullRequestTitleTemplate. Defaults to releasepr.DefaultTitleTemplate.
+	ReleaseCommitTemplate string `yaml:"release-commit-template"`
+
+	// Notifications are sent after every release.
+	Notifications []Notification `yaml:"notifications"`
+}
+
+// Package is a part of the repository that is versioned and released on its own.
+type Package struct {
+	// Name is used to identify the package in the release pull request and its branch name. Required.
+	Name string `yaml:"name"`
+	// Path is the directory of the package relative to the repository root. Only commits touching files in this
+	// directory are considered for the release of the package. Required.
+	Path string `yaml:"path"`
+	// TagPrefix is prepended to the version number of the package, e.g. "api/v" results in "api/v1.2.3". Required.
+	TagPrefix string `yaml:"tag-prefix"`
+	// ReleaseType selects well-known files of an ecosystem below Path that are updated with the version.
+	ReleaseType string `yaml:"release-type"`
+	// ExtraFiles lists files relative to the repository root that are scanned for version references.
+	ExtraFiles []ExtraFile `yaml:"extra-files"`
+	// ReleaseCommands are run in the worktree of the release branch before the release commit is created.
+	ReleaseCommands []string `yaml:"release-commands"`
+	// Assets are built and attached to every release of the package.
+	Assets Assets `yaml:"assets"`
+}
+
+// Assets are uploaded to the release after it was created.
+type Assets struct {
+	// Commands are run in the working directory before the release is created, e.g. to build binaries.
+	Commands []string `yaml:"commands"`
+	// Files are glob patterns (see filepath.Match) of the files that are uploaded.
+	Files []string `yaml:"files"`
+}
+
+type VersioningScheme string
+
+const (
+	VersioningSchemeSemVer VersioningScheme = "semver"
+	VersioningSchemeCalVer VersioningScheme = "calver"
+)
+
+type Versioning struct {
+	// Scheme of the version numbers, defaults to VersioningSchemeSemVer.
+	Scheme VersioningScheme `yaml:"scheme"`
+	// Format of CalVer versions, defaults to versioning.DefaultCalVerFormat. Only used with VersioningSchemeCalVer.
+	Format string `yaml:"format"`
+
+	// BumpMinorPreMajor bumps the minor instead of the major version for breaking changes before 1.0.0. Only used
+	// with VersioningSchemeSemVer.
+	BumpMinorPreMajor bool `yaml:"bump-minor-pre-major"`
+	// BumpPatchForMinorPreMajor bumps the patch instead of the minor version for features before 1.0.0. Only used
+	// with VersioningSchemeSemVer.
+	BumpPatchForMinorPreMajor bool `yaml:"bump-patch-for-minor-pre-major"`
+	// RequireMajorLabel never bumps the major version automatically. Breaking changes only bump the minor version,
+	// unless the label releasepr.LabelAllowMajor is added to the release pull request.
+	RequireMajorLabel bool `yaml:"require-major-label"`
+}
+
+// Strategy returns the versioning.Strategy for the configured scheme.
+func (v Versioning) Strategy() (versioning.Strategy, error) {
+	switch v.Scheme {
+	case VersioningSchemeSemVer, "":
+		return versioning.NewSemVer(versioning.SemVerOptions{
+			BumpMinorPreMajor:         v.BumpMinorPreMajor,
+			BumpPatchForMinorPreMajor: v.BumpPatchForMinorPreMajor,
+		}), nil
+	case VersioningSchemeCalVer:
+		if v.BumpMinorPreMajor || v.BumpPatchForMinorPreMajor {
+			return nil, errors.New("bump-minor-pre-major and bump-patch-for-minor-pre-major can only be used with semver")
+		}
+
+		format := v.Format
+		if format == "" {
+			format = versioning.DefaultCalVerFormat
+		}
+		return versioning.CalVer(format)
+	default:
+		return nil, fmt.Errorf("unknown scheme %q", v.Scheme)
+	}
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeSlack   NotificationType = "slack"
+	NotificationTypeMatrix  NotificationType = "matrix"
+	NotificationTypeWebhook NotificationType = "webhook"
+)
+
+// Notification posts a message to a chat or webhook after a release was created. Secrets are read from environment
+// variables, so they are not committed to the repository.
+type Notification struct {
+	Type NotificationType `yaml:"type"`
+	// URL of the Slack incoming webhook or the generic webhook.
+	URL string `yaml:"url"`
+	// URLEnv is the environment variable with the URL, it takes precedence over URL.
+	URLEnv string `yaml:"url-env"`
+	// Homeserver is the base URL of the Matrix homeserver, e.g. "https://matrix.org".
+	Homeserver string `yaml:"homeserver"`
+	// Room is the ID of the Matrix room, e.g. "!abc:matrix.org".
+	Room string `yaml:"room"`
+	// TokenEnv is the environment variable with the access token of the Matrix user.
+	TokenEnv string `yaml:"token-env"`
+	// Template is a Go template of the message, see notify.MessageData for the available variables. Defaults to
+	// notify.DefaultTemplate.
+	Template string `yaml:"template"`
+}
+
+func (n Notification) validate() error {
+	switch n.Type {
+	case NotificationTypeSlack, NotificationTypeWebhook:
+		if n.URL == "" && n.URLEnv == "" {
+			return errors.New("url or url-env is required")
+		}
+	case NotificationTypeMatrix:
+		if n.Homeserver == "" || n.Room == "" || n.TokenEnv == "" {
+			return errors.New("homeserver, room and token-env are required")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", n.Type)
+	}
+
+	if _, err := notify.ParseTemplate(n.Template); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	return nil
+}
+
+type CommitParserType string
+
+const (
+	CommitParserConventionalCommits CommitParserType = "conventional-commits"
+	CommitParserGitmoji             CommitParserType = "gitmoji"
+	CommitParserRegexMapping        CommitParserType = "regex-mapping"
+)
+
+// Commits configures how the commit messages are mapped to changelog sections and version bumps.
+type Commits struct {
+	// Parser of the commit messages, defaults to CommitParserConventionalCommits.
+	Parser CommitParserType `yaml:"parser"`
+	// Rules map the commit subjects to commit types. Only used with CommitParserRegexMapping.
+	Rules []CommitRule `yaml:"rules"`
+}
+
+// CommitRule maps the commits with a subject matching the Pattern to a commit type, see regexmapping.Rule.
+type CommitRule struct {
+	// Pattern is a regular expression, the capture groups "type", "scope" and "description" are used if present.
+	Pattern string `yaml:"pattern"`
+	// Type of the matched commits, e.g. "feat". Required unless the Pattern has a "type" capture group.
+	Type string `yaml:"type"`
+	// Breaking marks the matched commits as breaking changes.
+	Breaking bool `yaml:"breaking"`
+}
+
+// CommitParser returns the commitparser.CommitParser for the configured parser. Commits of the additionalTypes are
+// kept, even though they do not cause a version bump on their own.
+func (c Commits) CommitParser(logger *slog.Logger, additionalTypes ...string) (commitparser.CommitParser, error) {
+	if len(c.Rules) > 0 && c.Parser != CommitParserRegexMapping {
+		return nil, fmt.Errorf("rules can only be used with the parser %q", CommitParserRegexMapping)
+	}
+
+	switch c.Parser {
+	case CommitParserConventionalCommits, "":
+		return conventionalcommits.NewParser(logger, additionalTypes...), nil
+	case CommitParserGitmoji:
+		return gitmoji.NewParser(logger, additionalTypes...), nil
+	case CommitParserRegexMapping:
+		if len(c.Rules) == 0 {
+			return nil, fmt.Errorf("at least one rule is required for the parser %q", CommitParserRegexMapping)
+		}
+
+		rules := make([]regexmapping.Rule, 0, len(c.Rules))
+		for i, rule := range c.Rules {
+			r, err := regexmapping.NewRule(rule.Pattern, rule.Type, rule.Breaking)
+			if err != nil {
+				return nil, fmt.Errorf("rules[%d]: %w", i, err)
+			}
+			rules = append(rules, r)
+		}
+		return regexmapping.NewParser(logger, rules, additionalTypes...), nil
+	default:
+		return nil, fmt.Errorf("unknown parser %q", c.Parser)
+	}
+}
+
+type Changelog struct {
+	// Sections of the changelog, in order. Commits with a type that has no section are not listed in the changelog.
+	// The special type "breaking" lists all commits with breaking changes.
+	Sections []ChangelogSection `yaml:"sections"`
+	// LinkedIssues adds the issues closed by a pull request to its changelog entries.
+	LinkedIssues bool `yaml:"linked-issues"`
+	// GroupByScope lists the entries of every section grouped by the scope of the commits.
+	GroupByScope bool `yaml:"group-by-scope"`
+	// Sort orders the entries in every section: "merge-time" (default), "pull-request" or "scope".
+	Sort changelog.Order `yaml:"sort"`
+	// ExcludeScopes removes commits with one of these scopes from the changelog, e.g. "deps". They are still
+	// considered for the next version.
+	ExcludeScopes []string `yaml:"exclude-scopes"`
+	// Preamble is added below the header of the changelog file, when the file is created for the first release.
+	Preamble string `yaml:"preamble"`
+	// Authors credits every changelog entry to the author of its pull request, e.g. "by @apricote".
+	Authors bool `yaml:"authors"`
+	// NewContributors lists the users that made their first contribution to the repository in the release.
+	NewContributors bool `yaml:"new-contributors"`
+	// Format of the changelog file: "markdown" (default), "keep-a-changelog" or "json".
+	Format changelog.Format `yaml:"format"`
+	// Dependencies configures how the updates by dependency bots like Renovate and Dependabot are listed.
+	Dependencies ChangelogDependencies `yaml:"dependencies"`
+	// Path of the changelog file, defaults to "CHANGELOG.md", or "CHANGELOG.json" for the format "json".
+	Path string `yaml:"path"`
+	// Files receive the changelog entry of every release in addition to the changelog file.
+	Files []ChangelogFile `yaml:"files"`
+}
+
+// ChangelogFile is an additional file for the changelog entries.
+type ChangelogFile struct {
+	// Path of the file. If it contains a template action like "{{ .Version }}", a new file is written for every
+	// release.
+	Path string `yaml:"path"`
+	// Format of the entry, defaults to the format of the changelog.
+	Format changelog.Format `yaml:"format"`
+	// Template is the path of a custom template for the entry.
+	Template string `yaml:"template"`
+}
+
+// ChangelogDependencies groups the pull requests by dependency bots in a single "Dependencies" section.
+type ChangelogDependencies struct {
+	// Group lists one entry per updated dependency, e.g. "bumped foo from 1.0.0 to 1.1.0".
+	Group bool `yaml:"group"`
+	// Authors of the dependency updates. Together with BranchPrefixes, defaults to the users and branch prefixes
+	// of Renovate and Dependabot.
+	Authors []string `yaml:"authors"`
+	// BranchPrefixes of the pull requests with dependency updates, e.g. "renovate/".
+	BranchPrefixes []string `yaml:"branch-prefixes"`
+}
+
+type ChangelogSection struct {
+	Type  string `yaml:"type"`
+	Title string `yaml:"title"`
+}
+
+type ExtraFileType string
+
+const (
+	// ExtraFileTypeGeneric replaces versions in lines marked with x-releaser-pleaser-version.
+	ExtraFileTypeGeneric ExtraFileType = "generic"
+	// ExtraFileTypeJSON replaces the string value at Key in a JSON file.
+	ExtraFileTypeJSON ExtraFileType = "json"
+	// ExtraFileTypeYAML replaces the scalar value at Key in a YAML file.
+	ExtraFileTypeYAML ExtraFileType = "yaml"
+	// ExtraFileTypeText replaces the whole content of the file.
+	ExtraFileTypeText ExtraFileType = "text"
+)
+
+// ExtraFile is a file that is updated with the new version in the release commit. In the config file it can either
+// be specified as an object or as a plain string, which is the path of a file with ExtraFileTypeGeneric.
+type ExtraFile struct {
+	Path string        `yaml:"path"`
+	Type ExtraFileType `yaml:"type"`
+	// Key is the path to the value in structured files, nested keys are separated by ".".
+	Key string `yaml:"key"`
+}
+
+func (e *ExtraFile) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*e = ExtraFile{Path: value.Value, Type: ExtraFileTypeGeneric}
+		return nil
+	}
+
+	// Alias type to avoid infinite recursion
+	type extraFile ExtraFile
+	var file extraFile
+	if err := value.Decode(&file); err != nil {
+		return err
+	}
+
+	*e = ExtraFile(file)
+	if e.Type == "" {
+		e.Type = ExtraFileTypeGeneric
+	}
+
+	return nil
+}
+
+// Load reads the config file at path. If the file does not exist, an empty Config is returned.
+func Load(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(content)
+}
+
+// Parse decodes and validates the YAML config.
+func Parse(content []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c Config) validate() error {
+	if err := validateReleaseType("release-type", c.ReleaseType); err != nil {
+		return err
+	}
+	if err := validateExtraFiles("extra-files", c.ExtraFiles); err != nil {
+		return err
+	}
+	if err := validateAssets("assets", c.Assets); err != nil {
+		return err
+	}
+
+	if err := c.Changelog.validate(); err != nil {
+		return err
+	}
+
+	if _, err := c.Versioning.Strategy(); err != nil {
+		return fmt.Errorf("versioning: %w", err)
+	}
+
+	if _, err := c.Commits.CommitParser(slog.Default()); err != nil {
+		return fmt.Errorf("commits: %w", err)
+	}
+
+	for i, pattern := range c.MaintenanceBranches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("maintenance-branches[%d]: invalid pattern %q: %w", i, pattern, err)
+		}
+	}
+
+	if err := validateTitleTemplate("pr-title-template", c.PullRequestTitleTemplate); err != nil {
+		return err
+	}
+	if err := validateTitleTemplate("release-commit-template", c.ReleaseCommitTemplate); err != nil {
+		return err
+	}
+
+	for i, notification := range c.Notifications {
+		if err := notification.validate(); err != nil {
+			return fmt.Errorf("notifications[%d]: %w", i, err)
+		}
+	}
+
+	if c.TagPrefix != nil && len(c.Packages) > 0 {
+		return errors.New("tag-prefix: can not be used together with packages, set tag-prefix per package instead")
+	}
+	if (len(c.Assets.Commands) > 0 || len(c.Assets.Files) > 0) && len(c.Packages) > 0 {
+		return errors.New("assets: can not be used together with packages, set assets per package instead")
+	}
+	if len(c.ReleaseCommands) > 0 && len(c.Packages) > 0 {
+		return errors.New("release-commands: can not be used together with packages, set release-commands per package instead")
+	}
+
+	names := make(map[string]bool, len(c.Packages))
+	tagPrefixes := make(map[string]bool, len(c.Packages))
+
+	for i, pkg := range c.Packages {
+		if pkg.Name == "" {
+			return fmt.Errorf("packages[%d]: name is required", i)
+		}
+		if pkg.Path == "" {
+			return fmt.Errorf("packages[%d]: path is required", i)
+		}
+		if pkg.TagPrefix == "" {
+			return fmt.Errorf("packages[%d]: tag-prefix is required", i)
+		}
+
+		if err := validateReleaseType(fmt.Sprintf("packages[%d].release-type", i), pkg.ReleaseType); err != nil {
+			return err
+		}
+		if err := validateExtraFiles(fmt.Sprintf("packages[%d].extra-files", i), pkg.ExtraFiles); err != nil {
+			return err
+		}
+		if err := validateAssets(fmt.Sprintf("packages[%d].assets", i), pkg.Assets); err != nil {
+			return err
+		}
+
+		if names[pkg.Name] {
+			return fmt.Errorf("packages[%d]: duplicate name %q", i, pkg.Name)
+		}
+		names[pkg.Name] = true
+
+		if tagPrefixes[pkg.TagPrefix] {
+			return fmt.Errorf("packages[%d]: duplicate tag-prefix %q", i, pkg.TagPrefix)
+		}
+		tagPrefixes[pkg.TagPrefix] = true
+	}
+
+	return nil
+}
+
+func validateExtraFiles(field string, files []ExtraFile) error {
+	for i, file := range files {
+		if file.Path == "" {
+			return fmt.Errorf("%s[%d]: path is required", field, i)
+		}
+
+		switch file.Type {
+		case ExtraFileTypeGeneric, ExtraFileTypeText:
+		case ExtraFileTypeJSON, ExtraFileTypeYAML:
+			if file.Key == "" {
+				return fmt.Errorf("%s[%d]: key is required for type %s", field, i, file.Type)
+			}
+		default:
+			return fmt.Errorf("%s[%d]: unknown type %q", field, i, file.Type)
+		}
+	}
+
+	return nil
+}
+
+func validateAssets(field string, assets Assets) error {
+	for i, pattern := range assets.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s.files[%d]: invalid pattern %q: %w", field, i, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func validateReleaseType(field, releaseType string) error {
+	if releaseType == "" {
+		return nil
+	}
+
+	if _, ok := updater.ReleaseTypes[releaseType]; !ok {
+		return fmt.Errorf("%s: unknown release type %q", field, releaseType)
+	}
+
+	return nil
+}
+
+// validateTitleTemplate parses the template and renders it with example data, to catch unknown variables before the
+// first release.
+func validateTitleTemplate(field, raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	tpl, err := releasepr.ParseTitleTemplate(field, raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+
+	if _, err = releasepr.RenderTitle(tpl, releasepr.TitleData{Version: "v1.0.0", Branch: "main", Package: "example"}); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+
+	return nil
+}
+
+func (c Changelog) validate() error {
+	if err := validateChangelogFormat(c.Format); err != nil {
+		return fmt.Errorf("changelog.format: %w", err)
+	}
+
+	switch c.Sort {
+	case "", changelog.OrderMergeTime, changelog.OrderPullRequest, changelog.OrderScope:
+	default:
+		return fmt.Errorf("changelog.sort: unknown order %q", c.Sort)
+	}
+
+	for i, file := range c.Files {
+		if file.Path == "" {
+			return fmt.Errorf("changelog.files[%d]: path is required", i)
+		}
+		if _, err := template.New("path").Parse(file.Path); err != nil {
+			return fmt.Errorf("changelog.files[%d]: invalid path template: %w", i, err)
+		}
+		if err := validateChangelogFormat(file.Format); err != nil {
+			return fmt.Errorf("changelog.files[%d].format: %w", i, err)
+		}
+	}
+
+	types := make(map[string]bool, len(c.Sections))
+
+	for i, section := range c.Sections {
+		if section.Type == "" {
+			return fmt.Errorf("changelog.sections[%d]: type is required", i)
+		}
+		if section.Title == "" {
+			return fmt.Errorf("changelog.sections[%d]: title is required", i)
+		}
+
+		if types[section.Type] {
+			return fmt.Errorf("changelog.sections[%d]: duplicate type %q", i, section.Type)
+		}
+		types[section.Type] = true
+	}
+
+	return nil
+}
+
+func validateChangelogFormat(format changelog.Format) error {
+	switch format {
+	case "", changelog.FormatMarkdown, changelog.FormatKeepAChangelog, changelog.FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// IsMaintenanceBranch reports if the branch matches one of the MaintenanceBranches.
+func (c Config) IsMaintenanceBranch(branch string) bool {
+	for _, pattern := range c.MaintenanceBranches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+
+	return false
+}
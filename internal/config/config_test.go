@@ -0,0 +1,601 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+)
+
+func ptr[T any](input T) *T {
+	return &input
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    Config{},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "packages",
+			content: `packages:
+  - name: api
+    path: api
+    tag-prefix: api/v
+    extra-files:
+      - api/version.go
+  - name: web
+    path: web
+    tag-prefix: web/v
+`,
+			want: Config{
+				Packages: []Package{
+					{Name: "api", Path: "api", TagPrefix: "api/v", ExtraFiles: []ExtraFile{{Path: "api/version.go", Type: ExtraFileTypeGeneric}}},
+					{Name: "web", Path: "web", TagPrefix: "web/v"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "extra files",
+			content: `extra-files:
+  - version.txt
+  - path: package.json
+    type: json
+    key: version
+  - path: VERSION
+    type: text
+`,
+			want: Config{
+				ExtraFiles: []ExtraFile{
+					{Path: "version.txt", Type: ExtraFileTypeGeneric},
+					{Path: "package.json", Type: ExtraFileTypeJSON, Key: "version"},
+					{Path: "VERSION", Type: ExtraFileTypeText},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "extra file without key",
+			content: `extra-files:
+  - path: package.json
+    type: json
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "extra file with unknown type",
+			content: `extra-files:
+  - path: package.json
+    type: xml
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "release type",
+			content: `release-type: node
+packages:
+  - name: chart
+    path: deploy/chart
+    tag-prefix: chart-v
+    release-type: helm
+`,
+			want: Config{
+				ReleaseType: "node",
+				Packages: []Package{
+					{Name: "chart", Path: "deploy/chart", TagPrefix: "chart-v", ReleaseType: "helm"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "assets",
+			content: `assets:
+  commands:
+    - make dist
+  files:
+    - dist/*.tar.gz
+`,
+			want: Config{
+				Assets: Assets{
+					Commands: []string{"make dist"},
+					Files:    []string{"dist/*.tar.gz"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid asset pattern",
+			content: `assets:
+  files:
+    - "dist/[.tar.gz"
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "assets with packages",
+			content: `assets:
+  files:
+    - dist/*
+packages:
+  - name: api
+    path: api
+    tag-prefix: api/v
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "release commands",
+			content: `release-commands:
+  - make generate-docs VERSION=$RELEASER_PLEASER_VERSION
+`,
+			want: Config{
+				ReleaseCommands: []string{"make generate-docs VERSION=$RELEASER_PLEASER_VERSION"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "release commands with packages",
+			content: `release-commands:
+  - make generate-docs
+packages:
+  - name: api
+    path: api
+    tag-prefix: api/v
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown release type",
+			content: "release-type: cobol\n",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "changelog sections",
+			content: `changelog:
+  sections:
+    - type: breaking
+      title: Breaking Changes
+    - type: feat
+      title: Features
+`,
+			want: Config{
+				Changelog: Changelog{
+					Sections: []ChangelogSection{
+						{Type: "breaking", Title: "Breaking Changes"},
+						{Type: "feat", Title: "Features"},
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog scopes",
+			content: `changelog:
+  group-by-scope: true
+  exclude-scopes: [deps, internal]
+`,
+			want: Config{
+				Changelog: Changelog{
+					GroupByScope:  true,
+					ExcludeScopes: []string{"deps", "internal"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog dependencies",
+			content: `changelog:
+  dependencies:
+    group: true
+    authors: [deps-bot]
+    branch-prefixes: [deps/]
+`,
+			want: Config{
+				Changelog: Changelog{
+					Dependencies: ChangelogDependencies{
+						Group:          true,
+						Authors:        []string{"deps-bot"},
+						BranchPrefixes: []string{"deps/"},
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog preamble",
+			content: `changelog:
+  preamble: |
+    All notable changes to this project are documented in this file.
+`,
+			want: Config{
+				Changelog: Changelog{
+					Preamble: "All notable changes to this project are documented in this file.\n",
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog authors",
+			content: `changelog:
+  authors: true
+  new-contributors: true
+`,
+			want: Config{
+				Changelog: Changelog{
+					Authors:         true,
+					NewContributors: true,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog format",
+			content: `changelog:
+  format: keep-a-changelog
+`,
+			want: Config{
+				Changelog: Changelog{
+					Format: changelog.FormatKeepAChangelog,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unknown changelog format",
+			content: `changelog:
+  format: asciidoc
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "changelog sort",
+			content: `changelog:
+  sort: pull-request
+`,
+			want: Config{
+				Changelog: Changelog{
+					Sort: changelog.OrderPullRequest,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unknown changelog sort",
+			content: `changelog:
+  sort: random
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "changelog files",
+			content: `changelog:
+  path: docs/CHANGELOG.md
+  files:
+    - path: "docs/content/changelog/{{ .Version }}.md"
+      template: .releaser-pleaser/hugo.md.tpl
+    - path: CHANGELOG.json
+      format: json
+`,
+			want: Config{
+				Changelog: Changelog{
+					Path: "docs/CHANGELOG.md",
+					Files: []ChangelogFile{
+						{Path: "docs/content/changelog/{{ .Version }}.md", Template: ".releaser-pleaser/hugo.md.tpl"},
+						{Path: "CHANGELOG.json", Format: changelog.FormatJSON},
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "changelog file without path",
+			content: `changelog:
+  files:
+    - format: json
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "changelog file with invalid path template",
+			content: `changelog:
+  files:
+    - path: "docs/{{ .Version.md"
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "changelog section without title",
+			content: `changelog:
+  sections:
+    - type: feat
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "calver",
+			content: `versioning:
+  scheme: calver
+  format: YY.0M.MICRO
+`,
+			want:    Config{Versioning: Versioning{Scheme: VersioningSchemeCalVer, Format: "YY.0M.MICRO"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid calver format",
+			content: `versioning:
+  scheme: calver
+  format: YY.0M
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "version bump policy",
+			content: `versioning:
+  bump-minor-pre-major: true
+  bump-patch-for-minor-pre-major: true
+  require-major-label: true
+`,
+			want:    Config{Versioning: Versioning{BumpMinorPreMajor: true, BumpPatchForMinorPreMajor: true, RequireMajorLabel: true}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "notifications",
+			content: `notifications:
+  - type: slack
+    url-env: SLACK_WEBHOOK_URL
+    template: "{{ .Release.TagName }} is out"
+  - type: matrix
+    homeserver: https://matrix.org
+    room: "!abc:matrix.org"
+    token-env: MATRIX_TOKEN
+  - type: webhook
+    url: https://example.com/hook
+`,
+			want: Config{Notifications: []Notification{
+				{Type: NotificationTypeSlack, URLEnv: "SLACK_WEBHOOK_URL", Template: "{{ .Release.TagName }} is out"},
+				{Type: NotificationTypeMatrix, Homeserver: "https://matrix.org", Room: "!abc:matrix.org", TokenEnv: "MATRIX_TOKEN"},
+				{Type: NotificationTypeWebhook, URL: "https://example.com/hook"},
+			}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "notification without url",
+			content: `notifications:
+  - type: slack
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "matrix notification without token",
+			content: `notifications:
+  - type: matrix
+    homeserver: https://matrix.org
+    room: "!abc:matrix.org"
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "notification with invalid template",
+			content: `notifications:
+  - type: webhook
+    url: https://example.com/hook
+    template: "{{ .Release"
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "unknown notification type",
+			content: `notifications:
+  - type: irc
+    url: irc://example.com
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "gitmoji parser",
+			content: `commits:
+  parser: gitmoji
+`,
+			want:    Config{Commits: Commits{Parser: CommitParserGitmoji}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "regex mapping parser",
+			content: `commits:
+  parser: regex-mapping
+  rules:
+    - pattern: '^\[Feature\]'
+      type: feat
+    - pattern: '^(?P<type>\w+):'
+      breaking: true
+`,
+			want: Config{Commits: Commits{Parser: CommitParserRegexMapping, Rules: []CommitRule{
+				{Pattern: `^\[Feature\]`, Type: "feat"},
+				{Pattern: `^(?P<type>\w+):`, Breaking: true},
+			}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "regex mapping parser without rules",
+			content: `commits:
+  parser: regex-mapping
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "regex mapping rule without type",
+			content: `commits:
+  parser: regex-mapping
+  rules:
+    - pattern: '^\[Feature\]'
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "rules with other parser",
+			content: `commits:
+  parser: gitmoji
+  rules:
+    - pattern: '^\[Feature\]'
+      type: feat
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "unknown parser",
+			content: `commits:
+  parser: foo
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "pre major bump policy with calver",
+			content: `versioning:
+  scheme: calver
+  bump-minor-pre-major: true
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown versioning scheme",
+			content: "versioning:\n  scheme: romver\n",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "maintenance branches",
+			content: "maintenance-branches:\n  - release-*\n",
+			want:    Config{MaintenanceBranches: []string{"release-*"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid maintenance branch pattern",
+			content: "maintenance-branches:\n  - release-[\n",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "tag prefix",
+			content: "tag-prefix: release-\n",
+			want:    Config{TagPrefix: ptr("release-")},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty tag prefix",
+			content: "tag-prefix: \"\"\n",
+			want:    Config{TagPrefix: ptr("")},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "tag prefix with packages",
+			content: `tag-prefix: v
+packages:
+  - name: api
+    path: api
+    tag-prefix: api/v
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "package without tag-prefix",
+			content: `packages:
+  - name: api
+    path: api
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "duplicate package name",
+			content: `packages:
+  - name: api
+    path: api
+    tag-prefix: api/v
+  - name: api
+    path: web
+    tag-prefix: web/v
+`,
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name: "title templates",
+			content: `pr-title-template: "chore(release): {{ .Version }}"
+release-commit-template: "chore(release): {{ .Version }} [skip ci]"
+`,
+			want: Config{
+				PullRequestTitleTemplate: "chore(release): {{ .Version }}",
+				ReleaseCommitTemplate:    "chore(release): {{ .Version }} [skip ci]",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid title template",
+			content: "pr-title-template: \"release {{ .Version\"\n",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown variable in commit template",
+			content: "release-commit-template: \"release {{ .Ticket }}\"\n",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid yaml",
+			content: "packages: {",
+			want:    Config{},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.content))
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_IsMaintenanceBranch(t *testing.T) {
+	cfg := Config{MaintenanceBranches: []string{"release-*", "v*.x"}}
+
+	assert.True(t, cfg.IsMaintenanceBranch("release-1.4"))
+	assert.True(t, cfg.IsMaintenanceBranch("v2.x"))
+	assert.False(t, cfg.IsMaintenanceBranch("main"))
+	assert.False(t, Config{}.IsMaintenanceBranch("release-1.4"))
+}
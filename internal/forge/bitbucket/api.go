@@ -0,0 +1,221 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// client is a minimal client for the Bitbucket Cloud REST API 2.0, covering the endpoints required by the forge.
+// See https://developer.atlassian.com/cloud/bitbucket/rest/intro/
+type client struct {
+	http    *http.Client
+	baseURL string
+
+	authenticate func(req *http.Request)
+}
+
+// apiError is returned for all responses with a non-2xx status code.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("bitbucket api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+func isStatus(err error, statusCode int) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// do sends the request to the API. The path is either relative to the base URL or a full URL, as returned in the
+// "next" field of paginated responses. If result is not nil, the response body is decoded into it.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.send(req, result)
+}
+
+// upload sends the content as a multipart form with a single file field to the API.
+func (c *client) upload(ctx context.Context, path, field, filename string, content io.Reader) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(part, content); err != nil {
+		return err
+	}
+	if err = form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path, nil), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return c.send(req, nil)
+}
+
+func (c *client) url(path string, query url.Values) string {
+	u := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		u = c.baseURL + "/" + path
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	return u
+}
+
+func (c *client) send(req *http.Request, result any) error {
+	req.Header.Set("Accept", "application/json")
+	c.authenticate(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &apiError{StatusCode: resp.StatusCode, Message: resp.Status}
+
+		var errResp errorResponse
+		if err = json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error.Message != "" {
+			apiErr.Message = errResp.Error.Message
+		}
+
+		return apiErr
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type page[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// all follows the "next" links of a paginated endpoint and returns the values of all pages.
+func all[T any](ctx context.Context, c *client, path string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("pagelen", strconv.Itoa(PerPageMax))
+
+	results := make([]T, 0)
+	next := path
+
+	for next != "" {
+		var p page[T]
+		if err := c.do(ctx, http.MethodGet, next, query, nil, &p); err != nil {
+			return nil, err
+		}
+
+		results = append(results, p.Values...)
+
+		// The next link already contains all query parameters
+		next = p.Next
+		query = nil
+	}
+
+	return results, nil
+}
+
+type bbCommit struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message,omitempty"`
+	// Author is only set in responses. The user is nil if the email address is not linked to an account.
+	Author *struct {
+		User *bbUser `json:"user"`
+	} `json:"author,omitempty"`
+	// Date is only set in responses.
+	Date *time.Time `json:"date,omitempty"`
+}
+
+type bbTag struct {
+	Name   string   `json:"name"`
+	Target bbCommit `json:"target"`
+}
+
+type bbBranch struct {
+	Name string `json:"name"`
+}
+
+type bbRef struct {
+	Branch bbBranch  `json:"branch"`
+	Commit *bbCommit `json:"commit,omitempty"`
+}
+
+type bbPullRequest struct {
+	ID                int       `json:"id,omitempty"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	State             string    `json:"state,omitempty"`
+	Source            bbRef     `json:"source"`
+	Destination       bbRef     `json:"destination"`
+	MergeCommit       *bbCommit `json:"merge_commit,omitempty"`
+	CloseSourceBranch bool      `json:"close_source_branch"`
+	Author            *bbUser   `json:"author,omitempty"`
+}
+
+type bbUser struct {
+	UUID        string `json:"uuid"`
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+type bbComment struct {
+	ID      int    `json:"id"`
+	Deleted bool   `json:"deleted"`
+	User    bbUser `json:"user"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+}
+
+type bbRepositoryPermission struct {
+	Permission string `json:"permission"`
+	User       bbUser `json:"user"`
+}
@@ -0,0 +1,688 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/telemetry"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+const (
+	DefaultAPIURL = "https://api.bitbucket.org/2.0"
+	PerPageMax    = 100
+
+	PRStateOpen   = "OPEN"
+	PRStateMerged = "MERGED"
+
+	EnvAccessToken = "BITBUCKET_TOKEN" // nolint:gosec // Not actually a hardcoded credential
+	EnvUsername    = "BITBUCKET_USERNAME"
+	EnvAppPassword = "BITBUCKET_APP_PASSWORD" // nolint:gosec // Not actually a hardcoded credential
+
+	// The following vars are from https://support.atlassian.com/bitbucket-cloud/docs/variables-and-secrets/
+
+	EnvWorkspace = "BITBUCKET_WORKSPACE"
+	EnvRepoSlug  = "BITBUCKET_REPO_SLUG"
+
+	// accessTokenUsername is used for git operations with repository, project or workspace access tokens.
+	accessTokenUsername = "x-token-auth"
+)
+
+var (
+	// Bitbucket Cloud does not support labels on pull requests. Instead, the labels are stored in a hidden comment at
+	// the end of the pull request description.
+	labelsFormat = "<!-- releaser-pleaser-labels: %s -->"
+	labelsRegex  = regexp.MustCompile(`\n*<!-- releaser-pleaser-labels: (.*?) -->\s*`)
+
+	// pullRequestReferenceRegexes match the pull request number in the subjects of merge commits and squashed commits.
+	pullRequestReferenceRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\(pull request #(\d+)\)$`),
+	}
+)
+
+var (
+	_ forge.Forge                = &Bitbucket{}
+	_ forge.ReleaseFinder        = &Bitbucket{}
+	_ forge.ReleaseBranchCleaner = &Bitbucket{}
+)
+
+type Bitbucket struct {
+	options *Options
+
+	client *client
+	log    *slog.Logger
+}
+
+func (b *Bitbucket) RepoURL() string {
+	return fmt.Sprintf("https://bitbucket.org/%s/%s", b.options.Workspace, b.options.RepoSlug)
+}
+
+func (b *Bitbucket) CloneURL() string {
+	return fmt.Sprintf("https://bitbucket.org/%s/%s.git", b.options.Workspace, b.options.RepoSlug)
+}
+
+// ReleaseURL links to the source at the tag, as Bitbucket Cloud has no releases.
+func (b *Bitbucket) ReleaseURL(version string) string {
+	return fmt.Sprintf("%s/src/%s", b.RepoURL(), url.PathEscape(version))
+}
+
+func (b *Bitbucket) PullRequestURL(id int) string {
+	return fmt.Sprintf("%s/pull-requests/%d", b.RepoURL(), id)
+}
+
+// CompareURL links to the diff of the tags. Bitbucket expects the newer revision first, separated by a carriage
+// return.
+func (b *Bitbucket) CompareURL(from, to string) string {
+	return fmt.Sprintf("%s/branches/compare/%s%%0D%s", b.RepoURL(), url.PathEscape(to), url.PathEscape(from))
+}
+
+func (b *Bitbucket) CommitURL(hash string) string {
+	return fmt.Sprintf("%s/commits/%s", b.RepoURL(), hash)
+}
+
+func (b *Bitbucket) GitAuth() transport.AuthMethod {
+	if b.options.AccessToken != "" {
+		return &http.BasicAuth{
+			Username: accessTokenUsername,
+			Password: b.options.AccessToken,
+		}
+	}
+
+	return &http.BasicAuth{
+		Username: b.options.Username,
+		Password: b.options.AppPassword,
+	}
+}
+
+func (b *Bitbucket) repoPath(elem ...string) string {
+	return "repositories/" + url.PathEscape(b.options.Workspace) + "/" + url.PathEscape(b.options.RepoSlug) + "/" + strings.Join(elem, "/")
+}
+
+func (b *Bitbucket) LatestTags(ctx context.Context, tagPrefix string, strategy versioning.Strategy) (git.Releases, error) {
+	b.log.DebugContext(ctx, "listing all tags in bitbucket repository")
+
+	tags, err := all[bbTag](ctx, b.client, b.repoPath("refs", "tags"), url.Values{
+		"sort": {"-target.date"},
+		"q":    {fmt.Sprintf("name ~ %q", tagPrefix)},
+	})
+	if err != nil {
+		return git.Releases{}, err
+	}
+
+	releaseTags := make([]git.Tag, 0, len(tags))
+	for _, bbTag := range tags {
+		releaseTags = append(releaseTags, git.Tag{Hash: bbTag.Target.Hash, Name: bbTag.Name})
+	}
+
+	return forge.LatestReleases(ctx, b.log, releaseTags, tagPrefix, strategy, b.tagReachable)
+}
+
+// tagReachable checks if the tagged commit is part of the history of the base branch. Tags created on other
+// branches, e.g. maintenance branches for older versions, are not relevant for the next release.
+func (b *Bitbucket) tagReachable(ctx context.Context, tag *git.Tag) (bool, error) {
+	var mergeBase bbCommit
+	err := b.client.do(ctx, nethttp.MethodGet, b.repoPath("merge-base", url.PathEscape(tag.Hash+".."+b.options.BaseBranch)), nil, nil, &mergeBase)
+	if err != nil {
+		return false, fmt.Errorf("failed to get merge base of tag %s and base branch: %w", tag.Name, err)
+	}
+
+	return mergeBase.Hash == tag.Hash, nil
+}
+
+func (b *Bitbucket) CommitsSince(ctx context.Context, tag *git.Tag, path string) ([]git.Commit, error) {
+	head := b.options.BaseBranch
+	log := b.log.With("head", head)
+
+	query := url.Values{"include": {head}}
+	if tag != nil {
+		log = log.With("base", tag.Hash)
+		query.Set("exclude", tag.Hash)
+	}
+	if path != "" {
+		log = log.With("path", path)
+		query.Set("path", path)
+	}
+	log.DebugContext(ctx, "listing commits")
+
+	bbCommits, err := all[bbCommit](ctx, b.client, b.repoPath("commits"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseCommits := make([]string, 0, len(bbCommits))
+	for _, bbCommit := range bbCommits {
+		releaseCommits = append(releaseCommits, bbCommit.Hash)
+	}
+
+	commits := make([]git.Commit, 0, len(bbCommits))
+	for _, bbCommit := range bbCommits {
+		commit := git.Commit{
+			Hash:    bbCommit.Hash,
+			Message: bbCommit.Message,
+		}
+		if bbCommit.Author != nil && bbCommit.Author.User != nil {
+			commit.AuthorLogin = bbCommit.Author.User.Nickname
+		}
+		if bbCommit.Date != nil {
+			commit.Date = *bbCommit.Date
+		}
+		commits = append(commits, commit)
+	}
+
+	err = forge.ForEach(ctx, len(commits), b.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+		var err error
+		commits[i].PullRequest, err = b.prForCommit(ctx, commits[i], releaseCommits)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for commit pull request: %w", err)
+	}
+
+	return commits, nil
+}
+
+func (b *Bitbucket) prForCommit(ctx context.Context, commit git.Commit, releaseCommits []string) (*git.PullRequest, error) {
+	// Like on GitLab, we naively look up the associated PR for each commit. This requires len(commits) requests.
+
+	b.log.DebugContext(ctx, "fetching pull requests associated with commit", "commit.hash", commit.Hash)
+
+	associatedPRs, err := all[bbPullRequest](ctx, b.client, b.repoPath("commit", commit.Hash, "pullrequests"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedPRs := make([]forge.MergedPullRequest, 0, len(associatedPRs))
+	for _, pr := range associatedPRs {
+		mergedPR := forge.MergedPullRequest{
+			Merged:     pr.State == PRStateMerged,
+			BaseBranch: pr.Destination.Branch.Name,
+		}
+		// Bitbucket returns abbreviated hashes for the merge commit.
+		if pr.MergeCommit != nil {
+			mergedPR.MergeCommits = []string{pr.MergeCommit.Hash}
+		}
+		mergedPRs = append(mergedPRs, mergedPR)
+	}
+
+	if i := forge.MatchPullRequest(commit.Hash, b.options.BaseBranch, releaseCommits, mergedPRs); i >= 0 {
+		return bitbucketPRToPullRequest(&associatedPRs[i]), nil
+	}
+
+	return b.prForCommitMessage(ctx, commit)
+}
+
+// prForCommitMessage looks up the pull request referenced in the commit message, e.g. "Merged in fix (pull request
+// #123)".
+func (b *Bitbucket) prForCommitMessage(ctx context.Context, commit git.Commit) (*git.PullRequest, error) {
+	id := forge.PullRequestReference(commit.Message, pullRequestReferenceRegexes...)
+	if id == 0 {
+		return nil, nil
+	}
+
+	b.log.DebugContext(ctx, "fetching pull request referenced in commit message", "commit.hash", commit.Hash, "pr.id", id)
+
+	var pr bbPullRequest
+	err := b.client.do(ctx, nethttp.MethodGet, b.repoPath("pullrequests", fmt.Sprint(id)), nil, nil, &pr)
+	if err != nil {
+		if isStatus(err, nethttp.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if pr.State != PRStateMerged {
+		return nil, nil
+	}
+
+	return bitbucketPRToPullRequest(&pr), nil
+}
+
+// EnsureLabelsExist does nothing, as Bitbucket Cloud does not support labels.
+func (b *Bitbucket) EnsureLabelsExist(_ context.Context, _ []releasepr.Label) error {
+	return nil
+}
+
+func (b *Bitbucket) PullRequestForBranch(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
+	prs, err := all[bbPullRequest](ctx, b.client, b.repoPath("pullrequests"), url.Values{
+		"state": {PRStateOpen},
+		"q":     {fmt.Sprintf("source.branch.name = %q AND destination.branch.name = %q", branch, b.options.BaseBranch)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prs) >= 1 {
+		return bitbucketPRToReleasePullRequest(&prs[0]), nil
+	}
+
+	return nil, nil
+}
+
+func (b *Bitbucket) PullRequestComments(ctx context.Context, pr *releasepr.ReleasePullRequest) ([]string, error) {
+	bbComments, err := all[bbComment](ctx, b.client, b.repoPath("pullrequests", fmt.Sprint(pr.ID), "comments"), url.Values{
+		"sort": {"created_on"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the permission of every author, to avoid looking them up for every comment
+	canWrite := map[string]bool{}
+
+	comments := make([]string, 0, len(bbComments))
+	for _, comment := range bbComments {
+		if comment.Deleted {
+			continue
+		}
+
+		allowed, ok := canWrite[comment.User.UUID]
+		if !ok {
+			allowed, err = b.userCanWrite(ctx, comment.User)
+			if err != nil {
+				if isStatus(err, nethttp.StatusForbidden) {
+					b.log.WarnContext(ctx, "unable to check repository permissions of comment authors, ignoring all comments. The token requires admin permissions for the workspace.")
+					return []string{}, nil
+				}
+				return nil, err
+			}
+			canWrite[comment.User.UUID] = allowed
+		}
+
+		if !allowed {
+			b.log.DebugContext(ctx, "ignoring comment by user without write access", "comment.id", comment.ID, "comment.author", comment.User.DisplayName)
+			continue
+		}
+
+		comments = append(comments, comment.Content.Raw)
+	}
+
+	return comments, nil
+}
+
+func (b *Bitbucket) userCanWrite(ctx context.Context, user bbUser) (bool, error) {
+	permissions, err := all[bbRepositoryPermission](ctx, b.client,
+		"workspaces/"+url.PathEscape(b.options.Workspace)+"/permissions/repositories/"+url.PathEscape(b.options.RepoSlug),
+		url.Values{"q": {fmt.Sprintf("user.uuid = %q", user.UUID)}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(permissions, func(permission bbRepositoryPermission) bool {
+		return permission.Permission == "write" || permission.Permission == "admin"
+	}), nil
+}
+
+func (b *Bitbucket) CreatePullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
+	var bbPR bbPullRequest
+	err := b.client.do(ctx, nethttp.MethodPost, b.repoPath("pullrequests"), nil, bbPullRequest{
+		Title:       pr.Title,
+		Description: descriptionWithLabels(pr.Description, pr.Labels),
+		Source:      bbRef{Branch: bbBranch{Name: pr.Head}},
+		Destination: bbRef{Branch: bbBranch{Name: b.options.BaseBranch}},
+	}, &bbPR)
+	if err != nil {
+		return err
+	}
+
+	pr.ID = bbPR.ID
+
+	return nil
+}
+
+func (b *Bitbucket) UpdatePullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
+	return b.client.do(ctx, nethttp.MethodPut, b.repoPath("pullrequests", fmt.Sprint(pr.ID)), nil, map[string]string{
+		"title":       pr.Title,
+		"description": descriptionWithLabels(pr.Description, pr.Labels),
+	}, nil)
+}
+
+func (b *Bitbucket) SetPullRequestLabels(ctx context.Context, pr *releasepr.ReleasePullRequest, remove, add []releasepr.Label) error {
+	labels := slices.DeleteFunc(slices.Clone(pr.Labels), func(label releasepr.Label) bool {
+		return slices.Contains(remove, label)
+	})
+	for _, label := range add {
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+
+	if pr.ReleaseCommit != nil {
+		// Merged pull requests can not be edited anymore. PendingReleases checks if the tag exists instead.
+		b.log.DebugContext(ctx, "pull request is merged, skipping label update", "pr.id", pr.ID)
+		pr.Labels = labels
+		return nil
+	}
+
+	err := b.client.do(ctx, nethttp.MethodPut, b.repoPath("pullrequests", fmt.Sprint(pr.ID)), nil, map[string]string{
+		"title":       pr.Title,
+		"description": descriptionWithLabels(pr.Description, labels),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	pr.Labels = labels
+
+	return nil
+}
+
+func (b *Bitbucket) ClosePullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
+	return b.client.do(ctx, nethttp.MethodPost, b.repoPath("pullrequests", fmt.Sprint(pr.ID), "decline"), nil, nil, nil)
+}
+
+func (b *Bitbucket) PendingReleases(ctx context.Context, pendingLabel releasepr.Label) ([]*releasepr.ReleasePullRequest, error) {
+	bbPRs, err := all[bbPullRequest](ctx, b.client, b.repoPath("pullrequests"), url.Values{
+		"state": {PRStateMerged},
+		"q":     {fmt.Sprintf("destination.branch.name = %q AND description ~ %q", b.options.BaseBranch, pendingLabel.Name)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]*releasepr.ReleasePullRequest, 0, len(bbPRs))
+
+	for _, bbPR := range bbPRs {
+		pr := bitbucketPRToReleasePullRequest(&bbPR)
+		if !slices.Contains(pr.Labels, pendingLabel) {
+			continue
+		}
+
+		// The labels of merged pull requests can not be updated, so we check if the release was already tagged.
+		version, err := pr.Version()
+		if err != nil {
+			b.log.WarnContext(ctx, "unable to parse version from pull request, skipping", "pr.id", pr.ID, "pr.title", pr.Title)
+			continue
+		}
+		tagged, err := b.tagExists(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+		if tagged {
+			continue
+		}
+
+		if pr.ReleaseCommit != nil {
+			// The merge commit hash is abbreviated, but we need the full hash to create the tag.
+			var commit bbCommit
+			err = b.client.do(ctx, nethttp.MethodGet, b.repoPath("commit", pr.ReleaseCommit.Hash), nil, nil, &commit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get merge commit of pull request %d: %w", pr.ID, err)
+			}
+			pr.ReleaseCommit.Hash = commit.Hash
+		}
+
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
+
+func (b *Bitbucket) OpenPullRequests(ctx context.Context, headPrefix string) ([]*releasepr.ReleasePullRequest, error) {
+	bbPRs, err := all[bbPullRequest](ctx, b.client, b.repoPath("pullrequests"), url.Values{
+		"state": {PRStateOpen},
+		"q":     {fmt.Sprintf("source.branch.name ~ %q", headPrefix)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]*releasepr.ReleasePullRequest, 0, len(bbPRs))
+	for _, bbPR := range bbPRs {
+		// The ~ operator matches anywhere in the name
+		if !strings.HasPrefix(bbPR.Source.Branch.Name, headPrefix) {
+			continue
+		}
+
+		prs = append(prs, bitbucketPRToReleasePullRequest(&bbPR))
+	}
+
+	return prs, nil
+}
+
+func (b *Bitbucket) CommentOnPullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest, comment string) error {
+	return b.client.do(ctx, nethttp.MethodPost, b.repoPath("pullrequests", fmt.Sprint(pr.ID), "comments"), nil, map[string]any{
+		"content": map[string]string{"raw": comment},
+	}, nil)
+}
+
+func (b *Bitbucket) DeleteBranch(ctx context.Context, branch string) error {
+	return b.client.do(ctx, nethttp.MethodDelete, b.repoPath("refs", "branches", url.PathEscape(branch)), nil, nil, nil)
+}
+
+// CreateRelease creates the tag, as Bitbucket Cloud has no releases. The changelog is only available in the
+// changelog file of the repository.
+func (b *Bitbucket) CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, prerelease, _ bool) (forge.Release, error) {
+	release := forge.Release{
+		TagName:    title,
+		URL:        b.ReleaseURL(title),
+		Changelog:  changelog,
+		Prerelease: prerelease,
+	}
+
+	tagged, err := b.tagExists(ctx, title)
+	if err != nil {
+		return forge.Release{}, err
+	}
+	if tagged {
+		// The tag was already pushed, e.g. as a signed tag
+		return release, nil
+	}
+
+	err = b.client.do(ctx, nethttp.MethodPost, b.repoPath("refs", "tags"), nil, bbTag{
+		Name:   title,
+		Target: bbCommit{Hash: commit.Hash},
+	}, nil)
+	if err != nil {
+		return forge.Release{}, err
+	}
+
+	return release, nil
+}
+
+// FindRelease returns a release if the tag exists, as Bitbucket Cloud has no releases.
+func (b *Bitbucket) FindRelease(ctx context.Context, tagName string) (*forge.Release, error) {
+	tagged, err := b.tagExists(ctx, tagName)
+	if err != nil || !tagged {
+		return nil, err
+	}
+
+	return &forge.Release{TagName: tagName, URL: b.ReleaseURL(tagName)}, nil
+}
+
+func (b *Bitbucket) LastMergedPullRequest(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
+	var p page[bbPullRequest]
+	err := b.client.do(ctx, nethttp.MethodGet, b.repoPath("pullrequests"), url.Values{
+		"state":   {PRStateMerged},
+		"q":       {fmt.Sprintf("source.branch.name = %q AND destination.branch.name = %q", branch, b.options.BaseBranch)},
+		"sort":    {"-updated_on"},
+		"pagelen": {"1"},
+	}, nil, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.Values) == 0 {
+		return nil, nil
+	}
+
+	pr := bitbucketPRToReleasePullRequest(&p.Values[0])
+	if pr.ReleaseCommit != nil {
+		// The merge commit hash is abbreviated, but we need the full hash to create the tag.
+		var commit bbCommit
+		err = b.client.do(ctx, nethttp.MethodGet, b.repoPath("commit", pr.ReleaseCommit.Hash), nil, nil, &commit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merge commit of pull request %d: %w", pr.ID, err)
+		}
+		pr.ReleaseCommit.Hash = commit.Hash
+	}
+
+	return pr, nil
+}
+
+// UploadReleaseAsset adds the file to the downloads of the repository, as Bitbucket Cloud has no releases.
+func (b *Bitbucket) UploadReleaseAsset(ctx context.Context, _ forge.Release, name string, file *os.File) error {
+	return b.client.upload(ctx, b.repoPath("downloads"), "files", name, file)
+}
+
+func (b *Bitbucket) tagExists(ctx context.Context, name string) (bool, error) {
+	err := b.client.do(ctx, nethttp.MethodGet, b.repoPath("refs", "tags", url.PathEscape(name)), nil, nil, nil)
+	switch {
+	case err == nil:
+		return true, nil
+	case isStatus(err, nethttp.StatusNotFound):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to check if tag %s exists: %w", name, err)
+	}
+}
+
+func bitbucketPRToPullRequest(pr *bbPullRequest) *git.PullRequest {
+	description, labels := splitLabels(pr.Description)
+
+	var author string
+	if pr.Author != nil {
+		author = pr.Author.Nickname
+	}
+
+	return &git.PullRequest{
+		ID:          pr.ID,
+		Title:       pr.Title,
+		Description: description,
+		Labels:      labels,
+		Author:      author,
+		Branch:      pr.Source.Branch.Name,
+	}
+}
+
+func bitbucketPRToReleasePullRequest(pr *bbPullRequest) *releasepr.ReleasePullRequest {
+	_, labelNames := splitLabels(pr.Description)
+
+	labels := make([]releasepr.Label, 0, len(labelNames))
+	for _, labelName := range labelNames {
+		if i := slices.IndexFunc(releasepr.KnownLabels, func(label releasepr.Label) bool {
+			return label.Name == labelName
+		}); i >= 0 {
+			labels = append(labels, releasepr.KnownLabels[i])
+		}
+	}
+
+	var releaseCommit *git.Commit
+	if pr.MergeCommit != nil && pr.MergeCommit.Hash != "" {
+		releaseCommit = &git.Commit{Hash: pr.MergeCommit.Hash}
+	}
+
+	return &releasepr.ReleasePullRequest{
+		PullRequest: *bitbucketPRToPullRequest(pr),
+		Labels:      labels,
+
+		Head:          pr.Source.Branch.Name,
+		Base:          pr.Destination.Branch.Name,
+		ReleaseCommit: releaseCommit,
+	}
+}
+
+// splitLabels removes the hidden labels comment from the description and returns the label names.
+func splitLabels(description string) (string, []string) {
+	matches := labelsRegex.FindStringSubmatch(description)
+	if matches == nil {
+		return description, nil
+	}
+
+	var labels []string
+	for _, label := range strings.Split(matches[1], ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	return labelsRegex.ReplaceAllString(description, "\n"), labels
+}
+
+func descriptionWithLabels(description string, labels []releasepr.Label) string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+
+	return strings.TrimRight(description, "\n") + "\n\n" + fmt.Sprintf(labelsFormat, strings.Join(names, ", ")) + "\n"
+}
+
+func (b *Options) autodiscover() {
+	// Read settings from Bitbucket Pipelines env vars
+	if accessToken := os.Getenv(EnvAccessToken); accessToken != "" {
+		b.AccessToken = accessToken
+	}
+
+	if username := os.Getenv(EnvUsername); username != "" {
+		b.Username = username
+	}
+
+	if appPassword := os.Getenv(EnvAppPassword); appPassword != "" {
+		b.AppPassword = appPassword
+	}
+
+	if workspace := os.Getenv(EnvWorkspace); workspace != "" {
+		b.Workspace = workspace
+	}
+
+	if repoSlug := os.Getenv(EnvRepoSlug); repoSlug != "" {
+		b.RepoSlug = repoSlug
+	}
+}
+
+type Options struct {
+	forge.Options
+
+	Workspace string
+	RepoSlug  string
+
+	// APIURL defaults to DefaultAPIURL.
+	APIURL string
+
+	// AccessToken is a repository, project or workspace access token. If set, Username and AppPassword are ignored.
+	AccessToken string
+	// Username and AppPassword are used for authentication if no AccessToken is set.
+	Username    string
+	AppPassword string
+}
+
+func New(log *slog.Logger, options *Options) *Bitbucket {
+	log = log.With("forge", "bitbucket")
+	options.autodiscover()
+
+	apiURL := options.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &Bitbucket{
+		options: options,
+
+		client: &client{
+			http:    &nethttp.Client{Transport: telemetry.Transport("bitbucket", nil)},
+			baseURL: strings.TrimSuffix(apiURL, "/"),
+			authenticate: func(req *nethttp.Request) {
+				if options.AccessToken != "" {
+					req.Header.Set("Authorization", "Bearer "+options.AccessToken)
+				} else if options.Username != "" {
+					req.SetBasicAuth(options.Username, options.AppPassword)
+				}
+			},
+		},
+		log: log,
+	}
+}
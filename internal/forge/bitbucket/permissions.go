@@ -0,0 +1,49 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+var _ forge.PermissionChecker = &Bitbucket{}
+
+// CheckPermissions probes the API with read requests and with empty create requests. Bitbucket validates the
+// permissions of the token before the request body, so the empty requests fail with 400 if the permission is granted
+// and nothing is created. The permissions are named like the scopes of access tokens.
+func (b *Bitbucket) CheckPermissions(ctx context.Context) ([]forge.PermissionCheck, error) {
+	page := url.Values{"pagelen": {"1"}}
+
+	return forge.RunProbes(ctx, []forge.Probe{
+		{Action: "read tags", Permission: "repository", Do: b.probe(nethttp.MethodGet, b.repoPath("refs", "tags"), page)},
+		{Action: "read commits", Permission: "repository", Do: b.probe(nethttp.MethodGet, b.repoPath("commits"), page)},
+		{Action: "read pull requests", Permission: "pullrequest", Do: b.probe(nethttp.MethodGet, b.repoPath("pullrequests"), page)},
+		{Action: "push branches", Permission: "repository:write", Do: b.probe(nethttp.MethodPost, b.repoPath("refs", "branches"), nil)},
+		{Action: "create pull requests", Permission: "pullrequest:write", Do: b.probe(nethttp.MethodPost, b.repoPath("pullrequests"), nil)},
+		{Action: "create tags", Permission: "repository:write", Do: b.probe(nethttp.MethodPost, b.repoPath("refs", "tags"), nil)},
+	})
+}
+
+// probe returns a forge.Probe function that sends the request with an empty body for POST requests.
+func (b *Bitbucket) probe(method, path string, query url.Values) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		var body any
+		if method == nethttp.MethodPost {
+			body = struct{}{}
+		}
+
+		err := b.client.do(ctx, method, path, query, body, nil)
+		if err == nil {
+			return nethttp.StatusOK, nil
+		}
+
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			return apiErr.StatusCode, err
+		}
+		return 0, err
+	}
+}
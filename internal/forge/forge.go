@@ -2,11 +2,13 @@ package forge
 
 import (
 	"context"
+	"os"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
 
 	"github.com/apricote/releaser-pleaser/internal/git"
 	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
 )
 
 type Forge interface {
@@ -14,16 +16,22 @@ type Forge interface {
 	CloneURL() string
 	ReleaseURL(version string) string
 	PullRequestURL(id int) string
+	// CompareURL links to the changes between the two tags.
+	CompareURL(from, to string) string
+	// CommitURL links to the commit with the hash.
+	CommitURL(hash string) string
 
 	GitAuth() transport.AuthMethod
 
-	// LatestTags returns the last stable tag created on the main branch. If there is a more recent pre-release tag,
-	// that is also returned. If no tag is found, it returns nil.
-	LatestTags(context.Context) (git.Releases, error)
+	// LatestTags returns the stable tag with the highest version that is reachable from the base branch. If there is a
+	// pre-release tag with a higher version, that is also returned. Only tags starting with the tag prefix and a
+	// version supported by the strategy are considered, see LatestReleases. If no tag is found, it returns nil.
+	LatestTags(ctx context.Context, tagPrefix string, strategy versioning.Strategy) (git.Releases, error)
 
 	// CommitsSince returns all commits to main branch after the Tag. The tag can be `nil`, in which case this
-	// function should return all commits.
-	CommitsSince(context.Context, *git.Tag) ([]git.Commit, error)
+	// function should return all commits. If path is not empty, only commits that touch files in the path are
+	// returned.
+	CommitsSince(ctx context.Context, tag *git.Tag, path string) ([]git.Commit, error)
 
 	// EnsureLabelsExist verifies that all desired labels are available on the repository. If labels are missing, they
 	// are created them.
@@ -33,6 +41,10 @@ type Forge interface {
 	// exists, it returns nil.
 	PullRequestForBranch(context.Context, string) (*releasepr.ReleasePullRequest, error)
 
+	// PullRequestComments returns the text of all comments on the pull/merge request, in chronological order. Only
+	// comments by users with write access to the repository are returned.
+	PullRequestComments(context.Context, *releasepr.ReleasePullRequest) ([]string, error)
+
 	// CreatePullRequest opens a new pull/merge request for the ReleasePullRequest.
 	CreatePullRequest(context.Context, *releasepr.ReleasePullRequest) error
 
@@ -53,10 +65,96 @@ type Forge interface {
 	PendingReleases(context.Context, releasepr.Label) ([]*releasepr.ReleasePullRequest, error)
 
 	// CreateRelease creates a release on the Forge, pointing at the commit with the passed in details.
-	CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, prerelease, latest bool) error
+	CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, prerelease, latest bool) (Release, error)
+
+	// UploadReleaseAsset attaches the file to the release that was previously created with CreateRelease. The name is
+	// used as the file name of the asset.
+	UploadReleaseAsset(ctx context.Context, release Release, name string, file *os.File) error
+}
+
+// Release describes a release that was created on the Forge.
+type Release struct {
+	// ID of the release on the Forge, only set by forges that identify releases by a numeric ID.
+	ID         int64
+	TagName    string
+	URL        string
+	Changelog  string
+	Prerelease bool
+
+	// UploadURL is the endpoint to upload release assets to. It is only set by forges that support it.
+	UploadURL string
+}
+
+// ReleaseAnnouncer publishes a release after it was created, e.g. as a discussion or in a chat.
+type ReleaseAnnouncer interface {
+	AnnounceRelease(context.Context, Release) error
+}
+
+// DescriptionLimiter is implemented by forges that limit the length of pull request descriptions. The changelog in the
+// description of the release pull request is truncated to stay below the limit.
+type DescriptionLimiter interface {
+	// MaxDescriptionLength returns the maximum length of a pull request description in bytes.
+	MaxDescriptionLength() int
+}
+
+// ContributionCounter is implemented by forges that can count the contributions of a user. It is used to find the
+// new contributors of a release.
+type ContributionCounter interface {
+	// MergedPullRequestCount returns the number of merged pull requests of the user in the repository, across all
+	// branches.
+	MergedPullRequestCount(ctx context.Context, login string) (int, error)
+}
+
+// CommitCreator is implemented by forges that can create commits through their API. These commits are attributed to
+// the user of the token and are signed by the forge, so they pass branch protection rules that require verified
+// commits without a signing key.
+type CommitCreator interface {
+	// CreateCommit creates a commit with the changed files on top of the parent commit. The branch is created or
+	// forcefully updated to point at the new commit.
+	CreateCommit(ctx context.Context, branch, parent, message string, files []git.FileChange) (git.Commit, error)
+}
+
+// ReleaseFinder is implemented by forges that can look up existing releases and merged release pull requests. It
+// makes runs retry-safe: releases that a previous run did not finish are completed, and merged release pull requests
+// that lost their pending label are still released.
+type ReleaseFinder interface {
+	// FindRelease returns the release of the tag, or nil if it does not exist. Forges without releases return a
+	// release if the tag exists.
+	FindRelease(ctx context.Context, tagName string) (*Release, error)
+
+	// LastMergedPullRequest returns the most recently merged pull request from the branch into the base branch, or
+	// nil if there is none.
+	LastMergedPullRequest(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error)
+}
+
+// ReleaseBranchCleaner is implemented by forges that can list the open pull requests of all base branches. It is used
+// to close release pull requests that were superseded, e.g. after the base branch was renamed or a package was
+// removed, and to delete release branches without an open pull request.
+type ReleaseBranchCleaner interface {
+	// OpenPullRequests returns all open pull/merge requests from a branch starting with the prefix, independent of
+	// their base branch.
+	OpenPullRequests(ctx context.Context, headPrefix string) ([]*releasepr.ReleasePullRequest, error)
+
+	// CommentOnPullRequest adds the comment to the pull/merge request.
+	CommentOnPullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest, comment string) error
+
+	// DeleteBranch deletes the branch through the API, so it does not require push access to the repository.
+	DeleteBranch(ctx context.Context, branch string) error
 }
 
 type Options struct {
 	Repository string
 	BaseBranch string
+
+	// Concurrency is the number of requests that are made at the same time to look up the pull requests of commits.
+	// Defaults to DefaultConcurrency.
+	Concurrency int
+}
+
+// ConcurrencyOrDefault returns Concurrency, or DefaultConcurrency if it is not set.
+func (o Options) ConcurrencyOrDefault() int {
+	if o.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return o.Concurrency
 }
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+// DiscussionAnnouncer creates a discussion for every release, similar to the option "Create a discussion for this
+// release" in the GitHub UI.
+type DiscussionAnnouncer struct {
+	github   *GitHub
+	category string
+}
+
+var _ forge.ReleaseAnnouncer = &DiscussionAnnouncer{}
+
+// DiscussionAnnouncer returns a forge.ReleaseAnnouncer that creates discussions in the category. The category is
+// matched by name or slug.
+func (g *GitHub) DiscussionAnnouncer(category string) *DiscussionAnnouncer {
+	return &DiscussionAnnouncer{github: g, category: category}
+}
+
+type graphQLDiscussionCategoriesData struct {
+	Repository struct {
+		ID                   string `json:"id"`
+		DiscussionCategories struct {
+			Nodes []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+				Slug string `json:"slug"`
+			} `json:"nodes"`
+		} `json:"discussionCategories"`
+	} `json:"repository"`
+}
+
+type graphQLCreateDiscussionData struct {
+	CreateDiscussion struct {
+		Discussion struct {
+			URL string `json:"url"`
+		} `json:"discussion"`
+	} `json:"createDiscussion"`
+}
+
+const (
+	discussionCategoriesQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`
+
+	createDiscussionMutation = `mutation($input: CreateDiscussionInput!) {
+  createDiscussion(input: $input) { discussion { url } }
+}`
+)
+
+func (a *DiscussionAnnouncer) AnnounceRelease(ctx context.Context, release forge.Release) error {
+	g := a.github
+
+	var categories graphQLDiscussionCategoriesData
+	err := g.graphQL(ctx, discussionCategoriesQuery, map[string]any{
+		"owner": g.options.Owner,
+		"name":  g.options.Repo,
+	}, &categories)
+	if err != nil {
+		return fmt.Errorf("failed to list discussion categories: %w", err)
+	}
+
+	categoryID := ""
+	for _, category := range categories.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(category.Name, a.category) || category.Slug == a.category {
+			categoryID = category.ID
+			break
+		}
+	}
+	if categoryID == "" {
+		return fmt.Errorf("discussion category %q does not exist", a.category)
+	}
+
+	var discussion graphQLCreateDiscussionData
+	err = g.graphQL(ctx, createDiscussionMutation, map[string]any{
+		"input": map[string]any{
+			"repositoryId": categories.Repository.ID,
+			"categoryId":   categoryID,
+			"title":        release.TagName,
+			"body":         fmt.Sprintf("%s\n\n---\n\n[Release %s](%s)\n", strings.TrimSpace(release.Changelog), release.TagName, release.URL),
+		},
+	}, &discussion)
+	if err != nil {
+		return fmt.Errorf("failed to create discussion: %w", err)
+	}
+
+	g.log.InfoContext(ctx, "created discussion for release", "release.tag", release.TagName, "discussion.url", discussion.CreateDiscussion.Discussion.URL)
+
+	return nil
+}
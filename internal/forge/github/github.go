@@ -2,14 +2,16 @@ package github
 
 import (
 	"context"
-	"errors"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
+	nethttp "net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"slices"
 	"strings"
 
-	"github.com/blang/semver/v4"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/google/go-github/v66/github"
@@ -18,6 +20,8 @@ import (
 	"github.com/apricote/releaser-pleaser/internal/git"
 	"github.com/apricote/releaser-pleaser/internal/pointer"
 	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/telemetry"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
 )
 
 const (
@@ -27,31 +31,83 @@ const (
 	EnvAPIToken   = "GITHUB_TOKEN" // nolint:gosec // Not actually a hardcoded credential
 	EnvUsername   = "GITHUB_USER"
 	EnvRepository = "GITHUB_REPOSITORY"
+	EnvAPIURL     = "GITHUB_API_URL"
+	EnvServerURL  = "GITHUB_SERVER_URL"
+
+	DefaultAPIURL    = "https://api.github.com/"
+	DefaultServerURL = "https://github.com"
+
+	// MaxDescriptionLength is the maximum length of the body of a pull request.
+	MaxDescriptionLength = 65536
+
+	// Set by GitHub Actions, see https://docs.github.com/en/actions/writing-workflows/choosing-what-your-workflow-does/store-information-in-variables#default-environment-variables
+	EnvActions    = "GITHUB_ACTIONS"
+	EnvActor      = "GITHUB_ACTOR"
+	EnvRef        = "GITHUB_REF"
+	EnvOutput     = "GITHUB_OUTPUT"
+	EnvInputToken = "INPUT_TOKEN" // nolint:gosec // Not actually a hardcoded credential
 )
 
-var _ forge.Forge = &GitHub{}
+var (
+	// pullRequestReferenceRegexes match the pull request number in the subjects of squashed commits and merge commits.
+	pullRequestReferenceRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\(#(\d+)\)$`),
+		regexp.MustCompile(`^Merge pull request #(\d+) from `),
+		regexp.MustCompile(`^Merge #(\d+)$`),
+	}
+	// batchMergeRegexes match the lists of pull requests in the subjects of commits that merged multiple pull requests
+	// at once, e.g. "Merge #12 #34" by bors or "Merge pull requests from merge queue (#12, #34)" by merge queues. They
+	// are anchored to the subject of the merge commit, so squashed commits that end in multiple references, e.g.
+	// "fix: foo (#12) (#34)", are not split up.
+	batchMergeRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`^Merge((?: #\d+)+)$`),
+		regexp.MustCompile(`^Merge pull requests? .*\((#\d+(?:, #\d+)+)\)$`),
+	}
+)
+
+var (
+	_ forge.Forge                = &GitHub{}
+	_ forge.DescriptionLimiter   = &GitHub{}
+	_ forge.CommitCreator        = &GitHub{}
+	_ forge.ReleaseFinder        = &GitHub{}
+	_ forge.ReleaseBranchCleaner = &GitHub{}
+)
 
 type GitHub struct {
 	options *Options
 
 	client *github.Client
-	log    *slog.Logger
+	// graphQLPath is relative to the base URL of the client.
+	graphQLPath string
+	log         *slog.Logger
 }
 
 func (g *GitHub) RepoURL() string {
-	return fmt.Sprintf("https://github.com/%s/%s", g.options.Owner, g.options.Repo)
+	return fmt.Sprintf("%s/%s/%s", g.options.ServerURL, g.options.Owner, g.options.Repo)
 }
 
 func (g *GitHub) CloneURL() string {
-	return fmt.Sprintf("https://github.com/%s/%s.git", g.options.Owner, g.options.Repo)
+	return fmt.Sprintf("%s/%s/%s.git", g.options.ServerURL, g.options.Owner, g.options.Repo)
 }
 
 func (g *GitHub) ReleaseURL(version string) string {
-	return fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s", g.options.Owner, g.options.Repo, version)
+	return fmt.Sprintf("%s/releases/tag/%s", g.RepoURL(), version)
 }
 
 func (g *GitHub) PullRequestURL(id int) string {
-	return fmt.Sprintf("https://github.com/%s/%s/pull/%d", g.options.Owner, g.options.Repo, id)
+	return fmt.Sprintf("%s/pull/%d", g.RepoURL(), id)
+}
+
+func (g *GitHub) CompareURL(from, to string) string {
+	return fmt.Sprintf("%s/compare/%s...%s", g.RepoURL(), from, to)
+}
+
+func (g *GitHub) CommitURL(hash string) string {
+	return fmt.Sprintf("%s/commit/%s", g.RepoURL(), hash)
+}
+
+func (g *GitHub) MaxDescriptionLength() int {
+	return MaxDescriptionLength
 }
 
 func (g *GitHub) GitAuth() transport.AuthMethod {
@@ -61,7 +117,7 @@ func (g *GitHub) GitAuth() transport.AuthMethod {
 	}
 }
 
-func (g *GitHub) LatestTags(ctx context.Context) (git.Releases, error) {
+func (g *GitHub) LatestTags(ctx context.Context, tagPrefix string, strategy versioning.Strategy) (git.Releases, error) {
 	g.log.DebugContext(ctx, "listing all tags in github repository")
 
 	tags, err := all(func(listOptions github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
@@ -74,40 +130,33 @@ func (g *GitHub) LatestTags(ctx context.Context) (git.Releases, error) {
 		return git.Releases{}, err
 	}
 
-	var releases git.Releases
-
+	releaseTags := make([]git.Tag, 0, len(tags))
 	for _, ghTag := range tags {
-		tag := &git.Tag{
-			Hash: ghTag.GetCommit().GetSHA(),
-			Name: ghTag.GetName(),
-		}
+		releaseTags = append(releaseTags, git.Tag{Hash: ghTag.GetCommit().GetSHA(), Name: ghTag.GetName()})
+	}
 
-		version, err := semver.Parse(strings.TrimPrefix(tag.Name, "v"))
-		if err != nil {
-			g.log.WarnContext(
-				ctx, "unable to parse tag as semver, skipping",
-				"tag.name", tag.Name,
-				"tag.hash", tag.Hash,
-				"error", err,
-			)
-			continue
-		}
+	return forge.LatestReleases(ctx, g.log, releaseTags, tagPrefix, strategy, g.tagReachable)
+}
 
-		if releases.Latest == nil {
-			releases.Latest = tag
-		}
-		if len(version.Pre) == 0 {
-			// Stable version tag
-			// We return once we have found the latest stable tag, not needed to look at every single tag.
-			releases.Stable = tag
-			break
-		}
+// tagReachable checks if the tagged commit is part of the history of the base branch. Tags created on other
+// branches, e.g. maintenance branches for older versions, are not relevant for the next release.
+func (g *GitHub) tagReachable(ctx context.Context, tag *git.Tag) (bool, error) {
+	comparison, _, err := g.client.Repositories.CompareCommits(
+		ctx, g.options.Owner, g.options.Repo,
+		tag.Hash, g.options.BaseBranch, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return false, fmt.Errorf("failed to compare tag %s with base branch: %w", tag.Name, err)
 	}
 
-	return releases, nil
+	switch comparison.GetStatus() {
+	case "ahead", "identical":
+		return true, nil
+	default:
+		return false, nil
+	}
 }
 
-func (g *GitHub) CommitsSince(ctx context.Context, tag *git.Tag) ([]git.Commit, error) {
+func (g *GitHub) CommitsSince(ctx context.Context, tag *git.Tag, path string) ([]git.Commit, error) {
 	var repositoryCommits []*github.RepositoryCommit
 	var err error
 	if tag != nil {
@@ -120,21 +169,151 @@ func (g *GitHub) CommitsSince(ctx context.Context, tag *git.Tag) ([]git.Commit,
 		return nil, err
 	}
 
+	releaseCommits := make([]string, 0, len(repositoryCommits))
+	for _, ghCommit := range repositoryCommits {
+		releaseCommits = append(releaseCommits, ghCommit.GetSHA())
+	}
+
+	if path != "" {
+		repositoryCommits, err = g.filterCommitsByPath(ctx, repositoryCommits, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var commits = make([]git.Commit, 0, len(repositoryCommits))
 	for _, ghCommit := range repositoryCommits {
-		commit := git.Commit{
-			Hash:    ghCommit.GetSHA(),
-			Message: ghCommit.GetCommit().GetMessage(),
+		commits = append(commits, git.Commit{
+			Hash:        ghCommit.GetSHA(),
+			Message:     ghCommit.GetCommit().GetMessage(),
+			AuthorLogin: ghCommit.GetAuthor().GetLogin(),
+			Date:        ghCommit.GetCommit().GetCommitter().GetDate().Time,
+		})
+	}
+
+	err = g.setPullRequests(ctx, commits, releaseCommits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for commit pull request: %w", err)
+	}
+
+	commits, err = g.expandBatchMerges(ctx, commits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pull requests of batch merge: %w", err)
+	}
+
+	return commits, nil
+}
+
+// expandBatchMerges replaces commits that merged multiple pull requests at once, e.g. by a merge queue or bors, with
+// one commit for every referenced pull request. The merge commit is not the merge commit of any of the pull requests,
+// so they can not be found through the commit. The commits use the title of the pull request as message. Pull requests
+// that are already associated with other commits of the release, e.g. the commits of their branch, are not repeated.
+func (g *GitHub) expandBatchMerges(ctx context.Context, commits []git.Commit) ([]git.Commit, error) {
+	associated := make(map[int]bool)
+	for _, commit := range commits {
+		if commit.PullRequest != nil && forge.PullRequestReferences(commit.Message, batchMergeRegexes...) == nil {
+			associated[commit.PullRequest.ID] = true
+		}
+	}
+
+	expanded := make([]git.Commit, 0, len(commits))
+	for _, commit := range commits {
+		ids := forge.PullRequestReferences(commit.Message, batchMergeRegexes...)
+		if ids == nil {
+			expanded = append(expanded, commit)
+			continue
+		}
+
+		ids = slices.DeleteFunc(ids, func(id int) bool { return associated[id] })
+		if len(ids) == 0 {
+			// All pull requests are already listed through their own commits
+			continue
 		}
-		commit.PullRequest, err = g.prForCommit(ctx, commit)
+
+		g.log.DebugContext(ctx, "fetching pull requests of batch merge", "commit.hash", commit.Hash, "pr.ids", ids)
+
+		prs := make([]*git.PullRequest, len(ids))
+		err := forge.ForEach(ctx, len(ids), g.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+			var err error
+			prs[i], err = g.mergedPullRequest(ctx, ids[i])
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to check for commit pull request: %w", err)
+			return nil, err
+		}
+
+		found := false
+		for _, pr := range prs {
+			if pr == nil {
+				continue
+			}
+			found = true
+			expanded = append(expanded, git.Commit{
+				Hash:        commit.Hash,
+				Message:     batchMergeMessage(pr),
+				AuthorLogin: pr.Author,
+				Date:        commit.Date,
+				PullRequest: pr,
+			})
 		}
 
-		commits = append(commits, commit)
+		// The references might point to issues, keep the commit as it is
+		if !found {
+			expanded = append(expanded, commit)
+		}
 	}
 
-	return commits, nil
+	return expanded, nil
+}
+
+// batchMergeMessage returns the commit message for a pull request of a batch merge. Like the default message of a
+// squash merge, it consists of the title and the description of the pull request, so footers like "BREAKING CHANGE"
+// or trailers in the description are kept.
+func batchMergeMessage(pr *git.PullRequest) string {
+	description := strings.TrimSpace(strings.ReplaceAll(pr.Description, "\r\n", "\n"))
+	if description == "" {
+		return pr.Title
+	}
+	return pr.Title + "\n\n" + description
+}
+
+// setPullRequests looks up the associated pull request of every commit. It prefers the GraphQL API, which can resolve
+// many commits in a single request. If that is not available (e.g. because no token is configured), it falls back to
+// one REST request per commit. Commits without an associated pull request are matched through the pull request number
+// in their message.
+func (g *GitHub) setPullRequests(ctx context.Context, commits []git.Commit, releaseCommits []string) error {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	prs, err := g.prsForCommitsGraphQL(ctx, commits, releaseCommits)
+	if err == nil {
+		for i := range commits {
+			commits[i].PullRequest = prs[commits[i].Hash]
+		}
+	} else {
+		g.log.WarnContext(ctx, "failed to fetch pull requests through graphql, falling back to rest api", "error", err)
+
+		err = forge.ForEach(ctx, len(commits), g.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+			var err error
+			commits[i].PullRequest, err = g.prForCommit(ctx, commits[i], releaseCommits)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return forge.ForEach(ctx, len(commits), g.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+		// Batch merges reference multiple pull requests, they are expanded afterward
+		if commits[i].PullRequest != nil || forge.PullRequestReferences(commits[i].Message, batchMergeRegexes...) != nil {
+			return nil
+		}
+
+		var err error
+		commits[i].PullRequest, err = g.prForCommitMessage(ctx, commits[i])
+		return err
+	})
 }
 
 func (g *GitHub) commitsSinceTag(ctx context.Context, tag *git.Tag) ([]*github.RepositoryCommit, error) {
@@ -157,6 +336,9 @@ func (g *GitHub) commitsSinceTag(ctx context.Context, tag *git.Tag) ([]*github.R
 		return nil, err
 	}
 
+	// The compare endpoint lists the commits oldest first, all other forges and the list endpoint newest first.
+	slices.Reverse(repositoryCommits)
+
 	return repositoryCommits, nil
 }
 
@@ -181,7 +363,52 @@ func (g *GitHub) commitsSinceInit(ctx context.Context) ([]*github.RepositoryComm
 	return repositoryCommits, nil
 }
 
-func (g *GitHub) prForCommit(ctx context.Context, commit git.Commit) (*git.PullRequest, error) {
+func (g *GitHub) filterCommitsByPath(ctx context.Context, repositoryCommits []*github.RepositoryCommit, path string) ([]*github.RepositoryCommit, error) {
+	if len(repositoryCommits) == 0 {
+		return repositoryCommits, nil
+	}
+
+	// The compare endpoint does not support filtering by path. Instead, we list all commits touching the path and
+	// only keep those that are also part of the release. The oldest commit of the release bounds the listing, so we
+	// do not need to page through the complete history of the path.
+	since := repositoryCommits[0].GetCommit().GetCommitter().GetDate().Time
+	for _, ghCommit := range repositoryCommits {
+		if date := ghCommit.GetCommit().GetCommitter().GetDate().Time; date.Before(since) {
+			since = date
+		}
+	}
+
+	head := g.options.BaseBranch
+	g.log.Debug("listing commits touching path", "head", head, "path", path, "since", since)
+
+	pathCommits, err := all(
+		func(listOptions github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+			return g.client.Repositories.ListCommits(
+				ctx, g.options.Owner, g.options.Repo,
+				&github.CommitsListOptions{
+					SHA:         head,
+					Path:        path,
+					Since:       since,
+					ListOptions: listOptions,
+				})
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*github.RepositoryCommit, 0, len(pathCommits))
+	for _, ghCommit := range repositoryCommits {
+		if slices.ContainsFunc(pathCommits, func(pathCommit *github.RepositoryCommit) bool {
+			return pathCommit.GetSHA() == ghCommit.GetSHA()
+		}) {
+			filtered = append(filtered, ghCommit)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (g *GitHub) prForCommit(ctx context.Context, commit git.Commit, releaseCommits []string) (*git.PullRequest, error) {
 	// We naively look up the associated PR for each commit through the "List pull requests associated with a commit"
 	// endpoint. This requires len(commits) requests.
 	// Using the "List pull requests" endpoint might be faster, as it allows us to fetch 100 arbitrary PRs per request,
@@ -199,19 +426,52 @@ func (g *GitHub) prForCommit(ctx context.Context, commit git.Commit) (*git.PullR
 		return nil, err
 	}
 
-	var pullRequest *github.PullRequest
+	mergedPRs := make([]forge.MergedPullRequest, 0, len(associatedPRs))
 	for _, pr := range associatedPRs {
-		// We only look for the PR that has this commit set as the "merge commit" => The result of squashing this branch onto main
-		if pr.GetMergeCommitSHA() == commit.Hash {
-			pullRequest = pr
-			break
+		mergedPRs = append(mergedPRs, forge.MergedPullRequest{
+			MergeCommits: []string{pr.GetMergeCommitSHA()},
+			Merged:       pr.MergedAt != nil,
+			BaseBranch:   pr.GetBase().GetRef(),
+		})
+	}
+
+	i := forge.MatchPullRequest(commit.Hash, g.options.BaseBranch, releaseCommits, mergedPRs)
+	if i < 0 {
+		return nil, nil
+	}
+
+	return gitHubPRToPullRequest(associatedPRs[i]), nil
+}
+
+// prForCommitMessage looks up the pull request referenced in the commit message, e.g. "feat: foo (#123)". This is
+// required for commits that are not associated with the pull request on GitHub, e.g. after they were cherry-picked.
+func (g *GitHub) prForCommitMessage(ctx context.Context, commit git.Commit) (*git.PullRequest, error) {
+	id := forge.PullRequestReference(commit.Message, pullRequestReferenceRegexes...)
+	if id == 0 {
+		return nil, nil
+	}
+
+	g.log.DebugContext(ctx, "fetching pull request referenced in commit message", "commit.hash", commit.Hash, "pr.id", id)
+
+	return g.mergedPullRequest(ctx, id)
+}
+
+// mergedPullRequest returns the pull request with the number, or nil if it does not exist or was not merged.
+func (g *GitHub) mergedPullRequest(ctx context.Context, id int) (*git.PullRequest, error) {
+	pr, resp, err := g.client.PullRequests.Get(ctx, g.options.Owner, g.options.Repo, id)
+	if err != nil {
+		if resp != nil && resp.StatusCode == nethttp.StatusNotFound {
+			// The reference might point to an issue or a pull request in a different repository
+			return nil, nil
 		}
+		return nil, err
 	}
-	if pullRequest == nil {
+
+	if pr.MergedAt == nil {
 		return nil, nil
 	}
 
-	return gitHubPRToPullRequest(pullRequest), nil
+	return gitHubPRToPullRequest(pr), nil
 }
 
 func (g *GitHub) EnsureLabelsExist(ctx context.Context, labels []releasepr.Label) error {
@@ -248,28 +508,68 @@ func (g *GitHub) EnsureLabelsExist(ctx context.Context, labels []releasepr.Label
 }
 
 func (g *GitHub) PullRequestForBranch(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
-
 	prs, err := all(
 		func(listOptions github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
-			return g.client.PullRequests.ListPullRequestsWithCommit(ctx, g.options.Owner, g.options.Repo, branch, &listOptions)
+			return g.client.PullRequests.List(ctx, g.options.Owner, g.options.Repo, &github.PullRequestListOptions{
+				State: PRStateOpen,
+				// Branches from forks are not considered, as the branch is always pushed to the repository itself.
+				Head:        fmt.Sprintf("%s:%s", g.options.Owner, branch),
+				Base:        g.options.BaseBranch,
+				Sort:        "created",
+				Direction:   "desc",
+				ListOptions: listOptions,
+			})
 		})
 	if err != nil {
-		var ghErr *github.ErrorResponse
-		if errors.As(err, &ghErr) {
-			if ghErr.Message == fmt.Sprintf("No commit found for SHA: %s", branch) {
-				return nil, nil
-			}
+		return nil, err
+	}
+
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	if len(prs) > 1 {
+		for _, pr := range prs[1:] {
+			g.log.WarnContext(ctx, "found multiple open pull requests for release branch, ignoring older pull request",
+				"branch", branch,
+				"pr.id", pr.GetNumber(),
+				"pr.url", pr.GetHTMLURL(),
+			)
 		}
+	}
+
+	// Sorted by creation date, so the first one is the most recent pull request
+	return gitHubPRToReleasePullRequest(prs[0]), nil
+}
+
+func (g *GitHub) PullRequestComments(ctx context.Context, pr *releasepr.ReleasePullRequest) ([]string, error) {
+	ghComments, err := all(func(listOptions github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return g.client.Issues.ListComments(
+			ctx, g.options.Owner, g.options.Repo,
+			pr.ID, &github.IssueListCommentsOptions{
+				Sort:        pointer.Pointer("created"),
+				Direction:   pointer.Pointer("asc"),
+				ListOptions: listOptions,
+			})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	for _, pr := range prs {
-		if pr.GetBase().GetRef() == g.options.BaseBranch && pr.GetHead().GetRef() == branch && pr.GetState() == PRStateOpen {
-			return gitHubPRToReleasePullRequest(pr), nil
+	comments := make([]string, 0, len(ghComments))
+	for _, comment := range ghComments {
+		switch comment.GetAuthorAssociation() {
+		case "OWNER", "MEMBER", "COLLABORATOR":
+			comments = append(comments, comment.GetBody())
+		default:
+			g.log.DebugContext(ctx, "ignoring comment by user without write access",
+				"comment.id", comment.GetID(),
+				"comment.author", comment.GetUser().GetLogin(),
+			)
 		}
 	}
 
-	return nil, nil
+	return comments, nil
 }
 
 func (g *GitHub) CreatePullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
@@ -389,14 +689,64 @@ func (g *GitHub) PendingReleases(ctx context.Context, pendingLabel releasepr.Lab
 	return prs, nil
 }
 
-func (g *GitHub) CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, preRelease, latest bool) error {
+func (g *GitHub) OpenPullRequests(ctx context.Context, headPrefix string) ([]*releasepr.ReleasePullRequest, error) {
+	ghPRs, err := all(func(listOptions github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
+		return g.client.PullRequests.List(
+			ctx, g.options.Owner, g.options.Repo,
+			&github.PullRequestListOptions{
+				State:       PRStateOpen,
+				ListOptions: listOptions,
+			})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]*releasepr.ReleasePullRequest, 0, len(ghPRs))
+	for _, pr := range ghPRs {
+		// Branches from forks are not considered, as the branch is always pushed to the repository itself.
+		if pr.GetHead().GetRepo().GetID() != pr.GetBase().GetRepo().GetID() {
+			continue
+		}
+		if !strings.HasPrefix(pr.GetHead().GetRef(), headPrefix) {
+			continue
+		}
+
+		prs = append(prs, gitHubPRToReleasePullRequest(pr))
+	}
+
+	return prs, nil
+}
+
+func (g *GitHub) CommentOnPullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest, comment string) error {
+	_, _, err := g.client.Issues.CreateComment(
+		ctx, g.options.Owner, g.options.Repo,
+		pr.ID, &github.IssueComment{Body: &comment},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *GitHub) DeleteBranch(ctx context.Context, branch string) error {
+	_, err := g.client.Git.DeleteRef(ctx, g.options.Owner, g.options.Repo, "refs/heads/"+branch)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *GitHub) CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, preRelease, latest bool) (forge.Release, error) {
 	makeLatest := ""
 	if latest {
 		makeLatest = "true"
 	} else {
 		makeLatest = "false"
 	}
-	_, _, err := g.client.Repositories.CreateRelease(
+	release, _, err := g.client.Repositories.CreateRelease(
 		ctx, g.options.Owner, g.options.Repo,
 		&github.RepositoryRelease{
 			TagName:         &title,
@@ -408,10 +758,74 @@ func (g *GitHub) CreateRelease(ctx context.Context, commit git.Commit, title, ch
 		},
 	)
 	if err != nil {
-		return err
+		return forge.Release{}, err
 	}
 
-	return nil
+	return forge.Release{
+		ID:         release.GetID(),
+		TagName:    title,
+		URL:        release.GetHTMLURL(),
+		Changelog:  changelog,
+		Prerelease: preRelease,
+		UploadURL:  release.GetUploadURL(),
+	}, nil
+}
+
+func (g *GitHub) FindRelease(ctx context.Context, tagName string) (*forge.Release, error) {
+	release, resp, err := g.client.Repositories.GetReleaseByTag(ctx, g.options.Owner, g.options.Repo, tagName)
+	if err != nil {
+		if resp != nil && resp.StatusCode == nethttp.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &forge.Release{
+		ID:         release.GetID(),
+		TagName:    tagName,
+		URL:        release.GetHTMLURL(),
+		Changelog:  release.GetBody(),
+		Prerelease: release.GetPrerelease(),
+		UploadURL:  release.GetUploadURL(),
+	}, nil
+}
+
+func (g *GitHub) LastMergedPullRequest(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
+	// Only the most recently updated pull requests are considered, older release pull requests were released by
+	// previous runs.
+	prs, _, err := g.client.PullRequests.List(ctx, g.options.Owner, g.options.Repo, &github.PullRequestListOptions{
+		State:       PRStateClosed,
+		Head:        fmt.Sprintf("%s:%s", g.options.Owner, branch),
+		Base:        g.options.BaseBranch,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 10},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var last *github.PullRequest
+	for _, pr := range prs {
+		// pr.Merged is always nil :(
+		if pr.MergedAt != nil && (last == nil || pr.GetMergedAt().After(last.GetMergedAt().Time)) {
+			last = pr
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	return gitHubPRToReleasePullRequest(last), nil
+}
+
+func (g *GitHub) UploadReleaseAsset(ctx context.Context, release forge.Release, name string, file *os.File) error {
+	_, _, err := g.client.Repositories.UploadReleaseAsset(
+		ctx, g.options.Owner, g.options.Repo, release.ID,
+		&github.UploadOptions{Name: name},
+		file,
+	)
+	return err
 }
 
 func all[T any](f func(listOptions github.ListOptions) ([]T, *github.Response, error)) ([]T, error) {
@@ -433,11 +847,103 @@ func all[T any](f func(listOptions github.ListOptions) ([]T, *github.Response, e
 	}
 }
 
+func (g *GitHub) MergedPullRequestCount(ctx context.Context, login string) (int, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s", g.options.Owner, g.options.Repo, login)
+
+	result, _, err := g.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.GetTotal(), nil
+}
+
+func (g *GitHub) CreateCommit(ctx context.Context, branch, parent, message string, files []git.FileChange) (git.Commit, error) {
+	parentCommit, _, err := g.client.Git.GetCommit(ctx, g.options.Owner, g.options.Repo, parent)
+	if err != nil {
+		return git.Commit{}, err
+	}
+
+	entries := make([]*github.TreeEntry, len(files))
+	err = forge.ForEach(ctx, len(files), g.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+		file := files[i]
+
+		mode := "100644"
+		if file.Executable {
+			mode = "100755"
+		}
+		entries[i] = &github.TreeEntry{
+			Path: pointer.Pointer(file.Path),
+			Mode: pointer.Pointer(mode),
+			Type: pointer.Pointer("blob"),
+		}
+
+		// An entry without SHA and content deletes the file from the base tree
+		if file.Deleted {
+			return nil
+		}
+
+		// The content of tree entries must be valid UTF-8, blobs also support binary files
+		blob, _, err := g.client.Git.CreateBlob(ctx, g.options.Owner, g.options.Repo, &github.Blob{
+			Content:  pointer.Pointer(base64.StdEncoding.EncodeToString(file.Content)),
+			Encoding: pointer.Pointer("base64"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create blob for %s: %w", file.Path, err)
+		}
+		entries[i].SHA = blob.SHA
+
+		return nil
+	})
+	if err != nil {
+		return git.Commit{}, err
+	}
+
+	tree, _, err := g.client.Git.CreateTree(ctx, g.options.Owner, g.options.Repo, parentCommit.GetTree().GetSHA(), entries)
+	if err != nil {
+		return git.Commit{}, fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	// Author and committer are not set, so GitHub attributes the commit to the user of the token and signs it.
+	commit, _, err := g.client.Git.CreateCommit(ctx, g.options.Owner, g.options.Repo, &github.Commit{
+		Message: &message,
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: &parent}},
+	}, nil)
+	if err != nil {
+		return git.Commit{}, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	ref := &github.Reference{
+		Ref:    pointer.Pointer("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}
+
+	_, resp, err := g.client.Git.UpdateRef(ctx, g.options.Owner, g.options.Repo, ref, true)
+	if resp != nil && resp.StatusCode == nethttp.StatusUnprocessableEntity {
+		// The branch does not exist yet.
+		_, _, err = g.client.Git.CreateRef(ctx, g.options.Owner, g.options.Repo, ref)
+	}
+	if err != nil {
+		return git.Commit{}, fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+
+	return git.Commit{Hash: commit.GetSHA(), Message: message}, nil
+}
+
 func gitHubPRToPullRequest(pr *github.PullRequest) *git.PullRequest {
+	var labels []string
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+
 	return &git.PullRequest{
 		ID:          pr.GetNumber(),
 		Title:       pr.GetTitle(),
 		Description: pr.GetBody(),
+		Labels:      labels,
+		Author:      pr.GetUser().GetLogin(),
+		Branch:      pr.GetHead().GetRef(),
 	}
 }
 
@@ -462,6 +968,7 @@ func gitHubPRToReleasePullRequest(pr *github.PullRequest) *releasepr.ReleasePull
 		Labels:      labels,
 
 		Head:          pr.GetHead().GetRef(),
+		Base:          pr.GetBase().GetRef(),
 		ReleaseCommit: releaseCommit,
 	}
 }
@@ -469,10 +976,22 @@ func gitHubPRToReleasePullRequest(pr *github.PullRequest) *releasepr.ReleasePull
 func (g *Options) autodiscover() {
 	if apiToken := os.Getenv(EnvAPIToken); apiToken != "" {
 		g.APIToken = apiToken
+	} else if inputToken := os.Getenv(EnvInputToken); inputToken != "" && g.APIToken == "" {
+		// The "token" input of the action, in case it was not passed through GITHUB_TOKEN
+		g.APIToken = inputToken
 	}
 	// TODO: Check if there is a better solution for cloning/pushing locally
 	if username := os.Getenv(EnvUsername); username != "" {
 		g.Username = username
+	} else if actor := os.Getenv(EnvActor); actor != "" && g.Username == "" {
+		g.Username = actor
+	}
+
+	if apiURL := os.Getenv(EnvAPIURL); apiURL != "" && g.APIURL == "" {
+		g.APIURL = apiURL
+	}
+	if serverURL := os.Getenv(EnvServerURL); serverURL != "" && g.ServerURL == "" {
+		g.ServerURL = serverURL
 	}
 
 	if envRepository := os.Getenv(EnvRepository); envRepository != "" {
@@ -494,22 +1013,82 @@ type Options struct {
 
 	APIToken string
 	Username string
+
+	// APIURL is the base URL of the REST API, e.g. "https://github.example.com/api/v3/" for GitHub Enterprise Server.
+	// Defaults to DefaultAPIURL.
+	APIURL string
+	// ServerURL is the base URL of the web interface, used for links and to clone the repository. Defaults to the
+	// host of APIURL for GitHub Enterprise Server and to DefaultServerURL otherwise.
+	ServerURL string
+
+	// MaxRetries for requests that hit a rate limit. Defaults to DefaultMaxRetries, negative values disable retries.
+	MaxRetries int
 }
 
-func New(log *slog.Logger, options *Options) *GitHub {
+func New(log *slog.Logger, options *Options) (*GitHub, error) {
 	options.autodiscover()
 
-	client := github.NewClient(nil)
+	maxRetries := options.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	client := github.NewClient(&nethttp.Client{
+		Transport: newRateLimitTransport(log.With("forge", "github"), telemetry.Transport("github", nil), maxRetries),
+	})
 	if options.APIToken != "" {
 		client = client.WithAuthToken(options.APIToken)
 	}
 
+	graphQLPath := "graphql"
+
+	if options.APIURL != "" && strings.TrimSuffix(options.APIURL, "/")+"/" != DefaultAPIURL {
+		// GitHub Enterprise Server serves all APIs from the same host: /api/v3/, /api/uploads/ and /api/graphql
+		apiURL, err := url.Parse(options.APIURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api url: %w", err)
+		}
+		hostURL := fmt.Sprintf("%s://%s", apiURL.Scheme, apiURL.Host)
+
+		client, err = client.WithEnterpriseURLs(options.APIURL, hostURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api url: %w", err)
+		}
+
+		graphQLPath = "../graphql"
+
+		if options.ServerURL == "" {
+			options.ServerURL = hostURL
+		}
+	}
+
+	if options.ServerURL == "" {
+		options.ServerURL = DefaultServerURL
+	}
+	options.ServerURL = strings.TrimSuffix(options.ServerURL, "/")
+
 	gh := &GitHub{
 		options: options,
 
-		client: client,
-		log:    log.With("forge", "github"),
+		client:      client,
+		graphQLPath: graphQLPath,
+		log:         log.With("forge", "github"),
 	}
 
-	return gh
+	return gh, nil
+}
+
+// InActions reports if releaser-pleaser is running in a GitHub Actions workflow.
+func InActions() bool {
+	return os.Getenv(EnvActions) == "true"
+}
+
+// ActionsBranch returns the branch that triggered the GitHub Actions workflow, or an empty string if the workflow was
+// triggered by a tag or a pull request.
+func ActionsBranch() string {
+	branch, ok := strings.CutPrefix(os.Getenv(EnvRef), "refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
 }
@@ -0,0 +1,195 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestGitHub_expandBatchMerges(t *testing.T) {
+	prs := map[string]*github.PullRequest{
+		"12": {
+			Number:   github.Int(12),
+			Title:    github.String("feat: foo"),
+			Body:     github.String("Adds foo.\r\n\r\nBREAKING CHANGE: bar is removed\r\n"),
+			User:     &github.User{Login: github.String("alice")},
+			MergedAt: &github.Timestamp{},
+		},
+		"34": {
+			Number:   github.Int(34),
+			Title:    github.String("fix: baz"),
+			User:     &github.User{Login: github.String("bob")},
+			MergedAt: &github.Timestamp{},
+		},
+		"56": {
+			Number:   github.Int(56),
+			Title:    github.String("feat: not merged"),
+			MergedAt: nil,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr, ok := prs[strings.TrimPrefix(r.URL.Path, "/api/v3/repos/owner/repo/pulls/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(pr)
+	}))
+	t.Cleanup(server.Close)
+
+	g, err := New(slog.Default(), &Options{Owner: "owner", Repo: "repo", APIURL: server.URL + "/api/v3/", MaxRetries: -1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		commits []git.Commit
+		want    []git.Commit
+	}{
+		{
+			name: "bors",
+			commits: []git.Commit{
+				{Hash: "abc", Message: "Merge #12 #34\n\n12: feat: foo r=carol a=alice\n\nCo-authored-by: alice <alice@example.com>\n"},
+			},
+			want: []git.Commit{
+				{
+					Hash:        "abc",
+					Message:     "feat: foo\n\nAdds foo.\n\nBREAKING CHANGE: bar is removed",
+					AuthorLogin: "alice",
+					PullRequest: &git.PullRequest{ID: 12, Title: "feat: foo", Description: "Adds foo.\r\n\r\nBREAKING CHANGE: bar is removed\r\n", Author: "alice"},
+				},
+				{
+					Hash:        "abc",
+					Message:     "fix: baz",
+					AuthorLogin: "bob",
+					PullRequest: &git.PullRequest{ID: 34, Title: "fix: baz", Author: "bob"},
+				},
+			},
+		},
+		{
+			name: "merge queue",
+			commits: []git.Commit{
+				{Hash: "abc", Message: "Merge pull requests from merge queue (#34, #56, #78)"},
+			},
+			want: []git.Commit{
+				{
+					Hash:        "abc",
+					Message:     "fix: baz",
+					AuthorLogin: "bob",
+					PullRequest: &git.PullRequest{ID: 34, Title: "fix: baz", Author: "bob"},
+				},
+			},
+		},
+		{
+			name: "already associated",
+			commits: []git.Commit{
+				{Hash: "abc", Message: "Merge #12 #34"},
+				{Hash: "def", Message: "fix: baz", PullRequest: &git.PullRequest{ID: 34}},
+			},
+			want: []git.Commit{
+				{
+					Hash:        "abc",
+					Message:     "feat: foo\n\nAdds foo.\n\nBREAKING CHANGE: bar is removed",
+					AuthorLogin: "alice",
+					PullRequest: &git.PullRequest{ID: 12, Title: "feat: foo", Description: "Adds foo.\r\n\r\nBREAKING CHANGE: bar is removed\r\n", Author: "alice"},
+				},
+				{Hash: "def", Message: "fix: baz", PullRequest: &git.PullRequest{ID: 34}},
+			},
+		},
+		{
+			name: "no merged pull requests",
+			commits: []git.Commit{
+				{Hash: "abc", Message: "Merge #56 #78"},
+			},
+			want: []git.Commit{
+				{Hash: "abc", Message: "Merge #56 #78"},
+			},
+		},
+		{
+			name: "squashed commit with multiple references",
+			commits: []git.Commit{
+				{Hash: "abc", Message: "fix: foo (#12) (#34)", PullRequest: &git.PullRequest{ID: 34}},
+			},
+			want: []git.Commit{
+				{Hash: "abc", Message: "fix: foo (#12) (#34)", PullRequest: &git.PullRequest{ID: 34}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.expandBatchMerges(context.Background(), tt.commits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGitHub_CreateCommit(t *testing.T) {
+	var blobs []github.Blob
+	var tree struct {
+		BaseTree string           `json:"base_tree"`
+		Tree     []map[string]any `json:"tree"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/git/commits/parent", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.Commit{SHA: github.String("parent"), Tree: &github.Tree{SHA: github.String("base")}})
+	})
+	mux.HandleFunc("POST /api/v3/repos/owner/repo/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		var blob github.Blob
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&blob))
+		blobs = append(blobs, blob)
+		_ = json.NewEncoder(w).Encode(&github.Blob{SHA: github.String("blob")})
+	})
+	mux.HandleFunc("POST /api/v3/repos/owner/repo/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&tree))
+		_ = json.NewEncoder(w).Encode(&github.Tree{SHA: github.String("tree")})
+	})
+	mux.HandleFunc("POST /api/v3/repos/owner/repo/git/commits", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.Commit{SHA: github.String("commit")})
+	})
+	mux.HandleFunc("PATCH /api/v3/repos/owner/repo/git/refs/heads/release", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.Reference{})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// Blobs are created one after another, so the requests are recorded in order
+	g, err := New(slog.Default(), &Options{Owner: "owner", Repo: "repo", APIURL: server.URL + "/api/v3/", MaxRetries: -1})
+	require.NoError(t, err)
+	g.options.Concurrency = 1
+
+	commit, err := g.CreateCommit(context.Background(), "release", "parent", "chore: release", []git.FileChange{
+		{Path: "CHANGELOG.md", Content: []byte("# Changelog\n")},
+		{Path: "logo.png", Content: []byte{0x89, 'P', 'N', 'G', 0xff}, Created: true},
+		{Path: "bin/run", Content: []byte("#!/bin/sh\n"), Executable: true},
+		{Path: "version.txt", Deleted: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, git.Commit{Hash: "commit", Message: "chore: release"}, commit)
+
+	assert.Equal(t, []github.Blob{
+		{Content: github.String("IyBDaGFuZ2Vsb2cK"), Encoding: github.String("base64")},
+		{Content: github.String("iVBOR/8="), Encoding: github.String("base64")},
+		{Content: github.String("IyEvYmluL3NoCg=="), Encoding: github.String("base64")},
+	}, blobs)
+
+	assert.Equal(t, "base", tree.BaseTree)
+	assert.Equal(t, []map[string]any{
+		{"path": "CHANGELOG.md", "mode": "100644", "type": "blob", "sha": "blob"},
+		{"path": "logo.png", "mode": "100644", "type": "blob", "sha": "blob"},
+		{"path": "bin/run", "mode": "100755", "type": "blob", "sha": "blob"},
+		{"path": "version.txt", "mode": "100644", "type": "blob", "sha": nil},
+	}, tree.Tree)
+}
@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+const (
+	// GraphQLBatchSize is the number of commits that are looked up in a single GraphQL query.
+	GraphQLBatchSize = 50
+	// GraphQLAssociatedPullRequests is the maximum number of pull requests fetched for every commit.
+	GraphQLAssociatedPullRequests = 10
+	// GraphQLLabels is the maximum number of labels fetched for every pull request.
+	GraphQLLabels = 20
+)
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type graphQLPullRequest struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	Merged      bool   `json:"merged"`
+	BaseRefName string `json:"baseRefName"`
+	HeadRefName string `json:"headRefName"`
+	MergeCommit *struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	// Author is nil if the account was deleted.
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+type graphQLCommit struct {
+	AssociatedPullRequests struct {
+		Nodes []graphQLPullRequest `json:"nodes"`
+	} `json:"associatedPullRequests"`
+}
+
+type graphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+type graphQLCommitsData struct {
+	Repository map[string]*graphQLCommit `json:"repository"`
+}
+
+// prsForCommitsGraphQL looks up the pull requests associated with each commit using the GraphQL API. Up to
+// GraphQLBatchSize commits are resolved in a single request, instead of one REST request per commit.
+func (g *GitHub) prsForCommitsGraphQL(ctx context.Context, commits []git.Commit, releaseCommits []string) (map[string]*git.PullRequest, error) {
+	prs := make(map[string]*git.PullRequest, len(commits))
+
+	for start := 0; start < len(commits); start += GraphQLBatchSize {
+		end := min(start+GraphQLBatchSize, len(commits))
+		batch := commits[start:end]
+
+		g.log.DebugContext(ctx, "fetching pull requests associated with commits through graphql", "commits", len(batch))
+
+		var data graphQLCommitsData
+		err := g.graphQL(ctx, associatedPullRequestsQuery(batch), map[string]any{
+			"owner": g.options.Owner,
+			"name":  g.options.Repo,
+		}, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, commit := range batch {
+			ghCommit := data.Repository[commitAlias(i)]
+			if ghCommit == nil {
+				continue
+			}
+
+			nodes := ghCommit.AssociatedPullRequests.Nodes
+			mergedPRs := make([]forge.MergedPullRequest, 0, len(nodes))
+			for _, pr := range nodes {
+				mergedPR := forge.MergedPullRequest{Merged: pr.Merged, BaseBranch: pr.BaseRefName}
+				if pr.MergeCommit != nil {
+					mergedPR.MergeCommits = []string{pr.MergeCommit.OID}
+				}
+				mergedPRs = append(mergedPRs, mergedPR)
+			}
+
+			if i := forge.MatchPullRequest(commit.Hash, g.options.BaseBranch, releaseCommits, mergedPRs); i >= 0 {
+				var labels []string
+				for _, label := range nodes[i].Labels.Nodes {
+					labels = append(labels, label.Name)
+				}
+
+				pr := &git.PullRequest{
+					ID:          nodes[i].Number,
+					Title:       nodes[i].Title,
+					Description: nodes[i].Body,
+					Labels:      labels,
+					Branch:      nodes[i].HeadRefName,
+				}
+				if nodes[i].Author != nil {
+					pr.Author = nodes[i].Author.Login
+				}
+				prs[commit.Hash] = pr
+			}
+		}
+	}
+
+	return prs, nil
+}
+
+// graphQL runs the query and decodes the "data" field of the response into data.
+func (g *GitHub) graphQL(ctx context.Context, query string, variables map[string]any, data any) error {
+	req, err := g.client.NewRequest("POST", g.graphQLPath, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	resp := graphQLResponse{Data: data}
+	_, err = g.client.Do(ctx, req, &resp)
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		messages := make([]string, 0, len(resp.Errors))
+		for _, graphQLErr := range resp.Errors {
+			messages = append(messages, graphQLErr.Message)
+		}
+		return fmt.Errorf("graphql query failed: %s", strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func associatedPullRequestsQuery(commits []git.Commit) string {
+	var query strings.Builder
+
+	query.WriteString("query($owner: String!, $name: String!) {\n  repository(owner: $owner, name: $name) {\n")
+	for i, commit := range commits {
+		fmt.Fprintf(&query, "    %s: object(oid: %q) { ...associatedPullRequests }\n", commitAlias(i), commit.Hash)
+	}
+	query.WriteString("  }\n}\n")
+
+	fmt.Fprintf(&query, `fragment associatedPullRequests on Commit {
+  associatedPullRequests(first: %d) {
+    nodes { number title body merged baseRefName headRefName mergeCommit { oid } labels(first: %d) { nodes { name } } author { login } }
+  }
+}
+`, GraphQLAssociatedPullRequests, GraphQLLabels)
+
+	return query.String()
+}
+
+func commitAlias(i int) string {
+	return fmt.Sprintf("c%d", i)
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/google/go-github/v66/github"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+var _ forge.PermissionChecker = &GitHub{}
+
+// CheckPermissions probes the API with read requests and with empty create requests. GitHub validates the permissions
+// of the token before the request body, so the empty requests fail with 422 if the permission is granted and nothing
+// is created. The permissions are named like in the permissions of a workflow, classic tokens need the repo scope.
+func (g *GitHub) CheckPermissions(ctx context.Context) ([]forge.PermissionCheck, error) {
+	repoPath := fmt.Sprintf("repos/%s/%s", g.options.Owner, g.options.Repo)
+
+	return forge.RunProbes(ctx, []forge.Probe{
+		{Action: "read tags", Permission: "contents: read", Do: g.probe(nethttp.MethodGet, repoPath+"/tags?per_page=1")},
+		{Action: "read commits", Permission: "contents: read", Do: g.probe(nethttp.MethodGet, repoPath+"/commits?per_page=1")},
+		{Action: "read pull requests", Permission: "pull-requests: read", Do: g.probe(nethttp.MethodGet, repoPath+"/pulls?state=all&per_page=1")},
+		{Action: "create labels", Permission: "pull-requests: write", Do: g.probe(nethttp.MethodPost, repoPath+"/labels")},
+		{Action: "push branches", Permission: "contents: write", Do: g.probe(nethttp.MethodPost, repoPath+"/git/refs")},
+		{Action: "create pull requests", Permission: "pull-requests: write", Do: g.probe(nethttp.MethodPost, repoPath+"/pulls")},
+		{Action: "create releases", Permission: "contents: write", Do: g.probe(nethttp.MethodPost, repoPath+"/releases")},
+	})
+}
+
+// probe returns a forge.Probe function that sends the request with an empty body for POST requests.
+func (g *GitHub) probe(method, path string) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		var body any
+		if method == nethttp.MethodPost {
+			body = struct{}{}
+		}
+
+		req, err := g.client.NewRequest(method, path, body)
+		if err != nil {
+			return 0, err
+		}
+
+		resp, err := g.client.Do(ctx, req, nil)
+		return statusCode(resp), err
+	}
+}
+
+func statusCode(resp *github.Response) int {
+	if resp == nil || resp.Response == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
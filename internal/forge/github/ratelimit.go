@@ -0,0 +1,127 @@
+package github
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries for requests that hit a rate limit.
+	DefaultMaxRetries = 3
+	// MaxRetryWait limits how long a single retry waits for the rate limit to reset. Requests that would need to wait
+	// longer fail immediately.
+	MaxRetryWait = 5 * time.Minute
+
+	retryBaseWait = 5 * time.Second
+
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// rateLimitTransport retries requests that were rejected because of the primary or secondary rate limits of the
+// GitHub API. See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+type rateLimitTransport struct {
+	base       http.RoundTripper
+	log        *slog.Logger
+	maxRetries int
+}
+
+func newRateLimitTransport(log *slog.Logger, base http.RoundTripper, maxRetries int) *rateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &rateLimitTransport{
+		base:       base,
+		log:        log,
+		maxRetries: maxRetries,
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if remaining := resp.Header.Get(headerRateLimitRemaining); remaining != "" {
+			t.log.DebugContext(req.Context(), "github api rate limit", "remaining", remaining, "reset", resp.Header.Get(headerRateLimitReset))
+		}
+
+		wait, limited := retryWait(resp, attempt, time.Now())
+		if !limited || attempt >= t.maxRetries || wait > MaxRetryWait {
+			return resp, nil
+		}
+
+		// The body needs to be sent again in the retry
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		} else if req.Body != nil {
+			return resp, nil
+		}
+
+		t.log.WarnContext(req.Context(), "hit github api rate limit, retrying", "wait", wait, "attempt", attempt+1, "max_retries", t.maxRetries)
+		_ = resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryWait returns how long to wait before the request can be retried, if the response is caused by a rate limit.
+func retryWait(resp *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if retryAfter := resp.Header.Get(headerRetryAfter); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if resp.Header.Get(headerRateLimitRemaining) == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0) + time.Second, true
+		}
+	}
+
+	if resp.StatusCode == http.StatusForbidden && !isSecondaryRateLimit(resp) {
+		// Forbidden for other reasons is a permission issue, retrying does not help
+		return 0, false
+	}
+
+	// Secondary rate limits without further information: exponential backoff with jitter
+	backoff := retryBaseWait << attempt
+	return backoff/2 + rand.N(backoff/2), true
+}
+
+// isSecondaryRateLimit checks the error message in the response body. The body is restored, so it can still be read
+// by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	return bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
@@ -2,13 +2,15 @@ package gitlab
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
+	nethttp "net/http"
 	"os"
+	"regexp"
 	"slices"
 	"strings"
 
-	"github.com/blang/semver/v4"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/xanzy/go-gitlab"
@@ -17,6 +19,8 @@ import (
 	"github.com/apricote/releaser-pleaser/internal/git"
 	"github.com/apricote/releaser-pleaser/internal/pointer"
 	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/telemetry"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
 )
 
 const (
@@ -25,13 +29,32 @@ const (
 	PRStateMerged     = "merged"
 	PRStateEventClose = "close"
 
+	// MaxDescriptionLength is the maximum length of the description of a merge request.
+	MaxDescriptionLength = 1048576
+
 	EnvAPIToken = "GITLAB_TOKEN" // nolint:gosec // Not actually a hardcoded credential
+	// EnvToken is used if EnvAPIToken is not set, e.g. for a project access token in a CI/CD variable.
+	EnvToken = "RELEASER_PLEASER_TOKEN" // nolint:gosec // Not actually a hardcoded credential
 
 	// The following vars are from https://docs.gitlab.com/ee/ci/variables/predefined_variables.html
 
-	EnvAPIURL      = "CI_API_V4_URL"
-	EnvProjectURL  = "CI_PROJECT_URL"
-	EnvProjectPath = "CI_PROJECT_PATH"
+	EnvCI            = "GITLAB_CI"
+	EnvServerURL     = "CI_SERVER_URL"
+	EnvAPIURL        = "CI_API_V4_URL"
+	EnvProjectURL    = "CI_PROJECT_URL"
+	EnvProjectPath   = "CI_PROJECT_PATH"
+	EnvDefaultBranch = "CI_DEFAULT_BRANCH"
+	EnvJobToken      = "CI_JOB_TOKEN" // nolint:gosec // Not actually a hardcoded credential
+
+	// JobTokenUsername is required to clone and push with a CI/CD job token.
+	JobTokenUsername = "gitlab-ci-token"
+)
+
+var (
+	// pullRequestReferenceRegexes match the merge request number in the subjects of commits.
+	pullRequestReferenceRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\(!(\d+)\)$`),
+	}
 )
 
 type GitLab struct {
@@ -61,7 +84,26 @@ func (g *GitLab) PullRequestURL(id int) string {
 	return fmt.Sprintf("%s/-/merge_requests/%d", g.RepoURL(), id)
 }
 
+func (g *GitLab) CompareURL(from, to string) string {
+	return fmt.Sprintf("%s/-/compare/%s...%s", g.RepoURL(), from, to)
+}
+
+func (g *GitLab) CommitURL(hash string) string {
+	return fmt.Sprintf("%s/-/commit/%s", g.RepoURL(), hash)
+}
+
+func (g *GitLab) MaxDescriptionLength() int {
+	return MaxDescriptionLength
+}
+
 func (g *GitLab) GitAuth() transport.AuthMethod {
+	if g.options.JobToken {
+		return &http.BasicAuth{
+			Username: JobTokenUsername,
+			Password: g.options.APIToken,
+		}
+	}
+
 	return &http.BasicAuth{
 		// Username just needs to be any non-blank value
 		Username: "api-token",
@@ -69,7 +111,7 @@ func (g *GitLab) GitAuth() transport.AuthMethod {
 	}
 }
 
-func (g *GitLab) LatestTags(ctx context.Context) (git.Releases, error) {
+func (g *GitLab) LatestTags(ctx context.Context, tagPrefix string, strategy versioning.Strategy) (git.Releases, error) {
 	g.log.DebugContext(ctx, "listing all tags in gitlab repository")
 
 	tags, err := all(func(listOptions gitlab.ListOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
@@ -82,40 +124,28 @@ func (g *GitLab) LatestTags(ctx context.Context) (git.Releases, error) {
 		return git.Releases{}, err
 	}
 
-	var releases git.Releases
+	releaseTags := make([]git.Tag, 0, len(tags))
 	for _, glTag := range tags {
-		tag := &git.Tag{
-			Hash: glTag.Commit.ID,
-			Name: glTag.Name,
-		}
-
-		version, err := semver.Parse(strings.TrimPrefix(tag.Name, "v"))
-		if err != nil {
-			g.log.WarnContext(
-				ctx, "unable to parse tag as semver, skipping",
-				"tag.name", tag.Name,
-				"tag.hash", tag.Hash,
-				"error", err,
-			)
-			continue
-		}
+		releaseTags = append(releaseTags, git.Tag{Hash: glTag.Commit.ID, Name: glTag.Name})
+	}
 
-		if releases.Latest == nil {
-			releases.Latest = tag
-		}
-		if len(version.Pre) == 0 {
-			// Stable version tag
-			// We return once we have found the latest stable tag, not needed to look at every single tag.
-			releases.Stable = tag
-			break
-		}
+	return forge.LatestReleases(ctx, g.log, releaseTags, tagPrefix, strategy, g.tagReachable)
+}
 
+// tagReachable checks if the tagged commit is part of the history of the base branch. Tags created on other
+// branches, e.g. maintenance branches for older versions, are not relevant for the next release.
+func (g *GitLab) tagReachable(ctx context.Context, tag *git.Tag) (bool, error) {
+	mergeBase, _, err := g.client.Repositories.MergeBase(g.options.Path, &gitlab.MergeBaseOptions{
+		Ref: &[]string{tag.Hash, g.options.BaseBranch},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("failed to get merge base of tag %s and base branch: %w", tag.Name, err)
 	}
 
-	return releases, nil
+	return mergeBase.ID == tag.Hash, nil
 }
 
-func (g *GitLab) CommitsSince(ctx context.Context, tag *git.Tag) ([]git.Commit, error) {
+func (g *GitLab) CommitsSince(ctx context.Context, tag *git.Tag, path string) ([]git.Commit, error) {
 	var err error
 
 	head := g.options.BaseBranch
@@ -128,11 +158,17 @@ func (g *GitLab) CommitsSince(ctx context.Context, tag *git.Tag) ([]git.Commit,
 	} else {
 		refName = head
 	}
+	var pathFilter *string
+	if path != "" {
+		log = log.With("path", path)
+		pathFilter = &path
+	}
 	log.Debug("listing commits", "ref.name", refName)
 
 	gitLabCommits, err := all(func(listOptions gitlab.ListOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
 		return g.client.Commits.ListCommits(g.options.Path, &gitlab.ListCommitsOptions{
 			RefName:     &refName,
+			Path:        pathFilter,
 			ListOptions: listOptions,
 		}, gitlab.WithContext(ctx))
 	})
@@ -140,24 +176,36 @@ func (g *GitLab) CommitsSince(ctx context.Context, tag *git.Tag) ([]git.Commit,
 		return nil, err
 	}
 
+	releaseCommits := make([]string, 0, len(gitLabCommits))
+	for _, glCommit := range gitLabCommits {
+		releaseCommits = append(releaseCommits, glCommit.ID)
+	}
+
 	var commits = make([]git.Commit, 0, len(gitLabCommits))
-	for _, ghCommit := range gitLabCommits {
+	for _, glCommit := range gitLabCommits {
 		commit := git.Commit{
-			Hash:    ghCommit.ID,
-			Message: ghCommit.Message,
+			Hash:    glCommit.ID,
+			Message: glCommit.Message,
 		}
-		commit.PullRequest, err = g.prForCommit(ctx, commit)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check for commit pull request: %w", err)
+		if glCommit.CommittedDate != nil {
+			commit.Date = *glCommit.CommittedDate
 		}
-
 		commits = append(commits, commit)
 	}
 
+	err = forge.ForEach(ctx, len(commits), g.options.ConcurrencyOrDefault(), func(ctx context.Context, i int) error {
+		var err error
+		commits[i].PullRequest, err = g.prForCommit(ctx, commits[i], releaseCommits)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for commit pull request: %w", err)
+	}
+
 	return commits, nil
 }
 
-func (g *GitLab) prForCommit(ctx context.Context, commit git.Commit) (*git.PullRequest, error) {
+func (g *GitLab) prForCommit(ctx context.Context, commit git.Commit, releaseCommits []string) (*git.PullRequest, error) {
 	// We naively look up the associated MR for each commit through the "List merge requests associated with a commit"
 	// endpoint. This requires len(commits) requests.
 	// Using the "List merge requests" endpoint might be faster, as it allows us to fetch 100 arbitrary MRs per request,
@@ -174,20 +222,40 @@ func (g *GitLab) prForCommit(ctx context.Context, commit git.Commit) (*git.PullR
 		return nil, err
 	}
 
-	var mergeRequest *gitlab.MergeRequest
+	mergedMRs := make([]forge.MergedPullRequest, 0, len(associatedMRs))
 	for _, mr := range associatedMRs {
-		// We only look for the MR that has this commit set as the "merge/squash commit" => The result of squashing this branch onto main
-		if mr.MergeCommitSHA == commit.Hash || mr.SquashCommitSHA == commit.Hash {
-			mergeRequest = mr
-			break
+		mergedMRs = append(mergedMRs, gitlabMRToMergedPullRequest(mr))
+	}
+
+	if i := forge.MatchPullRequest(commit.Hash, g.options.BaseBranch, releaseCommits, mergedMRs); i >= 0 {
+		return gitlabMRToPullRequest(associatedMRs[i]), nil
+	}
+
+	return g.prForCommitMessage(ctx, commit)
+}
+
+// prForCommitMessage looks up the merge request referenced in the commit message, e.g. "feat: foo (!123)".
+func (g *GitLab) prForCommitMessage(ctx context.Context, commit git.Commit) (*git.PullRequest, error) {
+	id := forge.PullRequestReference(commit.Message, pullRequestReferenceRegexes...)
+	if id == 0 {
+		return nil, nil
+	}
+
+	g.log.DebugContext(ctx, "fetching merge request referenced in commit message", "commit.hash", commit.Hash, "pr.id", id)
+
+	mr, resp, err := g.client.MergeRequests.GetMergeRequest(g.options.Path, id, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && resp.StatusCode == nethttp.StatusNotFound {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	if mergeRequest == nil {
+	if mr.State != PRStateMerged {
 		return nil, nil
 	}
 
-	return gitlabMRToPullRequest(mergeRequest), nil
+	return gitlabMRToPullRequest(mr), nil
 }
 
 func (g *GitLab) EnsureLabelsExist(ctx context.Context, labels []releasepr.Label) error {
@@ -244,6 +312,54 @@ func (g *GitLab) PullRequestForBranch(ctx context.Context, branch string) (*rele
 	return nil, nil
 }
 
+func (g *GitLab) PullRequestComments(ctx context.Context, pr *releasepr.ReleasePullRequest) ([]string, error) {
+	notes, err := all(func(listOptions gitlab.ListOptions) ([]*gitlab.Note, *gitlab.Response, error) {
+		return g.client.Notes.ListMergeRequestNotes(g.options.Path, pr.ID, &gitlab.ListMergeRequestNotesOptions{
+			OrderBy:     pointer.Pointer("created_at"),
+			Sort:        pointer.Pointer("asc"),
+			ListOptions: listOptions,
+		}, gitlab.WithContext(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the access level of every author, to avoid looking them up for every note
+	canWrite := map[int]bool{}
+
+	comments := make([]string, 0, len(notes))
+	for _, note := range notes {
+		if note.System {
+			continue
+		}
+
+		allowed, ok := canWrite[note.Author.ID]
+		if !ok {
+			member, resp, err := g.client.ProjectMembers.GetInheritedProjectMember(g.options.Path, note.Author.ID, gitlab.WithContext(ctx))
+			if err != nil && resp != nil && (resp.StatusCode == nethttp.StatusUnauthorized || resp.StatusCode == nethttp.StatusForbidden) {
+				// The CI_JOB_TOKEN can not read the project members. Ignore all comments, anyone could write them.
+				g.log.WarnContext(ctx, "ignoring all comments on the merge request, the token can not read the project members to check the access level of their authors, configure a project or group access token", "err", err)
+				return nil, nil
+			}
+			if err != nil && (resp == nil || resp.StatusCode != nethttp.StatusNotFound) {
+				return nil, err
+			}
+
+			allowed = member != nil && member.AccessLevel >= gitlab.DeveloperPermissions
+			canWrite[note.Author.ID] = allowed
+		}
+
+		if !allowed {
+			g.log.DebugContext(ctx, "ignoring note by user without write access", "note.id", note.ID, "note.author", note.Author.Username)
+			continue
+		}
+
+		comments = append(comments, note.Body)
+	}
+
+	return comments, nil
+}
+
 func (g *GitLab) CreatePullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
 	labels := make(gitlab.LabelOptions, 0, len(pr.Labels))
 	for _, label := range pr.Labels {
@@ -336,7 +452,51 @@ func (g *GitLab) PendingReleases(ctx context.Context, pendingLabel releasepr.Lab
 	return prs, nil
 }
 
-func (g *GitLab) CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, _, _ bool) error {
+func (g *GitLab) OpenPullRequests(ctx context.Context, headPrefix string) ([]*releasepr.ReleasePullRequest, error) {
+	glMRs, err := all(func(listOptions gitlab.ListOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		return g.client.MergeRequests.ListProjectMergeRequests(g.options.Path, &gitlab.ListProjectMergeRequestsOptions{
+			State:       pointer.Pointer(PRStateOpen),
+			ListOptions: listOptions,
+		}, gitlab.WithContext(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]*releasepr.ReleasePullRequest, 0, len(glMRs))
+	for _, mr := range glMRs {
+		// Branches from forks are not considered, as the branch is always pushed to the project itself.
+		if mr.SourceProjectID != mr.TargetProjectID || !strings.HasPrefix(mr.SourceBranch, headPrefix) {
+			continue
+		}
+
+		prs = append(prs, gitlabMRToReleasePullRequest(mr))
+	}
+
+	return prs, nil
+}
+
+func (g *GitLab) CommentOnPullRequest(ctx context.Context, pr *releasepr.ReleasePullRequest, comment string) error {
+	_, _, err := g.client.Notes.CreateMergeRequestNote(g.options.Path, pr.ID, &gitlab.CreateMergeRequestNoteOptions{
+		Body: &comment,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *GitLab) DeleteBranch(ctx context.Context, branch string) error {
+	_, err := g.client.Branches.DeleteBranch(g.options.Path, branch, gitlab.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *GitLab) CreateRelease(ctx context.Context, commit git.Commit, title, changelog string, prerelease, _ bool) (forge.Release, error) {
 	_, _, err := g.client.Releases.CreateRelease(g.options.Path, &gitlab.CreateReleaseOptions{
 		Name:        &title,
 		TagName:     &title,
@@ -344,7 +504,69 @@ func (g *GitLab) CreateRelease(ctx context.Context, commit git.Commit, title, ch
 		Ref:         &commit.Hash,
 	}, gitlab.WithContext(ctx))
 	if err != nil {
-		return err
+		return forge.Release{}, err
+	}
+
+	return forge.Release{
+		TagName:    title,
+		URL:        g.ReleaseURL(title),
+		Changelog:  changelog,
+		Prerelease: prerelease,
+	}, nil
+}
+
+func (g *GitLab) FindRelease(ctx context.Context, tagName string) (*forge.Release, error) {
+	release, resp, err := g.client.Releases.GetRelease(g.options.Path, tagName, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && resp.StatusCode == nethttp.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &forge.Release{
+		TagName:   tagName,
+		URL:       g.ReleaseURL(tagName),
+		Changelog: release.Description,
+	}, nil
+}
+
+func (g *GitLab) LastMergedPullRequest(ctx context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
+	mrs, _, err := g.client.MergeRequests.ListProjectMergeRequests(g.options.Path, &gitlab.ListProjectMergeRequestsOptions{
+		State:        pointer.Pointer(PRStateMerged),
+		SourceBranch: pointer.Pointer(branch),
+		TargetBranch: pointer.Pointer(g.options.BaseBranch),
+		OrderBy:      pointer.Pointer("merged_at"),
+		Sort:         pointer.Pointer("desc"),
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: 1,
+		},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mrs) >= 1 {
+		return gitlabMRToReleasePullRequest(mrs[0]), nil
+	}
+
+	return nil, nil
+}
+
+// UploadReleaseAsset uploads the file to the project and links it as an asset of the release.
+func (g *GitLab) UploadReleaseAsset(ctx context.Context, release forge.Release, name string, file *os.File) error {
+	upload, _, err := g.client.Projects.UploadFile(g.options.Path, file, name, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	_, _, err = g.client.ReleaseLinks.CreateReleaseLink(g.options.Path, release.TagName, &gitlab.CreateReleaseLinkOptions{
+		Name: &name,
+		URL:  pointer.Pointer(g.RepoURL() + upload.URL),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to link file to release: %w", err)
 	}
 
 	return nil
@@ -369,14 +591,92 @@ func all[T any](f func(listOptions gitlab.ListOptions) ([]T, *gitlab.Response, e
 	}
 }
 
+func (g *GitLab) MergedPullRequestCount(ctx context.Context, login string) (int, error) {
+	_, resp, err := g.client.MergeRequests.ListProjectMergeRequests(g.options.Path, &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions:    gitlab.ListOptions{PerPage: 1},
+		State:          pointer.Pointer(PRStateMerged),
+		AuthorUsername: pointer.Pointer(login),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.TotalItems, nil
+}
+
+func (g *GitLab) CreateCommit(ctx context.Context, branch, parent, message string, files []git.FileChange) (git.Commit, error) {
+	actions := make([]*gitlab.CommitActionOptions, 0, len(files))
+	for _, file := range files {
+		if file.Deleted {
+			actions = append(actions, &gitlab.CommitActionOptions{
+				Action:   pointer.Pointer(gitlab.FileDelete),
+				FilePath: pointer.Pointer(file.Path),
+			})
+			continue
+		}
+
+		action := gitlab.FileUpdate
+		if file.Created {
+			action = gitlab.FileCreate
+		}
+
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:          pointer.Pointer(action),
+			FilePath:        pointer.Pointer(file.Path),
+			Content:         pointer.Pointer(base64.StdEncoding.EncodeToString(file.Content)),
+			Encoding:        pointer.Pointer("base64"),
+			ExecuteFilemode: pointer.Pointer(file.Executable),
+		})
+	}
+
+	// The commit is based on the parent and replaces the branch, same as a force push.
+	commit, _, err := g.client.Commits.CreateCommit(g.options.Path, &gitlab.CreateCommitOptions{
+		Branch:        pointer.Pointer(branch),
+		CommitMessage: pointer.Pointer(message),
+		StartSHA:      pointer.Pointer(parent),
+		Actions:       actions,
+		Force:         pointer.Pointer(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return git.Commit{}, err
+	}
+
+	return git.Commit{Hash: commit.ID, Message: message}, nil
+}
+
 func gitlabMRToPullRequest(pr *gitlab.MergeRequest) *git.PullRequest {
+	var author string
+	if pr.Author != nil {
+		author = pr.Author.Username
+	}
+
 	return &git.PullRequest{
 		ID:          pr.IID,
 		Title:       pr.Title,
 		Description: pr.Description,
+		Labels:      pr.Labels,
+		Author:      author,
+		Branch:      pr.SourceBranch,
 	}
 }
 
+func gitlabMRToMergedPullRequest(mr *gitlab.MergeRequest) forge.MergedPullRequest {
+	merged := forge.MergedPullRequest{
+		Merged:     mr.State == PRStateMerged,
+		BaseBranch: mr.TargetBranch,
+	}
+
+	switch {
+	case mr.MergeCommitSHA != "" || mr.SquashCommitSHA != "":
+		merged.MergeCommits = []string{mr.MergeCommitSHA, mr.SquashCommitSHA}
+	case merged.Merged:
+		// Fast-forward merges do not create a new commit, the head of the source branch is added to the target branch
+		merged.MergeCommits = []string{mr.SHA}
+	}
+
+	return merged
+}
+
 func gitlabMRToReleasePullRequest(pr *gitlab.MergeRequest) *releasepr.ReleasePullRequest {
 	labels := make([]releasepr.Label, 0, len(pr.Labels))
 	for _, labelName := range pr.Labels {
@@ -399,7 +699,8 @@ func gitlabMRToReleasePullRequest(pr *gitlab.MergeRequest) *releasepr.ReleasePul
 		PullRequest: *gitlabMRToPullRequest(pr),
 		Labels:      labels,
 
-		Head:          pr.SHA,
+		Head:          pr.SourceBranch,
+		Base:          pr.TargetBranch,
 		ReleaseCommit: releaseCommit,
 	}
 }
@@ -412,6 +713,13 @@ func (g *Options) autodiscover() {
 
 	if apiToken := os.Getenv(EnvAPIToken); apiToken != "" {
 		g.APIToken = apiToken
+		g.JobToken = false
+	} else if token := os.Getenv(EnvToken); token != "" && g.APIToken == "" {
+		g.APIToken = token
+	} else if jobToken := os.Getenv(EnvJobToken); jobToken != "" && g.APIToken == "" {
+		// The job token can only access some endpoints, but it does not need any setup
+		g.APIToken = jobToken
+		g.JobToken = true
 	}
 
 	if projectURL := os.Getenv(EnvProjectURL); projectURL != "" {
@@ -422,6 +730,19 @@ func (g *Options) autodiscover() {
 		g.Path = projectPath
 	}
 
+	if serverURL := strings.TrimSuffix(os.Getenv(EnvServerURL), "/"); serverURL != "" {
+		// Self-managed instances that do not set the more specific variables
+		if g.APIURL == "" {
+			g.APIURL = serverURL + "/api/v4"
+		}
+		if g.ProjectURL == "" && g.Path != "" {
+			g.ProjectURL = serverURL + "/" + g.Path
+		}
+	}
+
+	if defaultBranch := os.Getenv(EnvDefaultBranch); defaultBranch != "" && g.BaseBranch == "" {
+		g.BaseBranch = defaultBranch
+	}
 }
 
 func (g *Options) ClientOptions() []gitlab.ClientOptionFunc {
@@ -442,13 +763,24 @@ type Options struct {
 
 	APIURL   string
 	APIToken string
+	// JobToken is set if the APIToken is a CI/CD job token, which uses a different authentication.
+	JobToken bool
 }
 
 func New(log *slog.Logger, options *Options) (*GitLab, error) {
 	log = log.With("forge", "gitlab")
 	options.autodiscover()
 
-	client, err := gitlab.NewClient(options.APIToken, options.ClientOptions()...)
+	clientOptions := append(options.ClientOptions(), gitlab.WithHTTPClient(&nethttp.Client{
+		Transport: telemetry.Transport("gitlab", nil),
+	}))
+
+	newClient := gitlab.NewClient
+	if options.JobToken {
+		newClient = gitlab.NewJobClient
+	}
+
+	client, err := newClient(options.APIToken, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -462,3 +794,8 @@ func New(log *slog.Logger, options *Options) (*GitLab, error) {
 
 	return gl, nil
 }
+
+// InCI reports if releaser-pleaser is running in a GitLab CI/CD job.
+func InCI() bool {
+	return os.Getenv(EnvCI) == "true"
+}
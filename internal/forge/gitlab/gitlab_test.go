@@ -0,0 +1,92 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+func TestGitLab_PullRequestComments(t *testing.T) {
+	for _, env := range []string{EnvAPIURL, EnvAPIToken, EnvToken, EnvJobToken, EnvProjectURL, EnvProjectPath, EnvServerURL} {
+		t.Setenv(env, "")
+	}
+
+	notes := `[
+		{"id": 1, "body": "/rp skip", "author": {"id": 10, "username": "guest"}},
+		{"id": 2, "body": "changed the description", "system": true, "author": {"id": 20, "username": "maintainer"}},
+		{"id": 3, "body": "/rp set-version v2.0.0", "author": {"id": 20, "username": "maintainer"}},
+		{"id": 4, "body": "/rp skip", "author": {"id": 30, "username": "outsider"}},
+		{"id": 5, "body": "/rp prerelease", "author": {"id": 20, "username": "maintainer"}}
+	]`
+
+	tests := []struct {
+		name    string
+		members map[string]int
+		status  int
+		want    []string
+	}{
+		{
+			name:    "access levels",
+			members: map[string]int{"10": int(gitlab.GuestPermissions), "20": int(gitlab.MaintainerPermissions)},
+			want:    []string{"/rp set-version v2.0.0", "/rp prerelease"},
+		},
+		{
+			name:   "token can not read members",
+			status: http.StatusForbidden,
+			want:   nil,
+		},
+		{
+			name:   "unauthorized token",
+			status: http.StatusUnauthorized,
+			want:   nil,
+		},
+		{
+			name:   "other errors",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v4/projects/project/merge_requests/1/notes", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(notes))
+			})
+			mux.HandleFunc("GET /api/v4/projects/project/members/all/{id}", func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				level, ok := tt.members[r.PathValue("id")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]int{"access_level": level})
+			})
+
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			g, err := New(slog.Default(), &Options{Path: "project", APIURL: server.URL + "/api/v4", APIToken: "token"})
+			require.NoError(t, err)
+
+			got, err := g.PullRequestComments(context.Background(), &releasepr.ReleasePullRequest{PullRequest: git.PullRequest{ID: 1}})
+			if tt.status == http.StatusBadRequest {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
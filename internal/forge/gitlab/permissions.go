@@ -0,0 +1,52 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+var _ forge.PermissionChecker = &GitLab{}
+
+// CheckPermissions probes the API with read requests and with empty create requests. GitLab validates the permissions
+// of the token before the request body, so the empty requests fail with 400 if the permission is granted and nothing
+// is created.
+func (g *GitLab) CheckPermissions(ctx context.Context) ([]forge.PermissionCheck, error) {
+	projectPath := fmt.Sprintf("projects/%s", gitlab.PathEscape(g.options.Path))
+
+	return forge.RunProbes(ctx, []forge.Probe{
+		{Action: "read tags", Permission: "read_api scope, Reporter role", Do: g.probe(nethttp.MethodGet, projectPath+"/repository/tags")},
+		{Action: "read commits", Permission: "read_api scope, Reporter role", Do: g.probe(nethttp.MethodGet, projectPath+"/repository/commits")},
+		{Action: "read merge requests", Permission: "read_api scope, Reporter role", Do: g.probe(nethttp.MethodGet, projectPath+"/merge_requests")},
+		{Action: "create labels", Permission: "api scope, Reporter role", Do: g.probe(nethttp.MethodPost, projectPath+"/labels")},
+		{Action: "push branches", Permission: "api scope, Developer role", Do: g.probe(nethttp.MethodPost, projectPath+"/repository/branches")},
+		{Action: "create merge requests", Permission: "api scope, Developer role", Do: g.probe(nethttp.MethodPost, projectPath+"/merge_requests")},
+		{Action: "create releases", Permission: "api scope, Developer role", Do: g.probe(nethttp.MethodPost, projectPath+"/releases")},
+	})
+}
+
+// probe returns a forge.Probe function that requests a single item for GET requests, and sends an empty body for POST
+// requests.
+func (g *GitLab) probe(method, path string) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		var opt any = &gitlab.ListOptions{PerPage: 1}
+		if method == nethttp.MethodPost {
+			opt = struct{}{}
+		}
+
+		req, err := g.client.NewRequest(method, path, opt, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+		if err != nil {
+			return 0, err
+		}
+
+		resp, err := g.client.Do(req, nil)
+		if resp == nil || resp.Response == nil {
+			return 0, err
+		}
+		return resp.StatusCode, err
+	}
+}
@@ -0,0 +1,176 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+// ErrNotSupported is returned for actions that require a forge, e.g. creating releases.
+var ErrNotSupported = errors.New("not supported in local mode")
+
+var _ forge.Forge = &Local{}
+
+// Local is a forge for an existing clone on the local filesystem. It is used to preview the release pull request
+// before the automation is enabled: the release branch is written into the repository and the pull request is only
+// printed. Nothing is pushed to the remotes of the repository, and no releases are created.
+type Local struct {
+	options *Options
+
+	repo *git.Repository
+	path string
+	log  *slog.Logger
+}
+
+type Options struct {
+	forge.Options
+
+	// Path of the repository, or of any directory inside it.
+	Path string
+	// Preview receives the title and description of the release pull request. Defaults to io.Discard.
+	Preview io.Writer
+}
+
+func New(log *slog.Logger, options *Options) (*Local, error) {
+	log = log.With("forge", "local")
+
+	if options.Preview == nil {
+		options.Preview = io.Discard
+	}
+
+	path, err := filepath.Abs(options.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.OpenRepo(log, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		options: options,
+		repo:    repo,
+		path:    path,
+		log:     log,
+	}, nil
+}
+
+func (l *Local) RepoURL() string {
+	return l.path
+}
+
+// CloneURL is the path of the repository. The release branch is pushed back into it.
+func (l *Local) CloneURL() string {
+	return l.path
+}
+
+// ReleaseURL is empty, as there are no releases in local mode.
+func (l *Local) ReleaseURL(string) string {
+	return ""
+}
+
+// PullRequestURL is empty, as the pull request is only printed.
+func (l *Local) PullRequestURL(int) string {
+	return ""
+}
+
+func (l *Local) CompareURL(string, string) string {
+	return ""
+}
+
+func (l *Local) CommitURL(string) string {
+	return ""
+}
+
+func (l *Local) GitAuth() transport.AuthMethod {
+	return nil
+}
+
+func (l *Local) LatestTags(ctx context.Context, tagPrefix string, strategy versioning.Strategy) (git.Releases, error) {
+	l.log.DebugContext(ctx, "listing all tags in local repository")
+
+	tags, err := l.repo.Tags(ctx)
+	if err != nil {
+		return git.Releases{}, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return forge.LatestReleases(ctx, l.log, tags, tagPrefix, strategy, l.tagReachable)
+}
+
+// tagReachable checks if the tagged commit is part of the history of the local base branch.
+func (l *Local) tagReachable(ctx context.Context, tag *git.Tag) (bool, error) {
+	return l.repo.IsAncestor(ctx, tag.Hash, l.options.BaseBranch)
+}
+
+// CommitsSince returns the commits of the local base branch. Commits have no pull requests in local mode, the
+// changelog is built from the commit messages only.
+func (l *Local) CommitsSince(ctx context.Context, tag *git.Tag, path string) ([]git.Commit, error) {
+	from := ""
+	if tag != nil {
+		from = tag.Hash
+	}
+
+	l.log.DebugContext(ctx, "listing commits", "from", from, "to", l.options.BaseBranch, "path", path)
+	return l.repo.CommitsBetween(ctx, from, l.options.BaseBranch, path)
+}
+
+func (l *Local) EnsureLabelsExist(context.Context, []releasepr.Label) error {
+	return nil
+}
+
+// PullRequestForBranch always returns nil, every run previews a new pull request.
+func (l *Local) PullRequestForBranch(context.Context, string) (*releasepr.ReleasePullRequest, error) {
+	return nil, nil
+}
+
+func (l *Local) PullRequestComments(context.Context, *releasepr.ReleasePullRequest) ([]string, error) {
+	return nil, nil
+}
+
+// CreatePullRequest writes the pull request to Options.Preview.
+func (l *Local) CreatePullRequest(_ context.Context, pr *releasepr.ReleasePullRequest) error {
+	return l.preview(pr)
+}
+
+// UpdatePullRequest writes the pull request to Options.Preview.
+func (l *Local) UpdatePullRequest(_ context.Context, pr *releasepr.ReleasePullRequest) error {
+	return l.preview(pr)
+}
+
+func (l *Local) preview(pr *releasepr.ReleasePullRequest) error {
+	_, err := fmt.Fprintf(l.options.Preview, "# %s\n\nBranch: %s\n\n%s\n", pr.Title, pr.Head, pr.Description)
+	return err
+}
+
+func (l *Local) SetPullRequestLabels(context.Context, *releasepr.ReleasePullRequest, []releasepr.Label, []releasepr.Label) error {
+	return nil
+}
+
+func (l *Local) ClosePullRequest(context.Context, *releasepr.ReleasePullRequest) error {
+	return nil
+}
+
+// PendingReleases is always empty, as the release pull request can not be merged in local mode.
+func (l *Local) PendingReleases(context.Context, releasepr.Label) ([]*releasepr.ReleasePullRequest, error) {
+	return nil, nil
+}
+
+func (l *Local) CreateRelease(context.Context, git.Commit, string, string, bool, bool) (forge.Release, error) {
+	return forge.Release{}, fmt.Errorf("failed to create release: %w", ErrNotSupported)
+}
+
+func (l *Local) UploadReleaseAsset(context.Context, forge.Release, string, *os.File) error {
+	return fmt.Errorf("failed to upload release asset: %w", ErrNotSupported)
+}
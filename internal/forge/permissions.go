@@ -0,0 +1,69 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// PermissionCheck is the result of checking that the token can perform one action of releaser-pleaser.
+type PermissionCheck struct {
+	// Action that requires the permission, e.g. "create pull requests".
+	Action string
+	// Permission is the scope or permission that the token needs on the forge, e.g. "pull-requests: write".
+	Permission string
+	// Err is set if the permission is missing. It contains the response of the forge.
+	Err error
+}
+
+// PermissionChecker is implemented by forges that can verify the permissions of the token without changing anything.
+// It is used to report missing permissions before a run modifies the repository.
+type PermissionChecker interface {
+	// CheckPermissions checks every action of releaser-pleaser on the repository. It returns an error if the checks
+	// could not be completed, e.g. because the forge is not reachable.
+	CheckPermissions(ctx context.Context) ([]PermissionCheck, error)
+}
+
+// Probe is a request that checks a single permission, see RunProbes.
+type Probe struct {
+	Action     string
+	Permission string
+	// Do sends the request and returns the status code of the response, or 0 if there was no response. Probes for
+	// write permissions send invalid requests on purpose, so the forge rejects them after checking the permission and
+	// nothing is changed.
+	Do func(ctx context.Context) (int, error)
+}
+
+// RunProbes sends the probes one after another. The status code of a probe decides the result: successful responses
+// and validation errors (400, 409 and 422) mean that the permission is granted, as the request passed the permission
+// check. 401, 403 and 404 mean that the permission is missing. Other status codes abort the checks with an error.
+func RunProbes(ctx context.Context, probes []Probe) ([]PermissionCheck, error) {
+	checks := make([]PermissionCheck, 0, len(probes))
+	for _, probe := range probes {
+		check := PermissionCheck{Action: probe.Action, Permission: probe.Permission}
+
+		statusCode, err := probe.Do(ctx)
+		switch {
+		case statusCode >= 200 && statusCode < 300,
+			statusCode == http.StatusBadRequest,
+			statusCode == http.StatusConflict,
+			statusCode == http.StatusUnprocessableEntity:
+		case statusCode == http.StatusUnauthorized,
+			statusCode == http.StatusForbidden,
+			statusCode == http.StatusNotFound:
+			check.Err = err
+			if check.Err == nil {
+				check.Err = fmt.Errorf("forge returned status %d", statusCode)
+			}
+		default:
+			if err == nil {
+				err = fmt.Errorf("unexpected status %d", statusCode)
+			}
+			return nil, fmt.Errorf("failed to check permission to %s: %w", probe.Action, err)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,72 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunProbes(t *testing.T) {
+	probe := func(statusCode int, err error) Probe {
+		return Probe{
+			Action:     "create pull requests",
+			Permission: "pull-requests: write",
+			Do:         func(context.Context) (int, error) { return statusCode, err },
+		}
+	}
+
+	tests := []struct {
+		name       string
+		probe      Probe
+		wantDenied bool
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "success",
+			probe:   probe(200, nil),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "validation error",
+			probe:   probe(422, errors.New("validation failed")),
+			wantErr: assert.NoError,
+		},
+		{
+			name:       "forbidden",
+			probe:      probe(403, errors.New("resource not accessible by integration")),
+			wantDenied: true,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "not found without error",
+			probe:      probe(404, nil),
+			wantDenied: true,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:    "server error",
+			probe:   probe(502, errors.New("bad gateway")),
+			wantErr: assert.Error,
+		},
+		{
+			name:    "no response",
+			probe:   probe(0, errors.New("connection refused")),
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := RunProbes(context.Background(), []Probe{tt.probe})
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+
+			assert.Len(t, checks, 1)
+			assert.Equal(t, "create pull requests", checks[0].Action)
+			assert.Equal(t, "pull-requests: write", checks[0].Permission)
+			assert.Equal(t, tt.wantDenied, checks[0].Err != nil)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package forge
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency is the number of requests that are made at the same time to look up the pull requests of commits.
+const DefaultConcurrency = 5
+
+// ForEach calls fn for every index in [0, n), with at most concurrency calls running at the same time. Values below 1
+// run the calls one after another. fn should store its result by index, so the order of the results does not depend
+// on the scheduling.
+//
+// The first error cancels the context passed to the other calls, no new calls are started and the error is returned.
+func ForEach(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(concurrency, 1), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := fn(ctx, i); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+send:
+	for i := range n {
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			break send
+		case indices <- i:
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	return context.Cause(ctx)
+}
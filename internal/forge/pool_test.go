@@ -0,0 +1,103 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name        string
+		n           int
+		concurrency int
+		failAt      int
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "no items",
+			n:           0,
+			concurrency: 5,
+			failAt:      -1,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "sequential",
+			n:           10,
+			concurrency: 1,
+			failAt:      -1,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "concurrent",
+			n:           100,
+			concurrency: 5,
+			failAt:      -1,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "invalid concurrency",
+			n:           10,
+			concurrency: 0,
+			failAt:      -1,
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "error",
+			n:           100,
+			concurrency: 5,
+			failAt:      3,
+			wantErr:     assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			results := make([]int, tt.n)
+
+			err := ForEach(context.Background(), tt.n, tt.concurrency, func(ctx context.Context, i int) error {
+				current := running.Add(1)
+				defer running.Add(-1)
+				for {
+					previous := maxRunning.Load()
+					if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+
+				if i == tt.failAt {
+					return errors.New("failed")
+				}
+
+				results[i] = i * i
+				return nil
+			})
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.LessOrEqual(t, maxRunning.Load(), int32(max(tt.concurrency, 1)))
+			if err == nil {
+				for i, result := range results {
+					assert.Equal(t, i*i, result)
+				}
+			}
+		})
+	}
+}
+
+func TestForEach_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	err := ForEach(ctx, 10, 5, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, calls.Load())
+}
@@ -0,0 +1,102 @@
+package forge
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MergedPullRequest describes a pull request that is associated with a commit, as returned by the API of the forge.
+type MergedPullRequest struct {
+	// MergeCommits are the commits that were added to the base branch by merging the pull request: the merge commit,
+	// the squash commit or the last of the rebased commits. Forges may return abbreviated hashes.
+	MergeCommits []string
+	// Merged is false for open or closed pull requests.
+	Merged bool
+	// BaseBranch is the branch the pull request was merged into.
+	BaseBranch string
+}
+
+// MatchPullRequest returns the index of the pull request that added the commit to the base branch, or -1 if none of
+// the pull requests match. It supports all merge strategies:
+//
+//   - Squash: the commit is the merge commit of the pull request.
+//   - Merge commit: the commit is part of the pull request branch, the merge commit is part of the release.
+//   - Rebase: the commit is one of the rebased commits, the last of them is part of the release.
+//
+// releaseCommits are the hashes of all commits in the release, regardless of any path filters.
+func MatchPullRequest(commit, baseBranch string, releaseCommits []string, prs []MergedPullRequest) int {
+	isCommit := func(hash string) func(string) bool {
+		return func(abbreviated string) bool {
+			return abbreviated != "" && strings.HasPrefix(hash, abbreviated)
+		}
+	}
+
+	// The commit created by the merge is the most specific match, regardless of the merge strategy.
+	if i := slices.IndexFunc(prs, func(pr MergedPullRequest) bool {
+		return slices.ContainsFunc(pr.MergeCommits, isCommit(commit))
+	}); i >= 0 {
+		return i
+	}
+
+	return slices.IndexFunc(prs, func(pr MergedPullRequest) bool {
+		if !pr.Merged || pr.BaseBranch != baseBranch {
+			return false
+		}
+
+		return slices.ContainsFunc(pr.MergeCommits, func(mergeCommit string) bool {
+			return slices.ContainsFunc(releaseCommits, func(releaseCommit string) bool {
+				return isCommit(releaseCommit)(mergeCommit)
+			})
+		})
+	})
+}
+
+// PullRequestReference returns the pull request number from the first line of the commit message. The patterns
+// must have a single capture group for the number, e.g. `\(#(\d+)\)$` for the suffix GitHub adds to squashed commits.
+// It returns 0 if no pattern matches.
+func PullRequestReference(message string, patterns ...*regexp.Regexp) int {
+	subject, _, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	for _, pattern := range patterns {
+		if match := pattern.FindStringSubmatch(subject); match != nil {
+			if id, err := strconv.Atoi(match[1]); err == nil {
+				return id
+			}
+		}
+	}
+
+	return 0
+}
+
+var numberRegex = regexp.MustCompile(`\d+`)
+
+// PullRequestReferences returns the pull request numbers from the first line of the commit message of a batch merge,
+// e.g. by a merge queue or a bot like bors, that merged multiple pull requests with a single commit. The patterns must
+// have a single capture group for the list of references, every number in it is returned. It returns nil if no pattern
+// matches, or if the commit only references a single pull request.
+func PullRequestReferences(message string, patterns ...*regexp.Regexp) []int {
+	subject, _, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	for _, pattern := range patterns {
+		match := pattern.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+
+		var ids []int
+		for _, number := range numberRegex.FindAllString(match[1], -1) {
+			if id, err := strconv.Atoi(number); err == nil && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 1 {
+			return ids
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package forge
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPullRequest(t *testing.T) {
+	releaseCommits := []string{"aaaaaaaa", "bbbbbbbb", "cccccccc"}
+
+	tests := []struct {
+		name   string
+		commit string
+		prs    []MergedPullRequest
+		want   int
+	}{
+		{
+			name:   "no pull requests",
+			commit: "aaaaaaaa",
+			prs:    nil,
+			want:   -1,
+		},
+		{
+			name:   "squash",
+			commit: "aaaaaaaa",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"dddddddd"}, Merged: true, BaseBranch: "main"},
+				{MergeCommits: []string{"aaaaaaaa"}, Merged: true, BaseBranch: "main"},
+			},
+			want: 1,
+		},
+		{
+			name:   "abbreviated merge commit",
+			commit: "aaaaaaaa",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"aaaa"}, Merged: true, BaseBranch: "main"},
+			},
+			want: 0,
+		},
+		{
+			name:   "merge commit in release",
+			commit: "bbbbbbbb",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"cccccccc"}, Merged: true, BaseBranch: "main"},
+			},
+			want: 0,
+		},
+		{
+			name:   "merge commit not in release",
+			commit: "bbbbbbbb",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"dddddddd"}, Merged: true, BaseBranch: "main"},
+			},
+			want: -1,
+		},
+		{
+			name:   "merged into other branch",
+			commit: "bbbbbbbb",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"cccccccc"}, Merged: true, BaseBranch: "release-1.x"},
+			},
+			want: -1,
+		},
+		{
+			name:   "not merged",
+			commit: "bbbbbbbb",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"cccccccc"}, Merged: false, BaseBranch: "main"},
+			},
+			want: -1,
+		},
+		{
+			name:   "empty merge commit",
+			commit: "bbbbbbbb",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{""}, Merged: true, BaseBranch: "main"},
+			},
+			want: -1,
+		},
+		{
+			name:   "prefers merge commit",
+			commit: "cccccccc",
+			prs: []MergedPullRequest{
+				{MergeCommits: []string{"aaaaaaaa"}, Merged: true, BaseBranch: "main"},
+				{MergeCommits: []string{"cccccccc"}, Merged: true, BaseBranch: "main"},
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPullRequest(tt.commit, "main", releaseCommits, tt.prs))
+		})
+	}
+}
+
+func TestPullRequestReference(t *testing.T) {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`\(#(\d+)\)$`),
+		regexp.MustCompile(`^Merge pull request #(\d+) from `),
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    int
+	}{
+		{
+			name:    "squash suffix",
+			message: "feat: foo (#123)",
+			want:    123,
+		},
+		{
+			name:    "squash suffix with body",
+			message: "feat: foo (#123)\n\nSome details (#456)\n",
+			want:    123,
+		},
+		{
+			name:    "merge commit",
+			message: "Merge pull request #42 from foo/bar\n\nfeat: foo",
+			want:    42,
+		},
+		{
+			name:    "reference in body only",
+			message: "feat: foo\n\nCloses (#123)",
+			want:    0,
+		},
+		{
+			name:    "no reference",
+			message: "feat: foo",
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PullRequestReference(tt.message, patterns...))
+		})
+	}
+}
+
+func TestPullRequestReferences(t *testing.T) {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`^Merge((?: #\d+)+)$`),
+		regexp.MustCompile(`^Merge pull requests? .*\((#\d+(?:, #\d+)+)\)$`),
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    []int
+	}{
+		{
+			name:    "bors",
+			message: "Merge #12 #34 #56\n\n12: feat: foo r=alice a=bob\n\n34: fix: bar r=alice a=carol\n",
+			want:    []int{12, 34, 56},
+		},
+		{
+			name:    "merge queue",
+			message: "Merge pull requests from merge queue (#12, #34)",
+			want:    []int{12, 34},
+		},
+		{
+			name:    "multiple suffixes",
+			message: "feat: foo (#12) (#34)",
+			want:    nil,
+		},
+		{
+			name:    "list in squashed commit",
+			message: "chore: merge queue batch (#12, #34)",
+			want:    nil,
+		},
+		{
+			name:    "duplicate references",
+			message: "Merge pull requests (#12, #12)",
+			want:    nil,
+		},
+		{
+			name:    "single pull request",
+			message: "Merge #12",
+			want:    nil,
+		},
+		{
+			name:    "squash suffix",
+			message: "feat: foo (#12)",
+			want:    nil,
+		},
+		{
+			name:    "references in body only",
+			message: "feat: foo\n\nMerge #12 #34",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PullRequestReferences(tt.message, patterns...))
+		})
+	}
+}
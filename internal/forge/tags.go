@@ -0,0 +1,58 @@
+package forge
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+// LatestReleases selects the releases for Forge.LatestTags from all tags of the repository, in any order. Tags
+// without the prefix or with a version that the strategy does not support are ignored, e.g. nightly builds or the
+// tags of other packages. The remaining tags are checked from the highest to the lowest version until the highest
+// stable version that is reachable from the base branch is found.
+func LatestReleases(ctx context.Context, logger *slog.Logger, tags []git.Tag, tagPrefix string, strategy versioning.Strategy, reachable func(context.Context, *git.Tag) (bool, error)) (git.Releases, error) {
+	candidates := make([]*git.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.Name, tagPrefix) {
+			continue
+		}
+
+		if !strategy.IsVersion(strings.TrimPrefix(tag.Name, tagPrefix)) {
+			logger.WarnContext(ctx, "unable to parse tag as version, skipping", "tag.name", tag.Name, "tag.hash", tag.Hash)
+			continue
+		}
+
+		candidates = append(candidates, &tag)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *git.Tag) int {
+		return strategy.Compare(strings.TrimPrefix(b.Name, tagPrefix), strings.TrimPrefix(a.Name, tagPrefix))
+	})
+
+	var releases git.Releases
+	for _, tag := range candidates {
+		ok, err := reachable(ctx, tag)
+		if err != nil {
+			return git.Releases{}, err
+		}
+		if !ok {
+			logger.DebugContext(ctx, "tag is not reachable from base branch, skipping", "tag.name", tag.Name, "tag.hash", tag.Hash)
+			continue
+		}
+
+		if releases.Latest == nil {
+			releases.Latest = tag
+		}
+		if !strategy.IsPrerelease(strings.TrimPrefix(tag.Name, tagPrefix)) {
+			// The lower versions are not needed once the latest stable version is found.
+			releases.Stable = tag
+			break
+		}
+	}
+
+	return releases, nil
+}
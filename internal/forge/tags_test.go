@@ -0,0 +1,97 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+func TestLatestReleases(t *testing.T) {
+	tags := []git.Tag{
+		{Hash: "aaa", Name: "v1.2.0"},
+		{Hash: "bbb", Name: "nightly"},
+		{Hash: "ccc", Name: "v1.10.0-rc.1"},
+		{Hash: "ddd", Name: "api/v3.0.0"},
+		{Hash: "eee", Name: "v1.9.0"},
+		{Hash: "fff", Name: "v2.0.0"},
+		{Hash: "ggg", Name: "v1.10.0-beta.1"},
+	}
+
+	tests := []struct {
+		name        string
+		tags        []git.Tag
+		tagPrefix   string
+		unreachable []string
+		want        git.Releases
+		wantChecked []string
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "no tags",
+			want:    git.Releases{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:        "highest version",
+			tags:        tags,
+			tagPrefix:   "v",
+			want:        git.Releases{Latest: &git.Tag{Hash: "fff", Name: "v2.0.0"}, Stable: &git.Tag{Hash: "fff", Name: "v2.0.0"}},
+			wantChecked: []string{"v2.0.0"},
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "skips unreachable tags",
+			tags:        tags,
+			tagPrefix:   "v",
+			unreachable: []string{"v2.0.0"},
+			want:        git.Releases{Latest: &git.Tag{Hash: "ccc", Name: "v1.10.0-rc.1"}, Stable: &git.Tag{Hash: "eee", Name: "v1.9.0"}},
+			wantChecked: []string{"v2.0.0", "v1.10.0-rc.1", "v1.10.0-beta.1", "v1.9.0"},
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "prefix",
+			tags:        tags,
+			tagPrefix:   "api/v",
+			want:        git.Releases{Latest: &git.Tag{Hash: "ddd", Name: "api/v3.0.0"}, Stable: &git.Tag{Hash: "ddd", Name: "api/v3.0.0"}},
+			wantChecked: []string{"api/v3.0.0"},
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "nothing reachable",
+			tags:        tags,
+			tagPrefix:   "api/v",
+			unreachable: []string{"api/v3.0.0"},
+			want:        git.Releases{},
+			wantChecked: []string{"api/v3.0.0"},
+			wantErr:     assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checked []string
+			got, err := LatestReleases(context.Background(), slog.Default(), tt.tags, tt.tagPrefix, versioning.SemVer, func(_ context.Context, tag *git.Tag) (bool, error) {
+				checked = append(checked, tag.Name)
+				return !slices.Contains(tt.unreachable, tag.Name), nil
+			})
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantChecked, checked)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := LatestReleases(context.Background(), slog.Default(), tags, "v", versioning.SemVer, func(context.Context, *git.Tag) (bool, error) {
+			return false, errors.New("boom")
+		})
+		assert.Error(t, err)
+	})
+}
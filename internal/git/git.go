@@ -2,18 +2,26 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/apricote/releaser-pleaser/internal/telemetry"
 	"github.com/apricote/releaser-pleaser/internal/updater"
 )
 
@@ -25,6 +33,12 @@ const (
 type Commit struct {
 	Hash    string
 	Message string
+	// AuthorLogin is the username of the commit author on the forge. It is empty if the forge does not know the
+	// author, e.g. because the email address is not linked to an account.
+	AuthorLogin string
+	// Date is the committer date, for squashed and rebased pull requests the time they were merged. It is zero if
+	// the forge does not know it.
+	Date time.Time
 
 	PullRequest *PullRequest
 }
@@ -33,6 +47,15 @@ type PullRequest struct {
 	ID          int
 	Title       string
 	Description string
+
+	// LinkedIssues are references to the issues closed by the pull request, e.g. "#12" or "owner/repo#12".
+	LinkedIssues []string
+	// Labels are the names of the labels on the pull request.
+	Labels []string
+	// Author is the username of the user that opened the pull request.
+	Author string
+	// Branch is the name of the source branch of the pull request.
+	Branch string
 }
 
 type Tag struct {
@@ -45,30 +68,150 @@ type Releases struct {
 	Stable *Tag
 }
 
-func CloneRepo(ctx context.Context, logger *slog.Logger, cloneURL, branch string, auth transport.AuthMethod) (*Repository, error) {
-	dir, err := os.MkdirTemp("", "releaser-pleaser.*")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary directory for repo clone: %w", err)
-	}
+// CloneMode controls where the cloned repository is stored.
+type CloneMode string
+
+const (
+	// CloneModeDisk clones the repository into a temporary directory.
+	CloneModeDisk CloneMode = "disk"
+	// CloneModeMemory keeps the repository and worktree in memory. This is faster, but requires enough memory for the
+	// full checkout.
+	CloneModeMemory CloneMode = "memory"
+)
 
-	repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+type CloneOptions struct {
+	// Mode defaults to CloneModeDisk.
+	Mode CloneMode
+	// Depth limits the history that is fetched for every branch. 0 fetches the full history.
+	Depth int
+}
+
+func CloneRepo(ctx context.Context, logger *slog.Logger, cloneURL, branch string, auth transport.AuthMethod, options CloneOptions) (_ *Repository, err error) {
+	ctx, span := telemetry.Start(ctx, "git.CloneRepo",
+		attribute.String("clone.mode", string(options.Mode)),
+		attribute.Int("clone.depth", options.Depth),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	cloneOptions := &git.CloneOptions{
 		URL:           cloneURL,
 		RemoteName:    remoteName,
 		ReferenceName: plumbing.NewBranchReferenceName(branch),
 		SingleBranch:  false,
+		Depth:         options.Depth,
 		Auth:          auth,
-	})
+	}
+
+	var repo *git.Repository
+
+	switch options.Mode {
+	case CloneModeMemory:
+		repo, err = git.CloneContext(ctx, memory.NewStorage(), memfs.New(), cloneOptions)
+	case CloneModeDisk, "":
+		var dir string
+		dir, err = os.MkdirTemp("", "releaser-pleaser.*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory for repo clone: %w", err)
+		}
+
+		repo, err = git.PlainCloneContext(ctx, dir, false, cloneOptions)
+		if err == nil {
+			return &Repository{r: repo, logger: logger, auth: auth, dir: dir, depth: options.Depth}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown clone mode: %s", options.Mode)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
 
-	return &Repository{r: repo, logger: logger, auth: auth}, nil
+	return &Repository{r: repo, logger: logger, auth: auth, depth: options.Depth}, nil
+}
+
+// OpenRepo opens an existing repository in the directory or any of its parents.
+func OpenRepo(logger *slog.Logger, path string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	dir := ""
+	if worktree, err := repo.Worktree(); err == nil {
+		dir = worktree.Filesystem.Root()
+	}
+
+	return &Repository{r: repo, logger: logger, dir: dir}, nil
 }
 
 type Repository struct {
 	r      *git.Repository
 	logger *slog.Logger
 	auth   transport.AuthMethod
+	// dir of the worktree, empty if the worktree is only kept in memory.
+	dir string
+	// depth of a shallow clone, 0 if the full history was cloned.
+	depth int
+}
+
+// Dir returns the directory of the worktree, or an empty string for repositories cloned with CloneModeMemory.
+func (r *Repository) Dir() string {
+	return r.dir
+}
+
+// CommitsBetween returns all commits reachable from the revision "to" that are not reachable from "from", newest
+// first. If from is empty, all commits reachable from "to" are returned. If path is not empty, only commits that touch
+// files in the path are returned.
+func (r *Repository) CommitsBetween(_ context.Context, from, to, path string) ([]Commit, error) {
+	toHash, err := r.r.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %q: %w", to, err)
+	}
+
+	exclude := map[plumbing.Hash]bool{}
+	if from != "" {
+		fromHash, err := r.r.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve revision %q: %w", from, err)
+		}
+
+		fromIter, err := r.r.Log(&git.LogOptions{From: *fromHash})
+		if err != nil {
+			return nil, err
+		}
+		err = fromIter.ForEach(func(commit *object.Commit) error {
+			exclude[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logOptions := &git.LogOptions{From: *toHash}
+	if path != "" {
+		prefix := strings.TrimSuffix(path, "/") + "/"
+		logOptions.PathFilter = func(file string) bool {
+			return file == path || strings.HasPrefix(file, prefix)
+		}
+	}
+
+	iter, err := r.r.Log(logOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if !exclude[commit.Hash] {
+			commits = append(commits, Commit{Hash: commit.Hash.String(), Message: commit.Message, Date: commit.Committer.When})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
 }
 
 func (r *Repository) DeleteBranch(ctx context.Context, branch string) error {
@@ -98,7 +241,41 @@ func (r *Repository) Checkout(_ context.Context, branch string) error {
 	return nil
 }
 
-func (r *Repository) UpdateFile(_ context.Context, path string, create bool, updaters []updater.Updater) error {
+// CheckoutCommit checks out the commit without a branch, e.g. to build the assets of a release.
+func (r *Repository) CheckoutCommit(_ context.Context, commitHash string) error {
+	worktree, err := r.r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if err = worktree.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(commitHash)}); err != nil {
+		return fmt.Errorf("failed to check out commit %s: %w", commitHash, err)
+	}
+
+	return nil
+}
+
+// ReadFile returns the content of the file in the worktree. If the file does not exist, the error matches
+// fs.ErrNotExist.
+func (r *Repository) ReadFile(_ context.Context, path string) ([]byte, error) {
+	worktree, err := r.r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := worktree.Filesystem.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func (r *Repository) UpdateFile(ctx context.Context, path string, create bool, updaters []updater.Updater) (err error) {
+	_, span := telemetry.Start(ctx, "git.UpdateFile", attribute.String("file.path", path))
+	defer func() { telemetry.End(span, err) }()
+
 	worktree, err := r.r.Worktree()
 	if err != nil {
 		return err
@@ -125,7 +302,7 @@ func (r *Repository) UpdateFile(_ context.Context, path string, create bool, upd
 	for _, update := range updaters {
 		updatedContent, err = update(updatedContent)
 		if err != nil {
-			return fmt.Errorf("failed to run updater on file %s", path)
+			return fmt.Errorf("failed to run updater on file %s: %w", path, err)
 		}
 	}
 
@@ -150,15 +327,31 @@ func (r *Repository) UpdateFile(_ context.Context, path string, create bool, upd
 	return nil
 }
 
-func (r *Repository) Commit(_ context.Context, message string) (Commit, error) {
+// AddAll stages all changes in the worktree, including new and deleted files. Files matched by .gitignore are
+// skipped.
+func (r *Repository) AddAll(_ context.Context) error {
+	worktree, err := r.r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if err = worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return fmt.Errorf("failed to add changes to git worktree: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Commit(_ context.Context, message string, options CommitOptions) (Commit, error) {
 	worktree, err := r.r.Worktree()
 	if err != nil {
 		return Commit{}, err
 	}
 
 	releaseCommitHash, err := worktree.Commit(message, &git.CommitOptions{
-		Author:    signature(),
-		Committer: signature(),
+		Author:    options.signature(),
+		Committer: options.signature(),
+		Signer:    options.Signer,
 	})
 	if err != nil {
 		return Commit{}, fmt.Errorf("failed to commit changes: %w", err)
@@ -170,10 +363,79 @@ func (r *Repository) Commit(_ context.Context, message string) (Commit, error) {
 	}, nil
 }
 
+// FileChange is the content of a file that was added, modified or deleted in a commit.
+type FileChange struct {
+	Path    string
+	Content []byte
+	// Created is set if the file does not exist in the parent commit.
+	Created bool
+	// Deleted is set if the file does not exist in the commit, Content is empty.
+	Deleted    bool
+	Executable bool
+}
+
+// ChangedFiles returns the hash of the first parent of the commit and all files that the commit changed in comparison
+// to it. Renamed files are returned as a deletion of the old path and a new file.
+func (r *Repository) ChangedFiles(_ context.Context, commitHash string) (string, []FileChange, error) {
+	commit, err := r.r.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return "", nil, err
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get parent of commit %s: %w", commitHash, err)
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return "", nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", nil, err
+	}
+
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return "", nil, err
+	}
+
+	files := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return "", nil, err
+		}
+		if action == merkletrie.Delete {
+			files = append(files, FileChange{Path: change.From.Name, Deleted: true})
+			continue
+		}
+
+		_, to, err := change.Files()
+		if err != nil {
+			return "", nil, err
+		}
+		content, err := to.Contents()
+		if err != nil {
+			return "", nil, err
+		}
+
+		files = append(files, FileChange{
+			Path:       change.To.Name,
+			Content:    []byte(content),
+			Created:    action == merkletrie.Insert,
+			Executable: to.Mode == filemode.Executable,
+		})
+	}
+
+	return parent.Hash.String(), files, nil
+}
+
 func (r *Repository) HasChangesWithRemote(ctx context.Context, branch string) (bool, error) {
 	remoteRef, err := r.r.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), false)
 	if err != nil {
-		if err.Error() == "reference not found" {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
 			// No remote branch means that there are changes
 			return true, nil
 		}
@@ -224,10 +486,158 @@ func (r *Repository) ForcePush(ctx context.Context, branch string) error {
 	})
 }
 
-func signature() *object.Signature {
+// CreateTag creates an annotated tag for the commit. The commit does not need to be available in the local clone.
+func (r *Repository) CreateTag(_ context.Context, name, commitHash, message string, options CommitOptions) (Tag, error) {
+	// The signature is appended directly to the message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *options.signature(),
+		Message:    message,
+		TargetType: plumbing.CommitObject,
+		Target:     plumbing.NewHash(commitHash),
+	}
+
+	if options.Signer != nil {
+		encoded := &plumbing.MemoryObject{}
+		if err := tag.EncodeWithoutSignature(encoded); err != nil {
+			return Tag{}, err
+		}
+		reader, err := encoded.Reader()
+		if err != nil {
+			return Tag{}, err
+		}
+
+		signature, err := options.Signer.Sign(reader)
+		if err != nil {
+			return Tag{}, fmt.Errorf("failed to sign tag: %w", err)
+		}
+		tag.PGPSignature = string(signature)
+	}
+
+	obj := r.r.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return Tag{}, err
+	}
+	hash, err := r.r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return Tag{}, fmt.Errorf("failed to store tag: %w", err)
+	}
+
+	if err = r.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash)); err != nil {
+		return Tag{}, fmt.Errorf("failed to create tag reference: %w", err)
+	}
+
+	return Tag{Hash: commitHash, Name: name}, nil
+}
+
+// TagTarget returns the hash of the commit that the tag points at, or an empty string if the tag does not exist. Tags
+// of the remote are only available in the clone if they point at a fetched commit.
+func (r *Repository) TagTarget(_ context.Context, name string) (string, error) {
+	ref, err := r.r.Tag(name)
+	if errors.Is(err, git.ErrTagNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := r.r.TagObject(ref.Hash())
+	switch {
+	case err == nil:
+		return tag.Target.String(), nil
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		// Lightweight tags point at the commit directly
+		return ref.Hash().String(), nil
+	default:
+		return "", err
+	}
+}
+
+// Tags returns all tags of the repository with the commit they point at, in no particular order.
+func (r *Repository) Tags(_ context.Context) ([]Tag, error) {
+	iter, err := r.r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		// Annotated tags point at a tag object instead of the commit
+		if tag, err := r.r.TagObject(hash); err == nil {
+			hash = tag.Target
+		} else if !errors.Is(err, plumbing.ErrObjectNotFound) {
+			return err
+		}
+
+		tags = append(tags, Tag{Hash: hash.String(), Name: ref.Name().Short()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// IsAncestor returns true if the commit is part of the history of the revision, including the revision itself.
+func (r *Repository) IsAncestor(_ context.Context, commitHash, revision string) (bool, error) {
+	commit, err := r.r.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", commitHash, err)
+	}
+
+	revisionHash, err := r.r.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve revision %q: %w", revision, err)
+	}
+	revisionCommit, err := r.r.CommitObject(*revisionHash)
+	if err != nil {
+		return false, err
+	}
+
+	return commit.IsAncestor(revisionCommit)
+}
+
+func (r *Repository) PushTag(ctx context.Context, name string) error {
+	pushRefSpec := config.RefSpec(fmt.Sprintf("%[1]s:%[1]s", plumbing.NewTagReferenceName(name)))
+
+	r.logger.DebugContext(ctx, "pushing tag", "tag.name", name, "refspec", pushRefSpec.String())
+	return r.r.PushContext(ctx, &git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{pushRefSpec},
+		Auth:       r.auth,
+	})
+}
+
+// Identity is used as author and committer of release commits and as tagger of release tags.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+var DefaultIdentity = Identity{Name: "releaser-pleaser"}
+
+type CommitOptions struct {
+	// Identity defaults to DefaultIdentity.
+	Identity Identity
+	// Signer is used to sign release commits and tags. Commits and tags are not signed if it is nil.
+	Signer Signer
+}
+
+func (o CommitOptions) signature() *object.Signature {
+	identity := o.Identity
+	if identity.Name == "" {
+		identity = DefaultIdentity
+	}
+
 	return &object.Signature{
-		Name:  "releaser-pleaser",
-		Email: "",
+		Name:  identity.Name,
+		Email: identity.Email,
 		When:  time.Now(),
 	}
 }
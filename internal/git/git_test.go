@@ -0,0 +1,249 @@
+package git
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/updater"
+)
+
+func TestRepository_CommitsBetween(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	date := func(day int) time.Time { return time.Date(2024, 8, day, 12, 0, 0, 0, time.UTC) }
+	commit := func(message string, when time.Time) string {
+		hash, err := worktree.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "test", When: when}})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	first := commit("feat: first", date(1))
+	_, err = r.CreateTag("v1.0.0", plumbing.NewHash(first), nil)
+	require.NoError(t, err)
+	second := commit("fix: second", date(2))
+	third := commit("feat: third", date(3))
+
+	repo, err := OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []Commit
+	}{
+		{
+			name: "tag to head",
+			from: "v1.0.0",
+			to:   "HEAD",
+			want: []Commit{{Hash: third, Message: "feat: third", Date: date(3)}, {Hash: second, Message: "fix: second", Date: date(2)}},
+		},
+		{
+			name: "all commits",
+			from: "",
+			to:   second,
+			want: []Commit{{Hash: second, Message: "fix: second", Date: date(2)}, {Hash: first, Message: "feat: first", Date: date(1)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CommitsBetween(context.Background(), tt.from, tt.to, "")
+			require.NoError(t, err)
+			for i := range got {
+				got[i].Date = got[i].Date.UTC()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = repo.CommitsBetween(context.Background(), "v2.0.0", "HEAD", "")
+	assert.Error(t, err)
+}
+
+func TestRepository_ChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	commit := func(message string, files map[string]string) string {
+		for path, content := range files {
+			if content == "" {
+				// An empty content deletes the file
+				_, err := worktree.Remove(path)
+				require.NoError(t, err)
+				continue
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+			_, err := worktree.Add(path)
+			require.NoError(t, err)
+		}
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: "test"}})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	first := commit("feat: first", map[string]string{"CHANGELOG.md": "# Changelog\n", "version.txt": "1.0.0\n"})
+	second := commit("chore(main): release v1.1.0", map[string]string{"CHANGELOG.md": "# Changelog\n\n## v1.1.0\n", "VERSION": "1.1.0\n", "version.txt": ""})
+
+	repo, err := OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	parent, files, err := repo.ChangedFiles(context.Background(), second)
+	require.NoError(t, err)
+	assert.Equal(t, first, parent)
+	assert.ElementsMatch(t, []FileChange{
+		{Path: "CHANGELOG.md", Content: []byte("# Changelog\n\n## v1.1.0\n")},
+		{Path: "VERSION", Content: []byte("1.1.0\n"), Created: true},
+		{Path: "version.txt", Deleted: true},
+	}, files)
+
+	_, _, err = repo.ChangedFiles(context.Background(), first)
+	assert.Error(t, err, "the first commit has no parent")
+}
+
+func TestRepository_TagTarget(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit("feat: first", &git.CommitOptions{Author: &object.Signature{Name: "test"}, AllowEmptyCommits: true})
+	require.NoError(t, err)
+
+	repo, err := OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	_, err = r.CreateTag("v1.0.0", hash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag(context.Background(), "v1.1.0", hash.String(), "v1.1.0", CommitOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "lightweight", tag: "v1.0.0", want: hash.String()},
+		{name: "annotated", tag: "v1.1.0", want: hash.String()},
+		{name: "missing", tag: "v2.0.0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TagTarget(context.Background(), tt.tag)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_Tags(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	first, err := worktree.Commit("feat: first", &git.CommitOptions{Author: &object.Signature{Name: "test"}, AllowEmptyCommits: true})
+	require.NoError(t, err)
+	second, err := worktree.Commit("feat: second", &git.CommitOptions{Author: &object.Signature{Name: "test"}, AllowEmptyCommits: true})
+	require.NoError(t, err)
+
+	repo, err := OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	_, err = r.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag(context.Background(), "v1.1.0", second.String(), "v1.1.0", CommitOptions{})
+	require.NoError(t, err)
+
+	tags, err := repo.Tags(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Tag{{Hash: first.String(), Name: "v1.0.0"}, {Hash: second.String(), Name: "v1.1.0"}}, tags)
+
+	ok, err := repo.IsAncestor(context.Background(), first.String(), "HEAD")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.IsAncestor(context.Background(), second.String(), second.String())
+	require.NoError(t, err)
+	assert.True(t, ok, "a commit is its own ancestor")
+
+	ok, err = repo.IsAncestor(context.Background(), second.String(), first.String())
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCloneRepo_LocalPath(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "version.txt"), []byte("1.0.0\n"), 0o644))
+	_, err = worktree.Add("version.txt")
+	require.NoError(t, err)
+	head, err := worktree.Commit("feat: first", &git.CommitOptions{Author: &object.Signature{Name: "test"}})
+	require.NoError(t, err)
+
+	// Cloning and pushing to a path must not require the git binary.
+	repo, err := CloneRepo(context.Background(), slog.Default(), dir, "master", nil, CloneOptions{Mode: CloneModeMemory})
+	require.NoError(t, err)
+	require.NoError(t, repo.Checkout(context.Background(), "releaser-pleaser--branches--master"))
+	require.NoError(t, repo.UpdateFile(context.Background(), "version.txt", false, []updater.Updater{
+		func(string) (string, error) { return "1.1.0\n", nil },
+	}))
+	commit, err := repo.Commit(context.Background(), "chore: release", CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.ForcePush(context.Background(), "releaser-pleaser--branches--master"))
+
+	ref, err := r.Reference(plumbing.NewBranchReferenceName("releaser-pleaser--branches--master"), false)
+	require.NoError(t, err)
+	assert.Equal(t, commit.Hash, ref.Hash().String())
+
+	// The worktree of the local repository is not touched
+	current, err := r.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head, current.Hash())
+}
+
+func TestRemoteBranches(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "version.txt"), []byte("1.0.0\n"), 0o644))
+	_, err = worktree.Add("version.txt")
+	require.NoError(t, err)
+	head, err := worktree.Commit("feat: first", &git.CommitOptions{Author: &object.Signature{Name: "test"}})
+	require.NoError(t, err)
+
+	for _, branch := range []string{"releaser-pleaser--branches--main", "releaser-pleaser--branches--main--api", "feature"} {
+		require.NoError(t, r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head)))
+	}
+	_, err = r.CreateTag("releaser-pleaser--branches--tag", head, nil)
+	require.NoError(t, err)
+
+	branches, err := RemoteBranches(context.Background(), dir, nil, "releaser-pleaser--branches--")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"releaser-pleaser--branches--main", "releaser-pleaser--branches--main--api"}, branches)
+}
@@ -0,0 +1,33 @@
+package git
+
+import (
+	"errors"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+)
+
+func init() {
+	// The default transport for local paths runs the git binary, which is often not installed on Windows. The
+	// server of go-git implements the same protocol in process.
+	client.InstallProtocol("file", server.NewClient(localLoader{}))
+}
+
+// localLoader opens the repository at the path of a file:// endpoint. Unlike server.DefaultLoader, it accepts the
+// path of a worktree and not only of the .git directory.
+type localLoader struct{}
+
+func (localLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	repo, err := git.PlainOpenWithOptions(ep.Path, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, transport.ErrRepositoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.Storer, nil
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// RemoteBranches returns the sorted names of all branches in the remote repository that start with the prefix. The
+// repository is not cloned. Empty repositories have no branches.
+func RemoteBranches(ctx context.Context, url string, auth transport.AuthMethod, prefix string) ([]string, error) {
+	refs, err := newRemote(url).ListContext(ctx, &git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+
+	var branches []string
+	for _, ref := range refs {
+		if !ref.Name().IsBranch() {
+			continue
+		}
+		if name := ref.Name().Short(); strings.HasPrefix(name, prefix) {
+			branches = append(branches, name)
+		}
+	}
+	slices.Sort(branches)
+
+	return branches, nil
+}
+
+func newRemote(url string) *git.Remote {
+	return git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: remoteName, URLs: []string{url}})
+}
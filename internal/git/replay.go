@@ -0,0 +1,373 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ErrConflict is returned by ReplayCommit if the changes of the commit can not be applied to the worktree.
+var ErrConflict = errors.New("changes conflict with the worktree")
+
+// RemoteBranchCommits returns the commits of the remote branch that are not part of the remote base branch, oldest
+// first. Merge commits are skipped, e.g. when the base branch was merged into the branch, as their changes are
+// already part of the base branch. It returns nil if the remote branch does not exist.
+//
+// In a shallow clone, the history of both branches is fetched deeper until the branch point is found.
+func (r *Repository) RemoteBranchCommits(ctx context.Context, branch, base string) ([]Commit, error) {
+	branchRef, err := r.r.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	baseRef, err := r.r.Reference(plumbing.NewRemoteReferenceName(remoteName, base), false)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := r.depth
+	for {
+		commits, complete, err := r.branchCommits(branchRef.Hash(), baseRef.Hash())
+		if err != nil || complete {
+			return commits, err
+		}
+		if depth == 0 {
+			return nil, fmt.Errorf("history of branch %s is incomplete", branch)
+		}
+
+		depth *= 2
+		r.logger.DebugContext(ctx, "fetching more history to find the branch point", "branch.name", branch, "clone.depth", depth)
+		err = r.r.FetchContext(ctx, &git.FetchOptions{
+			RemoteName: remoteName,
+			RefSpecs:   []config.RefSpec{remoteRefSpec(branch), remoteRefSpec(base)},
+			Depth:      depth,
+			Auth:       r.auth,
+		})
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, fmt.Errorf("failed to find the branch point of %s and %s", branch, base)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch history of branch %s: %w", branch, err)
+		}
+	}
+}
+
+// branchCommits returns the commits reachable from branch but not from base, oldest first. Missing commits are
+// treated as the end of the history, e.g. the boundary of a shallow clone. The result is only complete if all commits
+// of the branch are available.
+func (r *Repository) branchCommits(branch, base plumbing.Hash) ([]Commit, bool, error) {
+	exclude := map[plumbing.Hash]bool{}
+	_, err := r.walkCommits(base, nil, func(commit *object.Commit) {
+		exclude[commit.Hash] = true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var commits []Commit
+	complete, err := r.walkCommits(branch, exclude, func(commit *object.Commit) {
+		if commit.NumParents() <= 1 {
+			commits = append(commits, Commit{Hash: commit.Hash.String(), Message: commit.Message})
+		}
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	slices.Reverse(commits)
+	return commits, complete, nil
+}
+
+// walkCommits calls fn for every commit reachable from the hash, newest first, and does not continue past the commits
+// in stop. It reports false if a commit is not available in the repository.
+func (r *Repository) walkCommits(hash plumbing.Hash, stop map[plumbing.Hash]bool, fn func(commit *object.Commit)) (bool, error) {
+	complete := true
+	seen := map[plumbing.Hash]bool{}
+	pending := []plumbing.Hash{hash}
+	for len(pending) > 0 {
+		hash = pending[0]
+		pending = pending[1:]
+		if seen[hash] || stop[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		commit, err := r.r.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			complete = false
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		fn(commit)
+		pending = append(pending, commit.ParentHashes...)
+	}
+
+	return complete, nil
+}
+
+func remoteRefSpec(branch string) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), plumbing.NewRemoteReferenceName(remoteName, branch)))
+}
+
+// ReplayCommit applies the changes of the commit to the worktree and commits them with the author and message of the
+// original commit. Changes to files that were also changed in the worktree are merged line by line. If they overlap,
+// the error matches ErrConflict and the worktree is left with partially applied changes. If the changes are already
+// part of the worktree, no commit is created and the returned commit is empty.
+func (r *Repository) ReplayCommit(_ context.Context, commitHash string, options CommitOptions) (Commit, error) {
+	commit, err := r.r.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return Commit{}, err
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed to get parent of commit %s: %w", commitHash, err)
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return Commit{}, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return Commit{}, err
+	}
+
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return Commit{}, err
+	}
+
+	worktree, err := r.r.Worktree()
+	if err != nil {
+		return Commit{}, err
+	}
+
+	changed := false
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return Commit{}, err
+		}
+
+		path := change.To.Name
+		if to == nil {
+			path = change.From.Name
+		}
+
+		current, err := readWorktreeFile(worktree, path)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return Commit{}, err
+		}
+
+		var oldContent, newContent string
+		if from != nil {
+			if oldContent, err = from.Contents(); err != nil {
+				return Commit{}, err
+			}
+		}
+		if to != nil {
+			if newContent, err = to.Contents(); err != nil {
+				return Commit{}, err
+			}
+		}
+
+		switch {
+		case to == nil:
+			if !exists {
+				continue
+			}
+			if current != oldContent {
+				return Commit{}, fmt.Errorf("%w: file %s was deleted, but it was changed in the worktree", ErrConflict, path)
+			}
+			if _, err = worktree.Remove(path); err != nil {
+				return Commit{}, err
+			}
+			changed = true
+			continue
+		case from == nil:
+			if exists && current != newContent {
+				return Commit{}, fmt.Errorf("%w: file %s was created, but it already exists in the worktree", ErrConflict, path)
+			}
+		case !exists:
+			return Commit{}, fmt.Errorf("%w: file %s was changed, but it does not exist in the worktree", ErrConflict, path)
+		default:
+			var ok bool
+			newContent, ok = mergeChanges(oldContent, newContent, current)
+			if !ok {
+				return Commit{}, fmt.Errorf("%w: changes to file %s overlap with the worktree", ErrConflict, path)
+			}
+		}
+
+		if exists && current == newContent {
+			continue
+		}
+
+		perm := os.FileMode(newFilePermissions)
+		if to.Mode == filemode.Executable {
+			perm = 0o755
+		}
+		if err = writeWorktreeFile(worktree, path, newContent, perm); err != nil {
+			return Commit{}, err
+		}
+		if _, err = worktree.Add(path); err != nil {
+			return Commit{}, fmt.Errorf("failed to add file to git worktree: %w", err)
+		}
+		changed = true
+	}
+
+	if !changed {
+		return Commit{}, nil
+	}
+
+	hash, err := worktree.Commit(commit.Message, &git.CommitOptions{
+		Author:    &commit.Author,
+		Committer: options.signature(),
+		Signer:    options.Signer,
+	})
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return Commit{Hash: hash.String(), Message: commit.Message}, nil
+}
+
+// mergeChanges applies the line based changes between oldContent and newContent to content. Every changed block of
+// lines must appear exactly once in content, but it may have moved, e.g. because the release commit added lines to the
+// changelog above it. Lines are only inserted next to an unchanged line that is unique in content. It returns false if
+// a change could not be applied.
+func mergeChanges(oldContent, newContent, content string) (string, bool) {
+	if content == oldContent || content == newContent {
+		return newContent, true
+	}
+
+	type hunk struct {
+		// start is the index of the first removed line in oldLines.
+		start            int
+		removed, added   []string
+		position, length int
+	}
+
+	dmp := diffmatchpatch.New()
+	oldChars, newChars, lines := dmp.DiffLinesToChars(oldContent, newContent)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(oldChars, newChars, false), lines)
+
+	oldLines := splitLines(oldContent)
+	contentLines := splitLines(content)
+
+	var hunks []*hunk
+	var current *hunk
+	index := 0
+	for _, diff := range diffs {
+		diffLines := splitLines(diff.Text)
+		if diff.Type == diffmatchpatch.DiffEqual {
+			current = nil
+			index += len(diffLines)
+			continue
+		}
+
+		if current == nil {
+			current = &hunk{start: index}
+			hunks = append(hunks, current)
+		}
+		if diff.Type == diffmatchpatch.DiffDelete {
+			current.removed = append(current.removed, diffLines...)
+			index += len(diffLines)
+		} else {
+			current.added = append(current.added, diffLines...)
+		}
+	}
+
+	end := 0
+	for _, h := range hunks {
+		var ok bool
+		switch {
+		case len(h.removed) > 0:
+			h.position, ok = findUnique(contentLines, h.removed)
+			h.length = len(h.removed)
+		case h.start > 0:
+			// Insert after the previous line, or before the next line
+			h.position, ok = findUnique(contentLines, oldLines[h.start-1:h.start])
+			h.position++
+			if !ok && h.start < len(oldLines) {
+				h.position, ok = findUnique(contentLines, oldLines[h.start:h.start+1])
+			}
+		case len(oldLines) > 0:
+			h.position, ok = findUnique(contentLines, oldLines[:1])
+		}
+		if !ok || h.position < end {
+			return "", false
+		}
+		end = h.position + h.length
+	}
+
+	for _, h := range slices.Backward(hunks) {
+		contentLines = slices.Replace(contentLines, h.position, h.position+h.length, h.added...)
+	}
+
+	return strings.Join(contentLines, ""), true
+}
+
+// splitLines splits the text after every newline. The last line does not end with a newline if the text does not.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// findUnique returns the index of the block in lines, if it appears exactly once.
+func findUnique(lines, block []string) (int, bool) {
+	position := -1
+	for i := 0; i+len(block) <= len(lines); i++ {
+		if slices.Equal(lines[i:i+len(block)], block) {
+			if position >= 0 {
+				return 0, false
+			}
+			position = i
+		}
+	}
+	return position, position >= 0
+}
+
+func readWorktreeFile(worktree *git.Worktree, path string) (string, error) {
+	file, err := worktree.Filesystem.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	return string(content), err
+}
+
+func writeWorktreeFile(worktree *git.Worktree, path, content string, perm os.FileMode) error {
+	file, err := worktree.Filesystem.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write([]byte(content))
+	return err
+}
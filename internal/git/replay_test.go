@@ -0,0 +1,146 @@
+package git
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+		content    string
+		want       string
+		wantOK     bool
+	}{
+		{
+			name:       "unchanged",
+			oldContent: "# Changelog\n\n## v1.0.0\n\n- Foo\n",
+			newContent: "# Changelog\n\n## v1.0.0\n\n- Foo bar\n",
+			content:    "# Changelog\n\n## v1.0.0\n\n- Foo\n",
+			want:       "# Changelog\n\n## v1.0.0\n\n- Foo bar\n",
+			wantOK:     true,
+		},
+		{
+			name:       "lines added above",
+			oldContent: "# Changelog\n\n## v1.1.0\n\n- Added a thing\n- Fixd a bug\n\n## v1.0.0\n\n- Initial release\n",
+			newContent: "# Changelog\n\n## v1.1.0\n\n- Added a thing\n- Fixed a bug\n\n## v1.0.0\n\n- Initial release\n",
+			content:    "# Changelog\n\n## v1.1.0\n\n- Added a thing\n- Added another thing\n- Fixd a bug\n\n## v1.0.0\n\n- Initial release\n",
+			want:       "# Changelog\n\n## v1.1.0\n\n- Added a thing\n- Added another thing\n- Fixed a bug\n\n## v1.0.0\n\n- Initial release\n",
+			wantOK:     true,
+		},
+		{
+			name:       "inserted line",
+			oldContent: "# Changelog\n\n## v1.1.0\n\n### Features\n\n- Added a thing\n",
+			newContent: "# Changelog\n\n## v1.1.0\n\n### Features\n\n- Added a thing\n- Added a thing that was missing\n",
+			content:    "# Changelog\n\n## v1.1.0\n\n### Features\n\n- Added a thing\n\n### Bug Fixes\n\n- Fixed a bug\n",
+			want:       "# Changelog\n\n## v1.1.0\n\n### Features\n\n- Added a thing\n- Added a thing that was missing\n\n### Bug Fixes\n\n- Fixed a bug\n",
+			wantOK:     true,
+		},
+		{
+			name:       "ambiguous change",
+			oldContent: "## v1.1.0\n\n- Updated dependencies\n",
+			newContent: "## v1.1.0\n\n- Updated dependency foo\n",
+			content:    "## v1.2.0\n\n- Updated dependencies\n\n## v1.1.0\n\n- Updated dependencies\n",
+			wantOK:     false,
+		},
+		{
+			name:       "overlapping change",
+			oldContent: "# Changelog\n\n## v1.1.0\n\n- Fixd a bug\n",
+			newContent: "# Changelog\n\n## v1.1.0\n\n- Fixed a bug\n",
+			content:    "# Changelog\n\n## v1.1.0\n\n- Fixd a bug in the parser\n",
+			wantOK:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeChanges(tt.oldContent, tt.newContent, tt.content)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRepository_RemoteBranchCommits_Shallow(t *testing.T) {
+	// The file transport of go-git does not support shallow clones, the repository is served by git daemon instead.
+	// It is started directly, as "git daemon" does not stop its child process.
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	r, err := git.PlainInit(filepath.Join(dir, "repo"), false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	commit := func(message string) string {
+		hash, err := worktree.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "test"}})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	for _, message := range []string{"feat: first", "feat: second", "feat: third"} {
+		commit(message)
+	}
+
+	const branch = "releaser-pleaser--branches--master"
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}))
+	release := commit("chore(master): release v1.1.0")
+	manual := commit("docs: fix typo")
+
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}))
+	for _, message := range []string{"feat: fourth", "feat: fifth", "feat: sixth"} {
+		commit(message)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	daemon := exec.Command(filepath.Join(strings.TrimSpace(string(execPath)), "git-daemon"), "--export-all", "--reuseaddr", "--listen=127.0.0.1", "--port="+strconv.Itoa(port), "--base-path="+dir, dir)
+	require.NoError(t, daemon.Start())
+	t.Cleanup(func() {
+		_ = daemon.Process.Kill()
+		_ = daemon.Wait()
+	})
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	repo, err := CloneRepo(context.Background(), slog.Default(), "git://"+listener.Addr().String()+"/repo", "master", nil, CloneOptions{Depth: 1})
+	require.NoError(t, err)
+
+	commits, err := repo.RemoteBranchCommits(context.Background(), branch, "master")
+	require.NoError(t, err)
+	assert.Equal(t, []Commit{
+		{Hash: release, Message: "chore(master): release v1.1.0"},
+		{Hash: manual, Message: "docs: fix typo"},
+	}, commits)
+
+	// The parent of the manual change is available to replay it
+	_, err = repo.ReplayCommit(context.Background(), manual, CommitOptions{})
+	assert.NoError(t, err)
+}
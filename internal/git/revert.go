@@ -0,0 +1,133 @@
+package git
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// revertedHashRegex matches the line that git revert adds to the message.
+	revertedHashRegex = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-fA-F]{7,40})\b`)
+	// revertedRefsRegex matches the footer that the Conventional Commits specification suggests for "revert:"
+	// commits, e.g. "Refs: 676104e, a215868".
+	revertedRefsRegex = regexp.MustCompile(`(?m)^Refs: ([0-9a-fA-F]{7,40}(?:, *[0-9a-fA-F]{7,40})*)\s*$`)
+	// revertSubjectRegexes match the subject of revert commits. The group is the subject of the reverted commit. The
+	// optional suffix is added by the forges when a revert is squash merged, e.g. ` (#13)`.
+	revertSubjectRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`^Revert "(.+)"(?: \([#!]\d+\)| \(pull request #\d+\))?$`),
+		regexp.MustCompile(`^revert(?:\(.+\))?: (.+)$`),
+	}
+)
+
+// DropReverted removes revert commits together with the commits they revert, if both are part of the release.
+// Otherwise, the changelog would list changes that are not actually released. Reverts of commits from earlier
+// releases are kept.
+//
+// The reverted commit is identified by the hash in the message ("This reverts commit <sha>.") or, if the message does
+// not include one, by the subject of the revert commit (`Revert "<subject>"` or `revert: <subject>`).
+func DropReverted(commits []Commit) []Commit {
+	// The reverted commits of each commit, as indices into commits. Multiple entries can share a hash, e.g. when a
+	// pull request overrides the commit with multiple entries.
+	reverts := make([][]int, len(commits))
+	for i := range commits {
+		reverts[i] = revertedCommits(commits, i)
+	}
+
+	dropped := make([]bool, len(commits))
+	isRevertedBy := func(target int) bool {
+		for i := range commits {
+			if !dropped[i] && slices.Contains(reverts[i], target) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Resolve the outermost reverts first, so that reverting a revert brings back the original commit.
+	for changed := true; changed; {
+		changed = false
+
+		for i := range commits {
+			if dropped[i] || len(reverts[i]) == 0 || isRevertedBy(i) {
+				continue
+			}
+
+			targets := slices.DeleteFunc(slices.Clone(reverts[i]), func(target int) bool { return dropped[target] })
+			if len(targets) == 0 {
+				continue
+			}
+
+			dropped[i] = true
+			for _, target := range targets {
+				dropped[target] = true
+			}
+			changed = true
+		}
+	}
+
+	result := make([]Commit, 0, len(commits))
+	for i, commit := range commits {
+		if !dropped[i] {
+			result = append(result, commit)
+		}
+	}
+
+	return result
+}
+
+// revertedCommits returns the indices of the commits that are reverted by commits[self].
+func revertedCommits(commits []Commit, self int) []int {
+	message := commits[self].Message
+	subject := commitSubject(message)
+
+	var revertedSubject string
+	for _, regex := range revertSubjectRegexes {
+		if match := regex.FindStringSubmatch(subject); match != nil {
+			revertedSubject = match[1]
+			break
+		}
+	}
+
+	var hashes []string
+	for _, match := range revertedHashRegex.FindAllStringSubmatch(message, -1) {
+		hashes = append(hashes, match[1])
+	}
+	if revertedSubject != "" {
+		for _, match := range revertedRefsRegex.FindAllStringSubmatch(message, -1) {
+			for _, hash := range strings.Split(match[1], ",") {
+				hashes = append(hashes, strings.TrimSpace(hash))
+			}
+		}
+	}
+
+	var match func(commit Commit) bool
+	switch {
+	case len(hashes) > 0:
+		match = func(commit Commit) bool {
+			return slices.ContainsFunc(hashes, func(hash string) bool {
+				return strings.HasPrefix(commit.Hash, strings.ToLower(hash))
+			})
+		}
+	case revertedSubject != "":
+		match = func(commit Commit) bool {
+			return commitSubject(commit.Message) == revertedSubject
+		}
+	default:
+		return nil
+	}
+
+	var targets []int
+	for i, commit := range commits {
+		if commit.Hash != commits[self].Hash && match(commit) {
+			targets = append(targets, i)
+		}
+	}
+
+	return targets
+}
+
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(subject)
+}
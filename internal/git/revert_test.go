@@ -0,0 +1,118 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropReverted(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []Commit
+		want    []Commit
+	}{
+		{
+			name:    "no commits",
+			commits: []Commit{},
+			want:    []Commit{},
+		},
+		{
+			name: "no reverts",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+			},
+			want: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+			},
+		},
+		{
+			name: "git revert",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+				{Hash: "cccccccc", Message: "Revert \"feat: foo\"\n\nThis reverts commit aaaaaaaa.\n"},
+			},
+			want: []Commit{
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+			},
+		},
+		{
+			name: "conventional revert with refs",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+				{Hash: "cccccccc", Message: "revert: let us never again speak of foo and bar\n\nRefs: aaaaaaa, bbbbbbb\n"},
+			},
+			want: []Commit{},
+		},
+		{
+			name: "refs footer without revert",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar\n\nRefs: aaaaaaa\n"},
+			},
+			want: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "fix: bar\n\nRefs: aaaaaaa\n"},
+			},
+		},
+		{
+			name: "squashed revert by subject",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo (#12)"},
+				{Hash: "bbbbbbbb", Message: "Revert \"feat: foo (#12)\" (#13)\n\nReverts apricote/releaser-pleaser#12"},
+			},
+			want: []Commit{},
+		},
+		{
+			name: "revert type by subject",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "revert: feat: foo"},
+			},
+			want: []Commit{},
+		},
+		{
+			name: "reverted commit from previous release",
+			commits: []Commit{
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+				{Hash: "cccccccc", Message: "Revert \"feat: foo\"\n\nThis reverts commit aaaaaaaa.\n"},
+			},
+			want: []Commit{
+				{Hash: "bbbbbbbb", Message: "fix: bar"},
+				{Hash: "cccccccc", Message: "Revert \"feat: foo\"\n\nThis reverts commit aaaaaaaa.\n"},
+			},
+		},
+		{
+			name: "revert of revert",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+				{Hash: "bbbbbbbb", Message: "Revert \"feat: foo\"\n\nThis reverts commit aaaaaaaa.\n"},
+				{Hash: "cccccccc", Message: "Revert \"Revert \"feat: foo\"\"\n\nThis reverts commit bbbbbbbb.\n"},
+			},
+			want: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo"},
+			},
+		},
+		{
+			name: "pull request with overrides",
+			commits: []Commit{
+				{Hash: "aaaaaaaa", Message: "feat: foo", PullRequest: &PullRequest{ID: 1}},
+				{Hash: "aaaaaaaa", Message: "feat: bar", PullRequest: &PullRequest{ID: 1}},
+				{Hash: "bbbbbbbb", Message: "fix: baz"},
+				{Hash: "cccccccc", Message: "Revert \"feat: foo\"\n\nThis reverts commit aaaaaaaa.\n"},
+			},
+			want: []Commit{
+				{Hash: "bbbbbbbb", Message: "fix: baz"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DropReverted(tt.commits))
+		})
+	}
+}
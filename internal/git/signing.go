@@ -0,0 +1,159 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signer creates signatures for commits and tags.
+type Signer = git.Signer
+
+// NewSigner parses an armored GPG private key or an SSH private key and returns a Signer for it. The passphrase is
+// only used if the key is encrypted.
+func NewSigner(key, passphrase []byte) (Signer, error) {
+	if bytes.Contains(key, []byte("BEGIN PGP PRIVATE KEY BLOCK")) {
+		return newGPGSigner(key, passphrase)
+	}
+
+	return newSSHSigner(key, passphrase)
+}
+
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func newGPGSigner(key, passphrase []byte) (*gpgSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gpg key: %w", err)
+	}
+	if len(entities) == 0 {
+		return nil, errors.New("gpg key ring does not contain any keys")
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, errors.New("gpg key is not a private key")
+	}
+
+	if entity.PrivateKey.Encrypted {
+		if err = entity.DecryptPrivateKeys(passphrase); err != nil {
+			return nil, fmt.Errorf("failed to decrypt gpg key: %w", err)
+		}
+	}
+
+	return &gpgSigner{entity: entity}, nil
+}
+
+func (s *gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, message, nil); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+const (
+	sshSigMagic         = "SSHSIG"
+	sshSigVersion       = 1
+	sshSigNamespace     = "git"
+	sshSigHashAlgorithm = "sha512"
+	sshSigLineLength    = 70
+)
+
+// sshSigner creates signatures in the format of `ssh-keygen -Y sign`, as described in
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+func newSSHSigner(key, passphrase []byte) (*sshSigner, error) {
+	var signer ssh.Signer
+	var err error
+
+	if len(passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key: %w", err)
+	}
+
+	return &sshSigner{signer: signer}, nil
+}
+
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, err
+	}
+
+	signedData := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: sshSigHashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	var signature *ssh.Signature
+	var err error
+
+	// RSA keys default to SHA-1 signatures, which are not accepted by git
+	if algorithmSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signedData, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, signedData)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{
+		Version:       sshSigVersion,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     sshSigNamespace,
+		HashAlgorithm: sshSigHashAlgorithm,
+		Signature:     ssh.Marshal(signature),
+	})...)
+
+	return armorSSHSignature(blob), nil
+}
+
+func armorSSHSignature(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var b strings.Builder
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > sshSigLineLength {
+		b.WriteString(encoded[:sshSigLineLength])
+		b.WriteString("\n")
+		encoded = encoded[sshSigLineLength:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\n-----END SSH SIGNATURE-----\n")
+
+	return []byte(b.String())
+}
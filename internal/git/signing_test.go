@@ -0,0 +1,95 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+const message = "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nchore(main): release v1.0.0\n"
+
+func TestNewSigner_GPG(t *testing.T) {
+	entity, err := openpgp.NewEntity("releaser-pleaser", "", "releaser-pleaser@example.com", nil)
+	require.NoError(t, err)
+
+	var key bytes.Buffer
+	w, err := armor.Encode(&key, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+
+	signer, err := NewSigner(key.Bytes(), nil)
+	require.NoError(t, err)
+
+	signature, err := signer.Sign(strings.NewReader(message))
+	require.NoError(t, err)
+
+	_, err = openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, strings.NewReader(message), bytes.NewReader(signature), nil)
+	assert.NoError(t, err)
+}
+
+func TestNewSigner_SSH(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	require.NoError(t, err)
+
+	signer, err := NewSigner(pem.EncodeToMemory(block), nil)
+	require.NoError(t, err)
+
+	armored, err := signer.Sign(strings.NewReader(message))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(armored)), "\n")
+	assert.Equal(t, "-----BEGIN SSH SIGNATURE-----", lines[0])
+	assert.Equal(t, "-----END SSH SIGNATURE-----", lines[len(lines)-1])
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(lines[1:len(lines)-1], ""))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(blob, []byte(sshSigMagic)))
+
+	var sig struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	require.NoError(t, ssh.Unmarshal(blob[len(sshSigMagic):], &sig))
+	assert.Equal(t, uint32(1), sig.Version)
+	assert.Equal(t, "git", sig.Namespace)
+	assert.Equal(t, "sha512", sig.HashAlgorithm)
+
+	publicKey, err := ssh.ParsePublicKey(sig.PublicKey)
+	require.NoError(t, err)
+
+	var signature ssh.Signature
+	require.NoError(t, ssh.Unmarshal(sig.Signature, &signature))
+
+	hash := sha512.Sum512([]byte(message))
+	signedData := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{"git", "", "sha512", hash[:]})...)
+
+	assert.NoError(t, publicKey.Verify(signedData, &signature))
+}
+
+func TestNewSigner_Invalid(t *testing.T) {
+	_, err := NewSigner([]byte("foobar"), nil)
+	assert.Error(t, err)
+}
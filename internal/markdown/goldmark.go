@@ -2,6 +2,7 @@ package markdown
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	markdown "github.com/teekennedy/goldmark-markdown"
@@ -96,6 +97,123 @@ func GetSectionText(source []byte, name string, output *string) gast.Walker {
 	}
 }
 
+// GetHeadingText returns the Markdown source below the first heading with the title, up to the next heading of the
+// same or a higher level. The title is compared case-insensitively.
+func GetHeadingText(source []byte, title string, output *string, found *bool) gast.Walker {
+	return func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering || n.Kind() != gast.KindHeading || n.Lines().Len() == 0 {
+			return gast.WalkContinue, nil
+		}
+
+		heading := n.(*gast.Heading)
+		if !strings.EqualFold(textFromLines(source, heading), title) {
+			return gast.WalkSkipChildren, nil
+		}
+
+		// The content starts on the line after the heading
+		start := heading.Lines().At(heading.Lines().Len() - 1).Stop
+		if i := bytes.IndexByte(source[start:], '\n'); i >= 0 {
+			start += i + 1
+		} else {
+			start = len(source)
+		}
+
+		end := len(source)
+		for sibling := heading.NextSibling(); sibling != nil; sibling = sibling.NextSibling() {
+			next, ok := sibling.(*gast.Heading)
+			if ok && next.Level <= heading.Level && next.Lines().Len() > 0 {
+				end = bytes.LastIndexByte(source[:next.Lines().At(0).Start], '\n') + 1
+				break
+			}
+		}
+
+		*output = strings.TrimSpace(string(source[start:end]))
+		if found != nil {
+			*found = true
+		}
+		// Stop looking after we find the first result
+		return gast.WalkStop, nil
+	}
+}
+
+// replacementSection temporarily holds the new content in ReplaceSection.
+const replacementSection = "releaser-pleaser-replacement"
+
+// ReplaceSection replaces the content of the first section with the name by content and renders the whole document
+// again. The document is formatted like by Format, which keeps documents that were already formatted unchanged.
+func ReplaceSection(source, name, content string) (string, error) {
+	// The nodes of the content and the document must reference the same source, so both are parsed together. The
+	// content is put first, so an unclosed block in the user-editable document can not swallow it.
+	combined := []byte(fmt.Sprintf(extensions.SectionStartFormat, replacementSection) + content +
+		fmt.Sprintf(extensions.SectionEndFormat, replacementSection) + "\n\n" + source)
+
+	md := New()
+	doc := md.Parser().Parse(text.NewReader(combined))
+
+	var replacement, section *ast.Section
+	err := gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindSection {
+			return gast.WalkContinue, nil
+		}
+
+		switch n := n.(*ast.Section); {
+		case replacement == nil && n.Name == replacementSection:
+			replacement = n
+			return gast.WalkSkipChildren, nil
+		case n.Name == name:
+			section = n
+			return gast.WalkStop, nil
+		}
+
+		return gast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if section == nil {
+		return "", fmt.Errorf("section %q not found", name)
+	}
+
+	section.RemoveChildren(section)
+	for child := replacement.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		section.AppendChild(section, child)
+		child = next
+	}
+	replacement.Parent().RemoveChild(replacement.Parent(), replacement)
+
+	var buf bytes.Buffer
+	if err = md.Renderer().Render(&buf, combined, doc); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Heading is a heading in a Markdown document.
+type Heading struct {
+	Level int
+	// Offset is the position of the start of the line with the heading in the source.
+	Offset int
+}
+
+// GetHeadings collects all headings of the document in order.
+func GetHeadings(source []byte, output *[]Heading) gast.Walker {
+	return func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering || n.Kind() != gast.KindHeading || n.Lines().Len() == 0 {
+			return gast.WalkContinue, nil
+		}
+
+		start := n.Lines().At(0).Start
+		*output = append(*output, Heading{
+			Level:  n.(*gast.Heading).Level,
+			Offset: bytes.LastIndexByte(source[:start], '\n') + 1,
+		})
+
+		return gast.WalkSkipChildren, nil
+	}
+}
+
 func textFromLines(source []byte, n gast.Node) string {
 	content := make([]byte, 0)
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/yuin/goldmark/ast"
 )
 
@@ -126,6 +127,57 @@ func TestGetCodeBlockText(t *testing.T) {
 	}
 }
 
+func TestGetHeadingText(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		title     string
+		wantText  string
+		wantFound bool
+	}{
+		{
+			name:      "no heading",
+			source:    "Foo\n\nBar",
+			title:     "Release Notes",
+			wantText:  "",
+			wantFound: false,
+		},
+		{
+			name:      "until end",
+			source:    "## Summary\n\nFoo\n\n### Release Notes\n\nFirst paragraph.\n\n- a list\n",
+			title:     "Release Notes",
+			wantText:  "First paragraph.\n\n- a list",
+			wantFound: true,
+		},
+		{
+			name:      "until next heading",
+			source:    "### release notes\n\nText\n\n#### Details\n\nMore\n\n### Checklist\n\n- [x] Tests",
+			title:     "Release Notes",
+			wantText:  "Text\n\n#### Details\n\nMore",
+			wantFound: true,
+		},
+		{
+			name:      "empty",
+			source:    "### Release Notes\n### Checklist\n",
+			title:     "Release Notes",
+			wantText:  "",
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotText string
+			var gotFound bool
+
+			err := WalkAST([]byte(tt.source), GetHeadingText([]byte(tt.source), tt.title, &gotText, &gotFound))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantText, gotText)
+			assert.Equal(t, tt.wantFound, gotFound)
+		})
+	}
+}
+
 func TestGetSectionText(t *testing.T) {
 	type args struct {
 		source []byte
@@ -258,3 +310,109 @@ func TestWalkAST(t *testing.T) {
 		})
 	}
 }
+
+func TestGetHeadings(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Heading
+	}{
+		{
+			name:   "no headings",
+			source: "Foo\n\n- Bar\n",
+			want:   nil,
+		},
+		{
+			name:   "atx headings",
+			source: "# Changelog\n\nSome text.\n\n## v1.0.0\n\n### Features\n",
+			want: []Heading{
+				{Level: 1, Offset: 0},
+				{Level: 2, Offset: 25},
+				{Level: 3, Offset: 36},
+			},
+		},
+		{
+			name:   "setext heading",
+			source: "Changelog\n=========\n\nv1.0.0\n------\n",
+			want: []Heading{
+				{Level: 1, Offset: 0},
+				{Level: 2, Offset: 21},
+			},
+		},
+		{
+			name:   "code block",
+			source: "# Changelog\n\n```md\n## Not a heading\n```\n",
+			want: []Heading{
+				{Level: 1, Offset: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Heading
+			err := WalkAST([]byte(tt.source), GetHeadings([]byte(tt.source), &got))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		section string
+		content string
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "replaces content",
+			source:  "<!-- section-start foobar -->\n- 1\n- 2\n\n<!-- section-end foobar -->\n\n## Overrides\n\n```rp-prefix\nKeep me\n```\n",
+			section: "foobar",
+			content: "### Features\n\n- 3\n",
+			want:    "<!-- section-start foobar -->\n### Features\n\n- 3\n\n<!-- section-end foobar -->\n\n## Overrides\n\n```rp-prefix\nKeep me\n```\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty content",
+			source:  "# Foo\n\n<!-- section-start foobar -->\n- 1\n\n<!-- section-end foobar -->\n\nBar\n",
+			section: "foobar",
+			content: "",
+			want:    "# Foo\n\n<!-- section-start foobar -->\n\n<!-- section-end foobar -->\n\nBar\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "other sections are kept",
+			source:  "<!-- section-start other -->\n- 1\n\n<!-- section-end other -->\n\n<!-- section-start foobar -->\n- 2\n\n<!-- section-end foobar -->\n",
+			section: "foobar",
+			content: "- 3",
+			want:    "<!-- section-start other -->\n- 1\n\n<!-- section-end other -->\n\n<!-- section-start foobar -->\n- 3\n\n<!-- section-end foobar -->\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unclosed code block in document",
+			source:  "<!-- section-start foobar -->\n- 1\n\n<!-- section-end foobar -->\n\n```rp-suffix\nText",
+			section: "foobar",
+			content: "- 2",
+			want:    "<!-- section-start foobar -->\n- 2\n\n<!-- section-end foobar -->\n\n```rp-suffix\nText\n```\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "missing section",
+			source:  "# Foo\n",
+			section: "foobar",
+			content: "- 1",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReplaceSection(tt.source, tt.section, tt.content)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
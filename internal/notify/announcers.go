@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+// Slack posts the message to a Slack incoming webhook.
+type Slack struct {
+	client   *http.Client
+	url      string
+	template *template.Template
+}
+
+var _ forge.ReleaseAnnouncer = &Slack{}
+
+// NewSlack returns a Slack announcer for the URL of an incoming webhook.
+func NewSlack(client *http.Client, webhookURL string, tpl *template.Template) *Slack {
+	return &Slack{client: client, url: webhookURL, template: tpl}
+}
+
+func (s *Slack) AnnounceRelease(ctx context.Context, release forge.Release) error {
+	message, err := Message(s.template, release)
+	if err != nil {
+		return err
+	}
+
+	err = post(ctx, s.client, http.MethodPost, s.url, "", map[string]string{"text": message})
+	if err != nil {
+		return fmt.Errorf("failed to send slack notification: %w", err)
+	}
+
+	return nil
+}
+
+// Matrix sends the message to a Matrix room.
+type Matrix struct {
+	client     *http.Client
+	homeserver string
+	room       string
+	token      string
+	template   *template.Template
+}
+
+var _ forge.ReleaseAnnouncer = &Matrix{}
+
+// NewMatrix returns a Matrix announcer that sends messages to the room with the access token of a user that joined
+// the room.
+func NewMatrix(client *http.Client, homeserver, room, token string, tpl *template.Template) *Matrix {
+	return &Matrix{client: client, homeserver: strings.TrimSuffix(homeserver, "/"), room: room, token: token, template: tpl}
+}
+
+func (m *Matrix) AnnounceRelease(ctx context.Context, release forge.Release) error {
+	message, err := Message(m.template, release)
+	if err != nil {
+		return err
+	}
+
+	// The transaction ID makes retries of the same release idempotent.
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver, url.PathEscape(m.room), url.PathEscape("releaser-pleaser-"+release.TagName))
+
+	err = post(ctx, m.client, http.MethodPut, endpoint, m.token, map[string]string{"msgtype": "m.text", "body": message})
+	if err != nil {
+		return fmt.Errorf("failed to send matrix notification: %w", err)
+	}
+
+	return nil
+}
+
+// Webhook posts the release and the message as JSON to a URL.
+type Webhook struct {
+	client   *http.Client
+	url      string
+	template *template.Template
+}
+
+var _ forge.ReleaseAnnouncer = &Webhook{}
+
+// WebhookPayload is the body of the requests of the Webhook.
+type WebhookPayload struct {
+	TagName    string `json:"tag_name"`
+	URL        string `json:"url"`
+	Changelog  string `json:"changelog"`
+	Prerelease bool   `json:"prerelease"`
+	Message    string `json:"message"`
+}
+
+// NewWebhook returns a Webhook announcer for the URL.
+func NewWebhook(client *http.Client, webhookURL string, tpl *template.Template) *Webhook {
+	return &Webhook{client: client, url: webhookURL, template: tpl}
+}
+
+func (w *Webhook) AnnounceRelease(ctx context.Context, release forge.Release) error {
+	message, err := Message(w.template, release)
+	if err != nil {
+		return err
+	}
+
+	err = post(ctx, w.client, http.MethodPost, w.url, "", WebhookPayload{
+		TagName:    release.TagName,
+		URL:        release.URL,
+		Changelog:  release.Changelog,
+		Prerelease: release.Prerelease,
+		Message:    message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send webhook notification: %w", err)
+	}
+
+	return nil
+}
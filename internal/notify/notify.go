@@ -0,0 +1,99 @@
+// Package notify posts a message to chats and webhooks after a release was created.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+// DefaultTemplate is the message that is sent if no template is configured.
+const DefaultTemplate = `Released {{ .Release.TagName }}: {{ .Release.URL }}
+{{- with .Summary }}
+
+{{ . }}
+{{- end }}`
+
+// maxSummaryEntries is the number of changelog entries that are listed in the summary.
+const maxSummaryEntries = 10
+
+// MessageData is available in the template of the message.
+type MessageData struct {
+	Release forge.Release
+	// Summary lists the first entries of the changelog, without the headings.
+	Summary string
+}
+
+// ParseTemplate parses the template of the message. An empty template returns the DefaultTemplate.
+func ParseTemplate(raw string) (*template.Template, error) {
+	if raw == "" {
+		raw = DefaultTemplate
+	}
+
+	return template.New("notification").Option("missingkey=error").Parse(raw)
+}
+
+// Message renders the message for the release.
+func Message(tpl *template.Template, release forge.Release) (string, error) {
+	var message strings.Builder
+	err := tpl.Execute(&message, MessageData{Release: release, Summary: Summary(release.Changelog)})
+	if err != nil {
+		return "", fmt.Errorf("failed to render notification: %w", err)
+	}
+
+	return strings.TrimSpace(message.String()), nil
+}
+
+// Summary returns the first entries of the changelog. If there are more entries, a line with their number is added.
+func Summary(changelog string) string {
+	var entries []string
+	for _, line := range strings.Split(changelog, "\n") {
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+			entries = append(entries, "- "+strings.TrimSpace(line[2:]))
+		}
+	}
+
+	if len(entries) > maxSummaryEntries {
+		more := len(entries) - maxSummaryEntries
+		entries = append(entries[:maxSummaryEntries], fmt.Sprintf("- … and %d more", more))
+	}
+
+	return strings.Join(entries, "\n")
+}
+
+// post sends the payload as JSON and returns an error for unsuccessful responses.
+func post(ctx context.Context, client *http.Client, method, url, token string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+var release = forge.Release{
+	TagName:   "v1.2.0",
+	URL:       "https://example.com/releases/v1.2.0",
+	Changelog: "### Features\n\n- Added foo\n\n### Bug Fixes\n\n- Fixed bar\n",
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "default",
+			want:    "Released v1.2.0: https://example.com/releases/v1.2.0\n\n- Added foo\n- Fixed bar",
+			wantErr: assert.NoError,
+		},
+		{
+			name:     "custom",
+			template: "{{ .Release.TagName }} is out!",
+			want:     "v1.2.0 is out!",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "unknown field",
+			template: "{{ .Foo }}",
+			wantErr:  assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := ParseTemplate(tt.template)
+			require.NoError(t, err)
+
+			got, err := Message(tpl, release)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var changelog strings.Builder
+	changelog.WriteString("### Features\n\n")
+	for range 12 {
+		changelog.WriteString("* foo\n")
+	}
+
+	got := Summary(changelog.String())
+	assert.Equal(t, strings.Repeat("- foo\n", 10)+"- … and 2 more", got)
+}
+
+func TestAnnouncers(t *testing.T) {
+	tpl, err := ParseTemplate("{{ .Release.TagName }}")
+	require.NoError(t, err)
+
+	type request struct {
+		method, path, auth string
+		body               map[string]any
+	}
+
+	tests := []struct {
+		name      string
+		announcer func(url string) forge.ReleaseAnnouncer
+		want      request
+	}{
+		{
+			name:      "slack",
+			announcer: func(url string) forge.ReleaseAnnouncer { return NewSlack(http.DefaultClient, url+"/hook", tpl) },
+			want:      request{method: http.MethodPost, path: "/hook", body: map[string]any{"text": "v1.2.0"}},
+		},
+		{
+			name: "matrix",
+			announcer: func(url string) forge.ReleaseAnnouncer {
+				return NewMatrix(http.DefaultClient, url+"/", "!room:example.com", "secret", tpl)
+			},
+			want: request{
+				method: http.MethodPut,
+				path:   "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/releaser-pleaser-v1.2.0",
+				auth:   "Bearer secret",
+				body:   map[string]any{"msgtype": "m.text", "body": "v1.2.0"},
+			},
+		},
+		{
+			name:      "webhook",
+			announcer: func(url string) forge.ReleaseAnnouncer { return NewWebhook(http.DefaultClient, url, tpl) },
+			want: request{method: http.MethodPost, path: "/", body: map[string]any{
+				"tag_name":   "v1.2.0",
+				"url":        "https://example.com/releases/v1.2.0",
+				"changelog":  release.Changelog,
+				"prerelease": false,
+				"message":    "v1.2.0",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = request{method: r.Method, path: r.URL.EscapedPath(), auth: r.Header.Get("Authorization")}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got.body))
+			}))
+			defer server.Close()
+
+			err := tt.announcer(server.URL).AnnounceRelease(context.Background(), release)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := NewSlack(http.DefaultClient, server.URL, tpl).AnnounceRelease(context.Background(), release)
+		assert.ErrorContains(t, err, "invalid_token")
+	})
+}
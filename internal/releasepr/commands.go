@@ -0,0 +1,42 @@
+package releasepr
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	CommandSetVersion = "set-version"
+	CommandSkip       = "skip"
+)
+
+var (
+	// CommandRegex matches commands in comments on the release pull request, e.g. "/releaser-pleaser skip abc123".
+	CommandRegex = regexp.MustCompile(`(?m)^/(?:releaser-pleaser|rp)[ \t]+([\w-]+)[ \t]*(.*?)\s*$`)
+)
+
+// ApplyCommands parses the commands from the comments and applies them to the overrides. Comments are expected in
+// chronological order, later commands take precedence. Unknown commands are ignored.
+//
+// Supported commands:
+//   - "set-version <version>": Use the version for the next release, instead of the calculated one. Without a
+//     version, any previous set-version is reset.
+//   - "skip <sha>": Exclude the commit from the release. Abbreviated hashes are supported.
+func ApplyCommands(overrides ReleaseOverrides, comments []string) ReleaseOverrides {
+	for _, comment := range comments {
+		for _, match := range CommandRegex.FindAllStringSubmatch(comment, -1) {
+			command, arg := match[1], match[2]
+
+			switch command {
+			case CommandSetVersion:
+				overrides.NextVersion = arg
+			case CommandSkip:
+				if arg != "" {
+					overrides.SkipCommits = append(overrides.SkipCommits, strings.Fields(arg)...)
+				}
+			}
+		}
+	}
+
+	return overrides
+}
@@ -0,0 +1,53 @@
+package releasepr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides ReleaseOverrides
+		comments  []string
+		want      ReleaseOverrides
+	}{
+		{
+			name:      "no comments",
+			overrides: ReleaseOverrides{Prefix: "Foo"},
+			comments:  nil,
+			want:      ReleaseOverrides{Prefix: "Foo"},
+		},
+		{
+			name:     "set version",
+			comments: []string{"/releaser-pleaser set-version 2.0.0"},
+			want:     ReleaseOverrides{NextVersion: "2.0.0"},
+		},
+		{
+			name:     "later set version wins",
+			comments: []string{"/rp set-version 2.0.0", "Lets do 3.0.0 instead\n\n/rp set-version 3.0.0\n"},
+			want:     ReleaseOverrides{NextVersion: "3.0.0"},
+		},
+		{
+			name:     "reset version",
+			comments: []string{"/rp set-version 2.0.0", "/rp set-version"},
+			want:     ReleaseOverrides{},
+		},
+		{
+			name:     "skip commits",
+			comments: []string{"/rp skip abc123\n/releaser-pleaser skip def456 0123456"},
+			want:     ReleaseOverrides{SkipCommits: []string{"abc123", "def456", "0123456"}},
+		},
+		{
+			name:     "ignores unknown commands and text",
+			comments: []string{"/rp foobar 1.0.0", "Please /rp skip abc123", "/rpskip abc123"},
+			want:     ReleaseOverrides{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ApplyCommands(tt.overrides, tt.comments))
+		})
+	}
+}
@@ -30,6 +30,15 @@ var (
 	}
 )
 
+// LabelAllowMajor is added by users to the release pull request to allow a major version bump, if the repository
+// requires it for breaking changes. It uses its own scope, as the GitLab scoped labels of rp-next-version are mutually
+// exclusive.
+var LabelAllowMajor = Label{
+	Color:       "D93F0B",
+	Name:        "rp-bump::major",
+	Description: "Allow a major version bump for breaking changes",
+}
+
 var (
 	LabelReleasePending = Label{
 		Color:       "DEDEDE",
@@ -43,12 +52,32 @@ var (
 	}
 )
 
+// LabelChangelogInclude is added by users to a merged pull request, to list it in the changelog of the next release
+// even though its commits are not releasable, e.g. "docs:" or "chore:".
+var LabelChangelogInclude = Label{
+	Color:       "1D76DB",
+	Name:        "rp-changelog::include",
+	Description: "Include this PR in the changelog of the next release",
+}
+
+// ReleaseTypeLabels can be added to the release pull request by users to change the type of the next release.
+var ReleaseTypeLabels = []Label{
+	LabelNextVersionTypeNormal,
+	LabelNextVersionTypeRC,
+	LabelNextVersionTypeBeta,
+	LabelNextVersionTypeAlpha,
+}
+
 var KnownLabels = []Label{
 	LabelNextVersionTypeNormal,
 	LabelNextVersionTypeRC,
 	LabelNextVersionTypeBeta,
 	LabelNextVersionTypeAlpha,
 
+	LabelAllowMajor,
+
 	LabelReleasePending,
 	LabelReleaseTagged,
+
+	LabelChangelogInclude,
 }
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/apricote/releaser-pleaser/internal/git"
@@ -14,7 +16,8 @@ import (
 )
 
 var (
-	releasePRTemplate *template.Template
+	releasePRTemplate    *template.Template
+	defaultTitleTemplate *template.Template
 )
 
 //go:embed releasepr.md.tpl
@@ -26,39 +29,91 @@ func init() {
 	if err != nil {
 		log.Fatalf("failed to parse release pr template: %v", err)
 	}
+
+	defaultTitleTemplate, err = ParseTitleTemplate("title", rawDefaultTitleTemplate)
+	if err != nil {
+		log.Fatalf("failed to parse default title template: %v", err)
+	}
 }
 
 type ReleasePullRequest struct {
 	git.PullRequest
 	Labels []Label
 
-	Head          string
+	Head string
+	// Base is the branch that the pull request merges into. It is only set for pull requests returned by the forge.
+	Base          string
 	ReleaseCommit *git.Commit
+
+	// ManualChanges are the commits that users pushed to the release branch. They are listed as a warning in the
+	// description.
+	ManualChanges []ManualChange
+
+	// version is set with the title and recorded in the description, so it can be read back independent of the
+	// title format.
+	version string
 }
 
-func NewReleasePullRequest(head, branch, version, changelogEntry string) (*ReleasePullRequest, error) {
+// NewReleasePullRequest returns the pull request for the release. The title is rendered from titleTemplate, or the
+// DefaultTitleTemplate if it is nil. If maxDescriptionLength is positive, the changelog in the description is
+// truncated to stay below it, see SetDescriptionWithLimit.
+func NewReleasePullRequest(head string, titleTemplate *template.Template, data TitleData, changelogEntry string, omitted []OmittedChange, maxDescriptionLength int) (*ReleasePullRequest, error) {
 	rp := &ReleasePullRequest{
 		Head:   head,
 		Labels: []Label{LabelReleasePending},
 	}
 
-	rp.SetTitle(branch, version)
-	if err := rp.SetDescription(changelogEntry, ReleaseOverrides{}); err != nil {
+	if err := rp.SetTitleFromTemplate(titleTemplate, data); err != nil {
+		return nil, err
+	}
+	if err := rp.SetDescriptionWithLimit(changelogEntry, ReleaseOverrides{}, omitted, maxDescriptionLength); err != nil {
 		return nil, err
 	}
 
 	return rp, nil
 }
 
+// OmittedChange is a pull request or commit of the release that is not listed in the changelog, e.g. because its type
+// is not releasable. They are listed in the description, so users can add them with the LabelChangelogInclude.
+type OmittedChange struct {
+	// Title is the title of the pull request, or the subject of the commit if it has no pull request.
+	Title          string
+	PullRequestID  int
+	PullRequestURL string
+	// Hash is the abbreviated hash of commits without a pull request.
+	Hash string
+	// Skipped is set if the commit was removed from the release with the "/rp-skip" command.
+	Skipped bool
+}
+
+// ManualChange is a commit on the release branch that was not created by releaser-pleaser, e.g. a fix to the
+// changelog. It is kept on top of the release commit when the branch is updated.
+type ManualChange struct {
+	// Title is the subject of the commit.
+	Title string
+	// Hash is the abbreviated hash of the commit.
+	Hash string
+	// Conflict is set if the commit could not be applied to the updated release commit. The release branch is not
+	// updated until the commit is removed from it.
+	Conflict bool
+}
+
 type ReleaseOverrides struct {
 	Prefix          string
 	Suffix          string
 	NextVersionType versioning.NextVersionType
+	// NextVersion is used instead of the calculated version, if set.
+	NextVersion string
+	// SkipCommits are (abbreviated) hashes of commits that are excluded from the release.
+	SkipCommits []string
+	// AllowMajor is set if the LabelAllowMajor is on the pull request.
+	AllowMajor bool
 }
 
 const (
-	DescriptionLanguagePrefix = "rp-prefix"
-	DescriptionLanguageSuffix = "rp-suffix"
+	DescriptionLanguagePrefix      = "rp-prefix"
+	DescriptionLanguageSuffix      = "rp-suffix"
+	DescriptionLanguageNextVersion = "rp-next-version"
 )
 
 const (
@@ -66,11 +121,17 @@ const (
 )
 
 const (
-	TitleFormat = "chore(%s): release %s"
+	rawDefaultTitleTemplate = "chore({{ .Branch }}): release {{ .Version }}"
 )
 
 var (
+	// TitleRegex matches titles of the DefaultTitleTemplate. It is only used for pull requests that were created before
+	// the version was recorded in the description.
 	TitleRegex = regexp.MustCompile("chore(.*): release (.*)")
+	// VersionMarkerRegex matches the hidden comment in the description that records the version of the pull request.
+	VersionMarkerRegex = regexp.MustCompile(`<!-- rp-version: (\S+) -->`)
+	// NextVersionRegex matches a line in the description that sets the next version, e.g. "rp-next-version: 2.0.0".
+	NextVersionRegex = regexp.MustCompile(`(?m)^` + DescriptionLanguageNextVersion + `:[ \t]*(\S+)[ \t]*$`)
 )
 
 func (pr *ReleasePullRequest) GetOverrides() (ReleaseOverrides, error) {
@@ -96,6 +157,8 @@ func (pr *ReleasePullRequest) parseVersioningFlags(overrides ReleaseOverrides) R
 			overrides.NextVersionType = versioning.NextVersionTypeBeta
 		case LabelNextVersionTypeAlpha:
 			overrides.NextVersionType = versioning.NextVersionTypeAlpha
+		case LabelAllowMajor:
+			overrides.AllowMajor = true
 		case LabelReleasePending, LabelReleaseTagged:
 			// These labels have no effect on the versioning.
 			break
@@ -111,11 +174,19 @@ func (pr *ReleasePullRequest) parseDescription(overrides ReleaseOverrides) (Rele
 	err := markdown.WalkAST(source,
 		markdown.GetCodeBlockText(source, DescriptionLanguagePrefix, &overrides.Prefix, nil),
 		markdown.GetCodeBlockText(source, DescriptionLanguageSuffix, &overrides.Suffix, nil),
+		markdown.GetCodeBlockText(source, DescriptionLanguageNextVersion, &overrides.NextVersion, nil),
 	)
 	if err != nil {
 		return ReleaseOverrides{}, err
 	}
 
+	overrides.NextVersion = strings.TrimSpace(overrides.NextVersion)
+	if overrides.NextVersion == "" {
+		if matches := NextVersionRegex.FindStringSubmatch(pr.Description); matches != nil {
+			overrides.NextVersion = matches[1]
+		}
+	}
+
 	return overrides, nil
 }
 
@@ -132,11 +203,86 @@ func (pr *ReleasePullRequest) ChangelogText() (string, error) {
 
 }
 
+// SetChangelogText replaces the changelog in the description. Unlike SetDescription, the rest of the description is
+// kept as it is, including any edits by users outside of the overrides.
+func (pr *ReleasePullRequest) SetChangelogText(changelogEntry string) error {
+	description, err := markdown.ReplaceSection(pr.Description, MarkdownSectionChangelog, changelogEntry)
+	if err != nil {
+		return err
+	}
+
+	pr.Description = description
+
+	return nil
+}
+
+// TitleData is available in the templates of the pull request title and the release commit message.
+type TitleData struct {
+	// Version is the tag name of the release, e.g. "v1.2.3" or "api/v1.2.3".
+	Version string
+	// Branch is the released branch.
+	Branch string
+	// Package is the name of the released package, it is empty if the whole repository is released.
+	Package string
+}
+
+// DefaultTitleTemplate is the default template of the pull request title and the release commit message, it renders
+// e.g. "chore(main): release v1.2.3".
+func DefaultTitleTemplate() *template.Template {
+	return defaultTitleTemplate
+}
+
+// ParseTitleTemplate parses a template of the pull request title or the release commit message.
+func ParseTitleTemplate(name, raw string) (*template.Template, error) {
+	return template.New(name).Parse(raw)
+}
+
+// RenderTitle executes the template of the pull request title or the release commit message. An empty result is an
+// error, as the forges and git reject it.
+func RenderTitle(tpl *template.Template, data TitleData) (string, error) {
+	var title bytes.Buffer
+	if err := tpl.Execute(&title, data); err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(title.String()) == "" {
+		return "", fmt.Errorf("template %q rendered an empty text", tpl.Name())
+	}
+
+	return title.String(), nil
+}
+
+// SetTitle sets the title from the DefaultTitleTemplate.
 func (pr *ReleasePullRequest) SetTitle(branch, version string) {
-	pr.Title = fmt.Sprintf(TitleFormat, branch, version)
+	// The default template can not fail.
+	_ = pr.SetTitleFromTemplate(nil, TitleData{Version: version, Branch: branch})
 }
 
+// SetTitleFromTemplate sets the title from tpl, or the DefaultTitleTemplate if it is nil. The version is recorded and
+// added to the description the next time it is set.
+func (pr *ReleasePullRequest) SetTitleFromTemplate(tpl *template.Template, data TitleData) error {
+	if tpl == nil {
+		tpl = DefaultTitleTemplate()
+	}
+
+	title, err := RenderTitle(tpl, data)
+	if err != nil {
+		return fmt.Errorf("failed to render pull request title: %w", err)
+	}
+
+	pr.Title = title
+	pr.version = data.Version
+
+	return nil
+}
+
+// Version returns the version of the release. It is read from the description, with a fallback to the title of pull
+// requests that were created by older versions of releaser-pleaser.
 func (pr *ReleasePullRequest) Version() (string, error) {
+	if matches := VersionMarkerRegex.FindStringSubmatch(pr.Description); matches != nil {
+		return matches[1], nil
+	}
+
 	matches := TitleRegex.FindStringSubmatch(pr.Title)
 	if len(matches) != 3 {
 		return "", fmt.Errorf("title has unexpected format")
@@ -146,16 +292,61 @@ func (pr *ReleasePullRequest) Version() (string, error) {
 }
 
 func (pr *ReleasePullRequest) SetDescription(changelogEntry string, overrides ReleaseOverrides) error {
-	var description bytes.Buffer
-	err := releasePRTemplate.Execute(&description, map[string]any{
-		"Changelog": changelogEntry,
-		"Overrides": overrides,
-	})
+	return pr.SetDescriptionWithLimit(changelogEntry, overrides, nil, 0)
+}
+
+// SetDescriptionWithLimit sets the description like SetDescription and lists the omitted changes below the changelog.
+// If maxLength is positive and the description would be longer, the list of omitted changes is removed first, then
+// the entries at the end of the changelog are replaced by a notice. The overrides are never truncated.
+func (pr *ReleasePullRequest) SetDescriptionWithLimit(changelogEntry string, overrides ReleaseOverrides, omitted []OmittedChange, maxLength int) error {
+	description, err := pr.renderDescription(changelogEntry, overrides, omitted)
 	if err != nil {
 		return err
 	}
 
-	pr.Description = description.String()
+	if maxLength > 0 && len(description) > maxLength {
+		description, err = pr.renderDescription(changelogEntry, overrides, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if maxLength > 0 && len(description) > maxLength {
+		withoutChangelog, err := pr.renderDescription("", overrides, nil)
+		if err != nil {
+			return err
+		}
+
+		description, err = pr.renderDescription(truncateChangelog(changelogEntry, maxLength-len(withoutChangelog)), overrides, nil)
+		if err != nil {
+			return err
+		}
+
+		if len(description) > maxLength {
+			return fmt.Errorf("pull request description is longer than the limit of %d bytes, even after truncating the changelog", maxLength)
+		}
+	}
+
+	pr.Description = description
 
 	return nil
 }
+
+func (pr *ReleasePullRequest) renderDescription(changelogEntry string, overrides ReleaseOverrides, omitted []OmittedChange) (string, error) {
+	var description bytes.Buffer
+	err := releasePRTemplate.Execute(&description, map[string]any{
+		"Version":      pr.version,
+		"Changelog":    changelogEntry,
+		"Overrides":    overrides,
+		"Omitted":      omitted,
+		"Manual":       pr.ManualChanges,
+		"Conflict":     slices.ContainsFunc(pr.ManualChanges, func(change ManualChange) bool { return change.Conflict }),
+		"Labels":       ReleaseTypeLabels,
+		"IncludeLabel": LabelChangelogInclude,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return description.String(), nil
+}
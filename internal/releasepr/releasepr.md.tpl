@@ -1,6 +1,29 @@
+{{- if .Manual -}}
+> [!WARNING]
+> The release branch has commits that were not created by releaser-pleaser. They are kept on top of the release commit when the branch is updated:
+>
+{{ range .Manual -}}
+> - `{{ .Hash }}` {{ .Title }}{{ if .Conflict }} (conflict){{ end }}
+{{ end -}}
+{{- if .Conflict }}>
+> Some commits conflict with the updated release commit, so the branch was not updated. Remove them from the branch, or close this pull request to discard all manual changes.
+{{ end }}
+{{ end -}}
 <!-- section-start changelog -->
 {{ .Changelog }}
 <!-- section-end changelog -->
+{{- if .Omitted }}
+
+<details>
+  <summary>Merged changes not included in the changelog</summary>
+
+{{ range .Omitted -}}
+- {{ .Title }}{{ if .PullRequestURL }} ([#{{ .PullRequestID }}]({{ .PullRequestURL }})){{ else if .Hash }} (`{{ .Hash }}`){{ end }}{{ if .Skipped }} (skipped){{ end }}
+{{ end }}
+Add the label `{{ .IncludeLabel.Name }}` to a pull request to list it in the changelog.
+
+</details>
+{{- end }}
 
 ---
 
@@ -29,4 +52,24 @@ This will be added to the end of the release notes.
 {{ .Overrides.Suffix }}{{ end }}
 ```
 
+## Version
+
+Put a version in here to use it for the next release, instead of the calculated one.
+
+```rp-next-version
+{{- if .Overrides.NextVersion }}
+{{ .Overrides.NextVersion }}{{ end }}
+```
+
+## Release Type
+
+Add one of these labels to change the type of the next release:
+{{ range .Labels }}
+- `{{ .Name }}`: {{ .Description }}
+{{- end }}
+
 </details>
+{{- if .Version }}
+
+<!-- rp-version: {{ .Version }} -->
+{{- end }}
@@ -2,9 +2,12 @@ package releasepr
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/apricote/releaser-pleaser/internal/git"
 	"github.com/apricote/releaser-pleaser/internal/versioning"
@@ -34,6 +37,17 @@ func TestReleasePullRequest_GetOverrides(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "allow major label",
+			pr: ReleasePullRequest{
+				Labels: []Label{LabelAllowMajor, LabelNextVersionTypeRC},
+			},
+			want: ReleaseOverrides{
+				NextVersionType: versioning.NextVersionTypeRC,
+				AllowMajor:      true,
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "prefix in description",
 			pr: ReleasePullRequest{
@@ -54,6 +68,36 @@ func TestReleasePullRequest_GetOverrides(t *testing.T) {
 			want:    ReleaseOverrides{Suffix: "## Compatibility\n\nNo compatibility guarantees."},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "next version in description",
+			pr: ReleasePullRequest{
+				PullRequest: git.PullRequest{
+					Description: "```rp-next-version\n v2.0.0 \n```",
+				},
+			},
+			want:    ReleaseOverrides{NextVersion: "v2.0.0"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "next version line in description",
+			pr: ReleasePullRequest{
+				PullRequest: git.PullRequest{
+					Description: "Some text\nrp-next-version: 2.0.0\nMore text",
+				},
+			},
+			want:    ReleaseOverrides{NextVersion: "2.0.0"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "next version code block takes precedence over line",
+			pr: ReleasePullRequest{
+				PullRequest: git.PullRequest{
+					Description: "rp-next-version: 2.0.0\n\n```rp-next-version\n3.0.0\n```",
+				},
+			},
+			want:    ReleaseOverrides{NextVersion: "3.0.0"},
+			wantErr: assert.NoError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +210,119 @@ func TestReleasePullRequest_SetTitle(t *testing.T) {
 	}
 }
 
+func TestReleasePullRequest_SetTitleFromTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		data    TitleData
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "default",
+			data:    TitleData{Version: "v1.0.0", Branch: "main"},
+			want:    "chore(main): release v1.0.0",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "custom",
+			tpl:     "[PROJ-1] release {{ .Version }}{{ with .Package }} of {{ . }}{{ end }} [skip ci]",
+			data:    TitleData{Version: "api/v1.2.0", Branch: "main", Package: "api"},
+			want:    "[PROJ-1] release api/v1.2.0 of api [skip ci]",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown variable",
+			tpl:     "release {{ .Ticket }}",
+			data:    TitleData{Version: "v1.0.0", Branch: "main"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "empty",
+			tpl:     "{{ .Package }}",
+			data:    TitleData{Version: "v1.0.0", Branch: "main"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tpl *template.Template
+			if tt.tpl != "" {
+				var err error
+				tpl, err = ParseTitleTemplate("title", tt.tpl)
+				require.NoError(t, err)
+			}
+
+			pr := &ReleasePullRequest{}
+			err := pr.SetTitleFromTemplate(tpl, tt.data)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, pr.Title)
+		})
+	}
+}
+
+func TestReleasePullRequest_Version(t *testing.T) {
+	tests := []struct {
+		name    string
+		pr      *ReleasePullRequest
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "from description",
+			pr: &ReleasePullRequest{PullRequest: git.PullRequest{
+				Title:       "[PROJ-1] Release it",
+				Description: "## Changelog\n\n<!-- rp-version: v1.2.3 -->\n",
+			}},
+			want:    "v1.2.3",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "from title",
+			pr: &ReleasePullRequest{PullRequest: git.PullRequest{
+				Title: "chore(main): release v1.2.3",
+			}},
+			want:    "v1.2.3",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unknown title",
+			pr: &ReleasePullRequest{PullRequest: git.PullRequest{
+				Title: "[PROJ-1] Release it",
+			}},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pr.Version()
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReleasePullRequest_Version_RoundTrip(t *testing.T) {
+	tpl, err := ParseTitleTemplate("title", "Release {{ .Version }} [skip ci]")
+	require.NoError(t, err)
+
+	pr, err := NewReleasePullRequest("releaser-pleaser--branches--main", tpl, TitleData{Version: "v2.0.0", Branch: "main"}, "### Features\n\n- Foobar!", nil, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Release v2.0.0 [skip ci]", pr.Title)
+
+	// The forges only return title and description of existing pull requests.
+	got := &ReleasePullRequest{PullRequest: git.PullRequest{Title: pr.Title, Description: pr.Description}}
+	version, err := got.Version()
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", version)
+}
+
 func TestReleasePullRequest_SetDescription(t *testing.T) {
 
 	tests := []struct {
@@ -206,6 +363,22 @@ This will be added to the end of the release notes.
 ` + "```" + `rp-suffix
 ` + "```" + `
 
+## Version
+
+Put a version in here to use it for the next release, instead of the calculated one.
+
+` + "```" + `rp-next-version
+` + "```" + `
+
+## Release Type
+
+Add one of these labels to change the type of the next release:
+
+- ` + "`rp-next-version::normal`" + `: Request a stable version
+- ` + "`rp-next-version::rc`" + `: Request a pre-release -rc version
+- ` + "`rp-next-version::beta`" + `: Request a pre-release -beta version
+- ` + "`rp-next-version::alpha`" + `: Request a pre-release -alpha version
+
 </details>
 `,
 			wantErr: assert.NoError,
@@ -214,8 +387,9 @@ This will be added to the end of the release notes.
 			name:           "existing overrides",
 			changelogEntry: `## v1.0.0`,
 			overrides: ReleaseOverrides{
-				Prefix: "This release is awesome!",
-				Suffix: "Fooo",
+				Prefix:      "This release is awesome!",
+				Suffix:      "Fooo",
+				NextVersion: "v2.0.0",
 			},
 			want: `<!-- section-start changelog -->
 ## v1.0.0
@@ -246,6 +420,23 @@ This will be added to the end of the release notes.
 Fooo
 ` + "```" + `
 
+## Version
+
+Put a version in here to use it for the next release, instead of the calculated one.
+
+` + "```" + `rp-next-version
+v2.0.0
+` + "```" + `
+
+## Release Type
+
+Add one of these labels to change the type of the next release:
+
+- ` + "`rp-next-version::normal`" + `: Request a stable version
+- ` + "`rp-next-version::rc`" + `: Request a pre-release -rc version
+- ` + "`rp-next-version::beta`" + `: Request a pre-release -beta version
+- ` + "`rp-next-version::alpha`" + `: Request a pre-release -alpha version
+
 </details>
 `,
 			wantErr: assert.NoError,
@@ -263,3 +454,118 @@ Fooo
 		})
 	}
 }
+
+func TestReleasePullRequest_SetDescription_RoundTrip(t *testing.T) {
+	overrides := ReleaseOverrides{
+		Prefix:      "### Prefix\n\nThis release is awesome!",
+		Suffix:      "### Suffix\n\n- Fooo\n- Bar",
+		NextVersion: "v2.0.0",
+	}
+	changelogEntry := "### Features\n\n- Foobar!"
+
+	pr := &ReleasePullRequest{}
+	err := pr.SetDescription(changelogEntry, overrides)
+	require.NoError(t, err)
+
+	gotOverrides, err := pr.GetOverrides()
+	require.NoError(t, err)
+	assert.Equal(t, overrides, gotOverrides)
+
+	gotChangelog, err := pr.ChangelogText()
+	require.NoError(t, err)
+	assert.Equal(t, changelogEntry+"\n", gotChangelog)
+}
+
+func TestReleasePullRequest_SetChangelogText(t *testing.T) {
+	overrides := ReleaseOverrides{
+		Prefix: "### Prefix\n\nThis release is awesome!",
+		Suffix: "### Suffix\n\n- Fooo\n- Bar",
+	}
+
+	pr := &ReleasePullRequest{}
+	// Changelog entries are formatted and end with a newline
+	err := pr.SetDescription("### Features\n\n- Foobar!\n", overrides)
+	require.NoError(t, err)
+
+	// Edits outside of the overrides are kept too
+	pr.Description += "\nThanks for reviewing!\n"
+	before := pr.Description
+
+	err = pr.SetChangelogText("### Bug Fixes\n\n- Fixed the foo\n")
+	require.NoError(t, err)
+
+	gotChangelog, err := pr.ChangelogText()
+	require.NoError(t, err)
+	assert.Equal(t, "### Bug Fixes\n\n- Fixed the foo\n", gotChangelog)
+
+	gotOverrides, err := pr.GetOverrides()
+	require.NoError(t, err)
+	assert.Equal(t, overrides, gotOverrides)
+
+	assert.Equal(t, strings.Replace(before, "### Features\n\n- Foobar!", "### Bug Fixes\n\n- Fixed the foo", 1), pr.Description)
+
+	pr = &ReleasePullRequest{}
+	pr.Description = "No changelog here"
+	assert.Error(t, pr.SetChangelogText("### Features"))
+}
+
+func TestReleasePullRequest_SetDescriptionWithLimit_Omitted(t *testing.T) {
+	omitted := []OmittedChange{
+		{Title: "docs: explain foo", PullRequestID: 12, PullRequestURL: "https://example.com/pull/12"},
+		{Title: "chore: update bar", Hash: "abcdef1"},
+		{Title: "fix: broken baz", PullRequestID: 13, PullRequestURL: "https://example.com/pull/13", Skipped: true},
+	}
+
+	pr := &ReleasePullRequest{}
+	err := pr.SetDescriptionWithLimit("### Features\n\n- Foobar!", ReleaseOverrides{}, omitted, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, pr.Description, `<!-- section-end changelog -->
+
+<details>
+  <summary>Merged changes not included in the changelog</summary>
+
+- docs: explain foo ([#12](https://example.com/pull/12))
+- chore: update bar (`+"`abcdef1`"+`)
+- fix: broken baz ([#13](https://example.com/pull/13)) (skipped)
+
+Add the label `+"`rp-changelog::include`"+` to a pull request to list it in the changelog.
+
+</details>
+
+---
+`)
+
+	gotChangelog, err := pr.ChangelogText()
+	require.NoError(t, err)
+	assert.Equal(t, "### Features\n\n- Foobar!\n", gotChangelog)
+}
+
+func TestReleasePullRequest_SetDescription_ManualChanges(t *testing.T) {
+	pr := &ReleasePullRequest{ManualChanges: []ManualChange{
+		{Title: "docs: fix typo in changelog", Hash: "abcdef1"},
+		{Title: "chore: bump version", Hash: "1234567", Conflict: true},
+	}}
+	err := pr.SetDescription("### Features\n\n- Foobar!", ReleaseOverrides{})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(pr.Description, `> [!WARNING]
+> The release branch has commits that were not created by releaser-pleaser. They are kept on top of the release commit when the branch is updated:
+>
+> - `+"`abcdef1`"+` docs: fix typo in changelog
+> - `+"`1234567`"+` chore: bump version (conflict)
+>
+> Some commits conflict with the updated release commit, so the branch was not updated. Remove them from the branch, or close this pull request to discard all manual changes.
+
+<!-- section-start changelog -->
+`), pr.Description)
+
+	gotChangelog, err := pr.ChangelogText()
+	require.NoError(t, err)
+	assert.Equal(t, "### Features\n\n- Foobar!\n", gotChangelog)
+
+	pr.ManualChanges = nil
+	err = pr.SetDescription("### Features\n\n- Foobar!", ReleaseOverrides{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pr.Description, "<!-- section-start changelog -->"))
+}
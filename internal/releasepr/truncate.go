@@ -0,0 +1,95 @@
+package releasepr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	truncatedEntriesNotice   = "\n_… changes not shown: %d. The full changelog is in the changelog file of this pull request._\n"
+	truncatedChangelogNotice = "\n_… The full changelog is in the changelog file of this pull request._\n"
+)
+
+// truncateChangelog shortens the changelog to maxLength bytes, including a notice about the removed entries. Entries
+// are the top-level list items with their nested lines. It keeps the first entries in the order of the changelog and
+// removes everything after the first entry that does not fit, including headings that are left without entries.
+func truncateChangelog(changelog string, maxLength int) string {
+	if len(changelog) <= maxLength {
+		return changelog
+	}
+
+	blocks := changelogBlocks(changelog)
+
+	entries := 0
+	for _, block := range blocks {
+		if block.entry {
+			entries++
+		}
+	}
+
+	// The notice is reserved with the largest possible count, so the kept blocks never have to be reconsidered.
+	reserved := len(fmt.Sprintf(truncatedEntriesNotice, entries))
+
+	kept := make([]changelogBlock, 0, len(blocks))
+	length := 0
+	for _, block := range blocks {
+		if length+len(block.text)+reserved > maxLength {
+			break
+		}
+
+		kept = append(kept, block)
+		length += len(block.text)
+	}
+
+	// Headings and paragraphs at the end belong to the entries that were removed.
+	for len(kept) > 0 && !kept[len(kept)-1].entry {
+		kept = kept[:len(kept)-1]
+	}
+
+	var truncated strings.Builder
+	keptEntries := 0
+	for _, block := range kept {
+		truncated.WriteString(block.text)
+		if block.entry {
+			keptEntries++
+		}
+	}
+
+	if keptEntries < entries {
+		truncated.WriteString(fmt.Sprintf(truncatedEntriesNotice, entries-keptEntries))
+	} else {
+		truncated.WriteString(truncatedChangelogNotice)
+	}
+
+	return truncated.String()
+}
+
+type changelogBlock struct {
+	text  string
+	entry bool
+}
+
+// changelogBlocks splits the changelog into entries and the lines between them.
+func changelogBlocks(changelog string) []changelogBlock {
+	var blocks []changelogBlock
+
+	for _, line := range strings.SplitAfter(changelog, "\n") {
+		if line == "" {
+			continue
+		}
+
+		isEntry := strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+		isContinuation := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+
+		switch {
+		case isEntry:
+			blocks = append(blocks, changelogBlock{text: line, entry: true})
+		case isContinuation && len(blocks) > 0 && blocks[len(blocks)-1].entry:
+			blocks[len(blocks)-1].text += line
+		default:
+			blocks = append(blocks, changelogBlock{text: line})
+		}
+	}
+
+	return blocks
+}
@@ -0,0 +1,87 @@
+package releasepr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_truncateChangelog(t *testing.T) {
+	changelog := "### Features\n\n- foo\n- bar\n  with details\n\n### Bug Fixes\n\n- " + strings.Repeat("baz ", 100) + "\n"
+
+	tests := []struct {
+		name      string
+		changelog string
+		maxLength int
+		want      string
+	}{
+		{
+			name:      "short enough",
+			changelog: changelog,
+			maxLength: len(changelog),
+			want:      changelog,
+		},
+		{
+			name:      "removes last section",
+			changelog: changelog,
+			maxLength: len("### Features\n\n- foo\n- bar\n  with details\n") + len(fmt.Sprintf(truncatedEntriesNotice, 3)),
+			want:      "### Features\n\n- foo\n- bar\n  with details\n" + "\n_… changes not shown: 1. The full changelog is in the changelog file of this pull request._\n",
+		},
+		{
+			name:      "keeps nested lines with the entry",
+			changelog: changelog,
+			maxLength: len("### Features\n\n- foo\n- bar\n") + len(fmt.Sprintf(truncatedEntriesNotice, 3)),
+			want:      "### Features\n\n- foo\n" + "\n_… changes not shown: 2. The full changelog is in the changelog file of this pull request._\n",
+		},
+		{
+			name:      "no entries fit",
+			changelog: changelog,
+			maxLength: len(fmt.Sprintf(truncatedEntriesNotice, 3)),
+			want:      "\n_… changes not shown: 3. The full changelog is in the changelog file of this pull request._\n",
+		},
+		{
+			name:      "without entries",
+			changelog: strings.Repeat("A very long prefix.\n", 100),
+			maxLength: 200,
+			want:      truncatedChangelogNotice,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateChangelog(tt.changelog, tt.maxLength)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len(got), tt.maxLength)
+		})
+	}
+}
+
+func TestReleasePullRequest_SetDescriptionWithLimit(t *testing.T) {
+	overrides := ReleaseOverrides{
+		Prefix:      "This release is awesome!",
+		NextVersion: "v2.0.0",
+	}
+	changelogEntry := "### Features\n\n" + strings.Repeat("- Foobar!\n", 1000)
+
+	omitted := []OmittedChange{{Title: "docs: foo", PullRequestID: 1, PullRequestURL: "https://example.com/pull/1"}}
+
+	pr := &ReleasePullRequest{}
+	err := pr.SetDescriptionWithLimit(changelogEntry, overrides, omitted, 4000)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(pr.Description), 4000)
+	assert.NotContains(t, pr.Description, "docs: foo")
+
+	gotOverrides, err := pr.GetOverrides()
+	require.NoError(t, err)
+	assert.Equal(t, overrides, gotOverrides)
+
+	gotChangelog, err := pr.ChangelogText()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(gotChangelog, "### Features\n\n- Foobar!\n"))
+	assert.Contains(t, gotChangelog, "changes not shown: ")
+
+	err = pr.SetDescriptionWithLimit(changelogEntry, overrides, nil, 100)
+	assert.Error(t, err)
+}
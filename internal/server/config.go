@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultConfigPath = "releaser-pleaser-server.yaml"
+	DefaultSecretEnv  = "RELEASER_PLEASER_WEBHOOK_SECRET"
+	DefaultBranch     = "main"
+)
+
+// Config lists the repositories that are handled by the server. It is read from a YAML file, usually
+// DefaultConfigPath.
+type Config struct {
+	Repositories []Repository `yaml:"repositories"`
+}
+
+// Repository is a repository on a forge that is released when webhooks for it are received.
+type Repository struct {
+	// Forge is the name of the forge, e.g. "github". Required.
+	Forge string `yaml:"forge"`
+	// Owner of the repository, e.g. the user, organization, group or workspace. Required.
+	Owner string `yaml:"owner"`
+	// Repo is the name of the repository. Required.
+	Repo string `yaml:"repo"`
+	// Branch is the branch that is released. Defaults to DefaultBranch.
+	Branch string `yaml:"branch"`
+	// Config is the path of the releaser-pleaser config file of the repository. Defaults to the default of the rp
+	// run command.
+	Config string `yaml:"config"`
+	// SecretEnv is the environment variable that holds the secret of the webhook. Defaults to DefaultSecretEnv.
+	SecretEnv string `yaml:"secret-env"`
+}
+
+// Key identifies the repository and branch in logs and the queue.
+func (r Repository) Key() string {
+	return r.Forge + ":" + r.Owner + "/" + r.Repo + "@" + r.Branch
+}
+
+// secret returns the webhook secret from the environment.
+func (r Repository) secret() string {
+	return os.Getenv(r.SecretEnv)
+}
+
+// LoadConfig reads the config file at path.
+func LoadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read server config file: %w", err)
+	}
+
+	return ParseConfig(content)
+}
+
+// ParseConfig decodes and validates the YAML config and applies the defaults.
+func ParseConfig(content []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse server config file: %w", err)
+	}
+
+	if len(cfg.Repositories) == 0 {
+		return Config{}, errors.New("invalid server config file: no repositories configured")
+	}
+
+	seen := make(map[string]bool, len(cfg.Repositories))
+	for i := range cfg.Repositories {
+		repo := &cfg.Repositories[i]
+		if repo.Branch == "" {
+			repo.Branch = DefaultBranch
+		}
+		if repo.SecretEnv == "" {
+			repo.SecretEnv = DefaultSecretEnv
+		}
+
+		if repo.Forge == "" || repo.Owner == "" || repo.Repo == "" {
+			return Config{}, fmt.Errorf("invalid server config file: repositories[%d]: forge, owner and repo are required", i)
+		}
+		if seen[repo.Key()] {
+			return Config{}, fmt.Errorf("invalid server config file: repositories[%d]: %s is configured twice", i, repo.Key())
+		}
+		seen[repo.Key()] = true
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RunFunc runs releaser-pleaser for the repository.
+type RunFunc func(ctx context.Context, repo Repository) error
+
+// queue coalesces bursts of webhooks for the same repository into a single run. Runs for different repositories are
+// executed in parallel, runs for the same repository never overlap.
+type queue struct {
+	logger *slog.Logger
+	run    RunFunc
+	// delay is waited after the first webhook of a burst, before the run is started.
+	delay time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*queueEntry
+	wg      sync.WaitGroup
+}
+
+type queueEntry struct {
+	// dirty is set if another webhook was received after the run was started. Another run is started afterward.
+	dirty bool
+}
+
+func newQueue(logger *slog.Logger, run RunFunc, delay time.Duration) *queue {
+	return &queue{
+		logger:  logger,
+		run:     run,
+		delay:   delay,
+		pending: make(map[string]*queueEntry),
+	}
+}
+
+// enqueue schedules a run for the repository. It does not block.
+func (q *queue) enqueue(ctx context.Context, repo Repository) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := repo.Key()
+	if entry, ok := q.pending[key]; ok {
+		entry.dirty = true
+		return
+	}
+
+	entry := &queueEntry{}
+	q.pending[key] = entry
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.work(ctx, repo, entry)
+	}()
+}
+
+func (q *queue) work(ctx context.Context, repo Repository, entry *queueEntry) {
+	key := repo.Key()
+	logger := q.logger.With("repository", key)
+
+	for {
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			delete(q.pending, key)
+			q.mu.Unlock()
+			return
+		case <-time.After(q.delay):
+		}
+
+		q.mu.Lock()
+		entry.dirty = false
+		q.mu.Unlock()
+
+		logger.InfoContext(ctx, "starting run")
+		if err := q.run(ctx, repo); err != nil {
+			logger.ErrorContext(ctx, "run failed", "err", err)
+		} else {
+			logger.InfoContext(ctx, "run finished")
+		}
+
+		q.mu.Lock()
+		if !entry.dirty {
+			delete(q.pending, key)
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		logger.DebugContext(ctx, "received webhooks during run, running again")
+	}
+}
+
+// wait blocks until all runs have finished.
+func (q *queue) wait() {
+	q.wg.Wait()
+}
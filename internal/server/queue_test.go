@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_Coalesce(t *testing.T) {
+	repo := Repository{Forge: "github", Owner: "apricote", Repo: "releaser-pleaser", Branch: "main"}
+	other := Repository{Forge: "github", Owner: "apricote", Repo: "other", Branch: "main"}
+
+	var runs atomic.Int32
+	q := newQueue(slog.Default(), func(_ context.Context, _ Repository) error {
+		runs.Add(1)
+		return nil
+	}, 50*time.Millisecond)
+
+	// A burst of webhooks is coalesced into a single run per repository.
+	for range 10 {
+		q.enqueue(context.Background(), repo)
+	}
+	q.enqueue(context.Background(), other)
+	q.wait()
+
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestQueue_RerunAfterWebhookDuringRun(t *testing.T) {
+	repo := Repository{Forge: "github", Owner: "apricote", Repo: "releaser-pleaser", Branch: "main"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var runs atomic.Int32
+	q := newQueue(slog.Default(), func(_ context.Context, _ Repository) error {
+		if runs.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	}, 0)
+
+	q.enqueue(context.Background(), repo)
+	<-started
+	// Webhooks that are received during the run trigger exactly one more run.
+	q.enqueue(context.Background(), repo)
+	q.enqueue(context.Background(), repo)
+	close(release)
+	q.wait()
+
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestQueue_Canceled(t *testing.T) {
+	repo := Repository{Forge: "github", Owner: "apricote", Repo: "releaser-pleaser", Branch: "main"}
+
+	var runs atomic.Int32
+	q := newQueue(slog.Default(), func(_ context.Context, _ Repository) error {
+		runs.Add(1)
+		return nil
+	}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.enqueue(ctx, repo)
+	cancel()
+	q.wait()
+
+	assert.Equal(t, int32(0), runs.Load())
+}
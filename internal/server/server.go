@@ -0,0 +1,152 @@
+// Package server implements the webhook server of releaser-pleaser. It runs releaser-pleaser for a repository
+// whenever the forge reports a change to the released branch or the release pull request.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCoalesceDelay = 10 * time.Second
+
+	// maxBodySize is the largest webhook payload that is accepted, it matches the limit of GitHub.
+	maxBodySize = 25 << 20
+	// shutdownTimeout is the time that requests in flight have to finish after the server was stopped.
+	shutdownTimeout = 10 * time.Second
+)
+
+type Server struct {
+	logger       *slog.Logger
+	repositories []Repository
+	queue        *queue
+}
+
+// New creates the server for the configured repositories. run is called for every burst of webhooks of a
+// repository, after waiting for coalesceDelay.
+func New(logger *slog.Logger, cfg Config, run RunFunc, coalesceDelay time.Duration) (*Server, error) {
+	for _, repo := range cfg.Repositories {
+		if _, ok := webhooks[repo.Forge]; !ok {
+			return nil, fmt.Errorf("%s: %w", repo.Key(), ErrUnknownForge)
+		}
+		if repo.secret() == "" {
+			return nil, fmt.Errorf("%s: webhook secret is not set, set the environment variable %s", repo.Key(), repo.SecretEnv)
+		}
+	}
+
+	return &Server{
+		logger:       logger,
+		repositories: cfg.Repositories,
+		queue:        newQueue(logger, run, coalesceDelay),
+	}, nil
+}
+
+// ListenAndServe accepts webhooks on addr until ctx is canceled. Afterward, it waits for all runs to finish.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(ctx),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.InfoContext(ctx, "listening for webhooks", "addr", addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		s.logger.InfoContext(ctx, "shutting down, waiting for runs to finish")
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		err = httpServer.Shutdown(shutdownCtx)
+	}
+
+	s.queue.wait()
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Handler returns the HTTP handler for the webhooks. The runs are canceled when ctx is canceled.
+//
+// Webhooks are received at "POST /webhook/{forge}", e.g. "/webhook/github". "GET /healthz" can be used for health
+// checks.
+func (s *Server) Handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /webhook/{forge}", func(w http.ResponseWriter, r *http.Request) {
+		s.handleWebhook(ctx, w, r)
+	})
+	return mux
+}
+
+func (s *Server) handleWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	forgeName := r.PathValue("forge")
+	logger := s.logger.With("forge", forgeName)
+
+	hook, ok := webhooks[forgeName]
+	if !ok {
+		http.Error(w, ErrUnknownForge.Error(), http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	events, err := hook.parse(r.Header, body)
+	if err != nil {
+		logger.WarnContext(r.Context(), "received invalid webhook", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var matched []Repository
+	for _, event := range events {
+		for _, repo := range s.repositories {
+			if repo.Forge == forgeName && strings.EqualFold(repo.Owner, event.Owner) &&
+				strings.EqualFold(repo.Repo, event.Repo) && repo.Branch == event.Branch {
+				matched = append(matched, repo)
+				logger.InfoContext(r.Context(), "received webhook", "repository", repo.Key(), "reason", event.Reason)
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		// Webhooks for other branches or events are expected, the forge does not need to retry them.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// The payload is only trusted after the signature was verified with the secret of the repository. All matched
+	// repositories are the same repository on the forge, so they share the webhook.
+	for _, repo := range matched {
+		if err := hook.verify(r.Header, body, repo.secret()); err != nil {
+			logger.WarnContext(r.Context(), "rejected webhook", "repository", repo.Key(), "err", err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	for _, repo := range matched {
+		s.queue.enqueue(ctx, repo)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "defaults",
+			content: `repositories:
+  - forge: github
+    owner: apricote
+    repo: releaser-pleaser
+`,
+			want: Config{Repositories: []Repository{{
+				Forge:     "github",
+				Owner:     "apricote",
+				Repo:      "releaser-pleaser",
+				Branch:    DefaultBranch,
+				SecretEnv: DefaultSecretEnv,
+			}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "full",
+			content: `repositories:
+  - forge: gitlab
+    owner: group/subgroup
+    repo: project
+    branch: release-1.x
+    config: /etc/rp/project.yaml
+    secret-env: PROJECT_SECRET
+`,
+			want: Config{Repositories: []Repository{{
+				Forge:     "gitlab",
+				Owner:     "group/subgroup",
+				Repo:      "project",
+				Branch:    "release-1.x",
+				Config:    "/etc/rp/project.yaml",
+				SecretEnv: "PROJECT_SECRET",
+			}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "no repositories",
+			content: `repositories: []`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing repo",
+			content: `repositories:
+  - forge: github
+    owner: apricote
+`,
+			wantErr: assert.Error,
+		},
+		{
+			name: "duplicate",
+			content: `repositories:
+  - forge: github
+    owner: apricote
+    repo: releaser-pleaser
+  - forge: github
+    owner: apricote
+    repo: releaser-pleaser
+    branch: main
+`,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConfig([]byte(tt.content))
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	t.Setenv(DefaultSecretEnv, "secret")
+
+	cfg := Config{Repositories: []Repository{
+		{Forge: "github", Owner: "apricote", Repo: "releaser-pleaser", Branch: "main", SecretEnv: DefaultSecretEnv},
+	}}
+
+	pushMain := `{"ref":"refs/heads/main","repository":{"full_name":"apricote/releaser-pleaser"}}`
+	pushOther := `{"ref":"refs/heads/feature","repository":{"full_name":"apricote/releaser-pleaser"}}`
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		header   http.Header
+		wantCode int
+		wantRuns int
+	}{
+		{
+			name:     "push to released branch",
+			path:     "/webhook/github",
+			body:     pushMain,
+			header:   http.Header{"X-Github-Event": {"push"}, "X-Hub-Signature-256": {sign(pushMain, "secret")}},
+			wantCode: http.StatusAccepted,
+			wantRuns: 1,
+		},
+		{
+			name:     "push to other branch",
+			path:     "/webhook/github",
+			body:     pushOther,
+			header:   http.Header{"X-Github-Event": {"push"}, "X-Hub-Signature-256": {sign(pushOther, "secret")}},
+			wantCode: http.StatusNoContent,
+			wantRuns: 0,
+		},
+		{
+			name:     "invalid signature",
+			path:     "/webhook/github",
+			body:     pushMain,
+			header:   http.Header{"X-Github-Event": {"push"}, "X-Hub-Signature-256": {sign(pushMain, "other")}},
+			wantCode: http.StatusUnauthorized,
+			wantRuns: 0,
+		},
+		{
+			name:     "unknown forge",
+			path:     "/webhook/gitea",
+			body:     pushMain,
+			header:   http.Header{},
+			wantCode: http.StatusNotFound,
+			wantRuns: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			runs := 0
+			run := func(_ context.Context, repo Repository) error {
+				mu.Lock()
+				defer mu.Unlock()
+				assert.Equal(t, cfg.Repositories[0], repo)
+				runs++
+				return nil
+			}
+
+			server, err := New(slog.Default(), cfg, run, 0)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header = tt.header
+			rec := httptest.NewRecorder()
+			server.Handler(context.Background()).ServeHTTP(rec, req)
+			server.queue.wait()
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantRuns, runs)
+		})
+	}
+}
+
+func TestNew_MissingSecret(t *testing.T) {
+	t.Setenv(DefaultSecretEnv, "")
+
+	cfg := Config{Repositories: []Repository{
+		{Forge: "github", Owner: "apricote", Repo: "releaser-pleaser", Branch: "main", SecretEnv: DefaultSecretEnv},
+	}}
+
+	_, err := New(slog.Default(), cfg, nil, time.Second)
+	assert.Error(t, err)
+}
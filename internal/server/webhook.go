@@ -0,0 +1,253 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidSignature is returned if the webhook was not signed with the secret of the repository.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownForge is returned for webhooks of forges that are not supported by the server.
+	ErrUnknownForge = errors.New("unknown forge")
+)
+
+// Event is a webhook that may require a run of releaser-pleaser.
+type Event struct {
+	// Owner and Repo of the repository that sent the webhook.
+	Owner string
+	Repo  string
+	// Branch that was pushed to or that the pull request was merged into.
+	Branch string
+	// Reason describes the event for the logs, e.g. "push" or "pull request merged".
+	Reason string
+}
+
+// webhook parses and verifies the webhooks of a forge.
+type webhook interface {
+	// parse returns no events if the webhook is not relevant for releaser-pleaser, e.g. pushes of tags.
+	parse(header http.Header, body []byte) ([]Event, error)
+	// verify checks that the webhook was sent by the forge.
+	verify(header http.Header, body []byte, secret string) error
+}
+
+var webhooks = map[string]webhook{
+	"github":    githubWebhook{},
+	"gitlab":    gitlabWebhook{},
+	"bitbucket": bitbucketWebhook{},
+}
+
+// splitFullName splits "owner/repo" into owner and repo. The owner may contain slashes on forges with nested groups.
+func splitFullName(fullName string) (string, string) {
+	i := strings.LastIndex(fullName, "/")
+	if i < 0 {
+		return "", fullName
+	}
+	return fullName[:i], fullName[i+1:]
+}
+
+// verifyHMAC checks the signature of the body in the format "sha256=<hex>".
+func verifyHMAC(signature string, body []byte, secret string) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+type githubWebhook struct{}
+
+func (githubWebhook) parse(header http.Header, body []byte) ([]Event, error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		Action     string `json:"action"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		PullRequest struct {
+			Merged bool `json:"merged"`
+			Base   struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		} `json:"pull_request"`
+	}
+
+	var branch, reason string
+	switch header.Get("X-GitHub-Event") {
+	case "push":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		var ok bool
+		branch, ok = strings.CutPrefix(payload.Ref, "refs/heads/")
+		if !ok {
+			return nil, nil
+		}
+		reason = "push"
+	case "pull_request":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		switch {
+		case payload.Action == "closed" && payload.PullRequest.Merged:
+			reason = "pull request merged"
+		case payload.Action == "labeled" || payload.Action == "unlabeled" || payload.Action == "edited":
+			// Labels and the description of the release pull request change the next release.
+			reason = "pull request " + payload.Action
+		default:
+			return nil, nil
+		}
+		branch = payload.PullRequest.Base.Ref
+	default:
+		return nil, nil
+	}
+
+	owner, repo := splitFullName(payload.Repository.FullName)
+	return []Event{{Owner: owner, Repo: repo, Branch: branch, Reason: reason}}, nil
+}
+
+func (githubWebhook) verify(header http.Header, body []byte, secret string) error {
+	return verifyHMAC(header.Get("X-Hub-Signature-256"), body, secret)
+}
+
+type gitlabWebhook struct{}
+
+func (gitlabWebhook) parse(header http.Header, body []byte) ([]Event, error) {
+	var payload struct {
+		Ref     string `json:"ref"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			Action       string `json:"action"`
+			TargetBranch string `json:"target_branch"`
+		} `json:"object_attributes"`
+	}
+
+	var branch, reason string
+	switch header.Get("X-Gitlab-Event") {
+	case "Push Hook":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		var ok bool
+		branch, ok = strings.CutPrefix(payload.Ref, "refs/heads/")
+		if !ok {
+			return nil, nil
+		}
+		reason = "push"
+	case "Merge Request Hook":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		switch payload.ObjectAttributes.Action {
+		case "merge":
+			reason = "merge request merged"
+		case "update":
+			// Labels and the description of the release merge request change the next release.
+			reason = "merge request updated"
+		default:
+			return nil, nil
+		}
+		branch = payload.ObjectAttributes.TargetBranch
+	default:
+		return nil, nil
+	}
+
+	owner, repo := splitFullName(payload.Project.PathWithNamespace)
+	return []Event{{Owner: owner, Repo: repo, Branch: branch, Reason: reason}}, nil
+}
+
+func (gitlabWebhook) verify(header http.Header, _ []byte, secret string) error {
+	// GitLab does not sign the webhook, it sends the secret token as is.
+	if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+type bitbucketWebhook struct{}
+
+func (bitbucketWebhook) parse(header http.Header, body []byte) ([]Event, error) {
+	var payload struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type string `json:"type"`
+					Name string `json:"name"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+		PullRequest struct {
+			Destination struct {
+				Branch struct {
+					Name string `json:"name"`
+				} `json:"branch"`
+			} `json:"destination"`
+		} `json:"pullrequest"`
+	}
+
+	var branches []string
+	var reason string
+	switch header.Get("X-Event-Key") {
+	case "repo:push":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		// A single push may update several branches.
+		for _, change := range payload.Push.Changes {
+			if change.New != nil && change.New.Type == "branch" {
+				branches = append(branches, change.New.Name)
+			}
+		}
+		reason = "push"
+	case "pullrequest:fulfilled":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		branches = []string{payload.PullRequest.Destination.Branch.Name}
+		reason = "pull request merged"
+	case "pullrequest:updated":
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook: %w", err)
+		}
+		// Labels and the description of the release pull request change the next release.
+		branches = []string{payload.PullRequest.Destination.Branch.Name}
+		reason = "pull request updated"
+	default:
+		return nil, nil
+	}
+
+	owner, repo := splitFullName(payload.Repository.FullName)
+	events := make([]Event, 0, len(branches))
+	for _, branch := range branches {
+		events = append(events, Event{Owner: owner, Repo: repo, Branch: branch, Reason: reason})
+	}
+	return events, nil
+}
+
+func (bitbucketWebhook) verify(header http.Header, body []byte, secret string) error {
+	return verifyHMAC(header.Get("X-Hub-Signature"), body, secret)
+}
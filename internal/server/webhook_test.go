@@ -0,0 +1,203 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhook_parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		forge   string
+		header  http.Header
+		body    string
+		want    []Event
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "github push",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"push"}},
+			body:    `{"ref":"refs/heads/main","repository":{"full_name":"apricote/releaser-pleaser"}}`,
+			want:    []Event{{Owner: "apricote", Repo: "releaser-pleaser", Branch: "main", Reason: "push"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github push of tag",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"push"}},
+			body:    `{"ref":"refs/tags/v1.0.0","repository":{"full_name":"apricote/releaser-pleaser"}}`,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github pull request merged",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"pull_request"}},
+			body:    `{"action":"closed","pull_request":{"merged":true,"base":{"ref":"main"}},"repository":{"full_name":"apricote/releaser-pleaser"}}`,
+			want:    []Event{{Owner: "apricote", Repo: "releaser-pleaser", Branch: "main", Reason: "pull request merged"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github pull request closed",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"pull_request"}},
+			body:    `{"action":"closed","pull_request":{"merged":false,"base":{"ref":"main"}},"repository":{"full_name":"apricote/releaser-pleaser"}}`,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github pull request labeled",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"pull_request"}},
+			body:    `{"action":"labeled","pull_request":{"base":{"ref":"main"}},"repository":{"full_name":"apricote/releaser-pleaser"}}`,
+			want:    []Event{{Owner: "apricote", Repo: "releaser-pleaser", Branch: "main", Reason: "pull request labeled"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github ping",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"ping"}},
+			body:    `{"zen":"Keep it logically awesome."}`,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github invalid payload",
+			forge:   "github",
+			header:  http.Header{"X-Github-Event": {"push"}},
+			body:    `{`,
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "gitlab push in subgroup",
+			forge:   "gitlab",
+			header:  http.Header{"X-Gitlab-Event": {"Push Hook"}},
+			body:    `{"ref":"refs/heads/main","project":{"path_with_namespace":"group/subgroup/project"}}`,
+			want:    []Event{{Owner: "group/subgroup", Repo: "project", Branch: "main", Reason: "push"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "gitlab merge request merged",
+			forge:   "gitlab",
+			header:  http.Header{"X-Gitlab-Event": {"Merge Request Hook"}},
+			body:    `{"object_attributes":{"action":"merge","target_branch":"main"},"project":{"path_with_namespace":"group/project"}}`,
+			want:    []Event{{Owner: "group", Repo: "project", Branch: "main", Reason: "merge request merged"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "gitlab merge request opened",
+			forge:   "gitlab",
+			header:  http.Header{"X-Gitlab-Event": {"Merge Request Hook"}},
+			body:    `{"object_attributes":{"action":"open","target_branch":"main"},"project":{"path_with_namespace":"group/project"}}`,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "bitbucket push of multiple branches",
+			forge:  "bitbucket",
+			header: http.Header{"X-Event-Key": {"repo:push"}},
+			body:   `{"repository":{"full_name":"workspace/repo"},"push":{"changes":[{"new":{"type":"branch","name":"main"}},{"new":{"type":"tag","name":"v1.0.0"}},{"new":null},{"new":{"type":"branch","name":"release-1.x"}}]}}`,
+			want: []Event{
+				{Owner: "workspace", Repo: "repo", Branch: "main", Reason: "push"},
+				{Owner: "workspace", Repo: "repo", Branch: "release-1.x", Reason: "push"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "bitbucket pull request merged",
+			forge:   "bitbucket",
+			header:  http.Header{"X-Event-Key": {"pullrequest:fulfilled"}},
+			body:    `{"repository":{"full_name":"workspace/repo"},"pullrequest":{"destination":{"branch":{"name":"main"}}}}`,
+			want:    []Event{{Owner: "workspace", Repo: "repo", Branch: "main", Reason: "pull request merged"}},
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := webhooks[tt.forge].parse(tt.header, []byte(tt.body))
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWebhook_verify(t *testing.T) {
+	body := `{"ref":"refs/heads/main"}`
+
+	tests := []struct {
+		name    string
+		forge   string
+		header  http.Header
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "github valid signature",
+			forge:   "github",
+			header:  http.Header{"X-Hub-Signature-256": {sign(body, "secret")}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "github wrong secret",
+			forge:   "github",
+			header:  http.Header{"X-Hub-Signature-256": {sign(body, "other")}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "github missing signature",
+			forge:   "github",
+			header:  http.Header{},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "github malformed signature",
+			forge:   "github",
+			header:  http.Header{"X-Hub-Signature-256": {"sha256=zz"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "gitlab valid token",
+			forge:   "gitlab",
+			header:  http.Header{"X-Gitlab-Token": {"secret"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "gitlab wrong token",
+			forge:   "gitlab",
+			header:  http.Header{"X-Gitlab-Token": {"other"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "bitbucket valid signature",
+			forge:   "bitbucket",
+			header:  http.Header{"X-Hub-Signature": {sign(body, "secret")}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "bitbucket wrong secret",
+			forge:   "bitbucket",
+			header:  http.Header{"X-Hub-Signature": {sign(body, "other")}},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantErr(t, webhooks[tt.forge].verify(tt.header, []byte(body), "secret"))
+		})
+	}
+}
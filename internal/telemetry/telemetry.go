@@ -0,0 +1,35 @@
+// Package telemetry instruments releaser-pleaser with OpenTelemetry. It uses the global providers of the otel package,
+// which discard all spans and metrics unless the application sets them up.
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of all spans and metrics.
+const ScopeName = "github.com/apricote/releaser-pleaser"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(ScopeName)
+}
+
+// Start starts a span that is a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, and ends the span. It is meant to be deferred with a named error result:
+//
+//	defer func() { telemetry.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MetricAPIRequests  = "releaser_pleaser.forge.api.requests"
+	MetricAPIDuration  = "releaser_pleaser.forge.api.duration"
+	MetricAPIRateLimit = "releaser_pleaser.forge.api.rate_limited"
+)
+
+type instruments struct {
+	requests    metric.Int64Counter
+	duration    metric.Float64Histogram
+	rateLimited metric.Int64Counter
+}
+
+func newInstruments() instruments {
+	meter := otel.Meter(ScopeName)
+
+	// The errors can be ignored, the instruments are still usable and do nothing.
+	requests, _ := meter.Int64Counter(MetricAPIRequests,
+		metric.WithDescription("Number of requests made to the API of the forge"),
+		metric.WithUnit("{request}"))
+	duration, _ := meter.Float64Histogram(MetricAPIDuration,
+		metric.WithDescription("Duration of requests made to the API of the forge"),
+		metric.WithUnit("s"))
+	rateLimited, _ := meter.Int64Counter(MetricAPIRateLimit,
+		metric.WithDescription("Number of requests to the API of the forge that were rejected because of a rate limit"),
+		metric.WithUnit("{request}"))
+
+	return instruments{requests: requests, duration: duration, rateLimited: rateLimited}
+}
+
+// transport records a span and metrics for every request to the API of a forge.
+type transport struct {
+	forge       string
+	base        http.RoundTripper
+	instruments instruments
+}
+
+// Transport wraps base, so every request is traced and counted with the name of the forge as an attribute. If base is
+// nil, http.DefaultTransport is used.
+func Transport(forge string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{forge: forge, base: base, instruments: newInstruments()}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("forge", t.forge),
+		attribute.String("http.request.method", req.Method),
+	}
+
+	ctx, span := tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		// The full URL is not recorded, as the query might contain secrets.
+		trace.WithAttributes(attribute.String("server.address", req.URL.Host), attribute.String("url.path", req.URL.Path)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, attribute.String("error.type", "transport"))
+	} else {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+		}
+		attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+
+	inst := t.instruments
+	inst.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+	inst.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+	if resp != nil && isRateLimited(resp) {
+		span.AddEvent("rate limited")
+		inst.rateLimited.Add(ctx, 1, metric.WithAttributes(attribute.String("forge", t.forge)))
+	}
+
+	return resp, err
+}
+
+// isRateLimited detects the responses of GitHub, GitLab and Bitbucket to requests that exceeded the rate limit.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
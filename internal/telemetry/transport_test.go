@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		header          http.Header
+		wantRateLimited int64
+	}{
+		{
+			name:            "ok",
+			status:          http.StatusOK,
+			wantRateLimited: 0,
+		},
+		{
+			name:            "too many requests",
+			status:          http.StatusTooManyRequests,
+			wantRateLimited: 1,
+		},
+		{
+			name:            "github primary rate limit",
+			status:          http.StatusForbidden,
+			header:          http.Header{"X-Ratelimit-Remaining": {"0"}},
+			wantRateLimited: 1,
+		},
+		{
+			name:            "forbidden",
+			status:          http.StatusForbidden,
+			wantRateLimited: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			previous := otel.GetMeterProvider()
+			otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+			t.Cleanup(func() { otel.SetMeterProvider(previous) })
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: Transport("github", nil)}
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(context.Background(), &rm))
+
+			assert.Equal(t, int64(1), counterValue(rm, MetricAPIRequests))
+			assert.Equal(t, tt.wantRateLimited, counterValue(rm, MetricAPIRateLimit))
+		})
+	}
+}
+
+// counterValue sums all data points of the counter.
+func counterValue(rm metricdata.ResourceMetrics, name string) int64 {
+	var value int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == name {
+				for _, dp := range sum.DataPoints {
+					value += dp.Value
+				}
+			}
+		}
+	}
+	return value
+}
@@ -3,6 +3,9 @@ package updater
 import (
 	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/markdown"
 )
 
 const (
@@ -14,19 +17,70 @@ var (
 	ChangelogUpdaterHeaderRegex = regexp.MustCompile(`^# Changelog\n`)
 )
 
+// Changelog adds the changelog entry of the release above the previous releases. Any text between the header and the
+// first release is kept. If the file is empty, it is created with the header and the ChangelogPreamble.
 func Changelog(info ReleaseInfo) Updater {
 	return func(content string) (string, error) {
-		headerIndex := ChangelogUpdaterHeaderRegex.FindStringIndex(content)
-		if headerIndex == nil && len(content) != 0 {
+		if len(content) == 0 {
+			content = ChangelogHeader + "\n"
+			if preamble := strings.TrimSpace(info.ChangelogPreamble); preamble != "" {
+				content += "\n" + preamble + "\n"
+			}
+		}
+
+		if !ChangelogUpdaterHeaderRegex.MatchString(content) {
 			return "", fmt.Errorf("unexpected format of CHANGELOG.md, header does not match")
 		}
-		if headerIndex != nil {
-			// Remove the header from the content
-			content = content[headerIndex[1]:]
+
+		offset, err := firstReleaseOffset(content, info.ChangelogEntry)
+		if err != nil {
+			return "", err
+		}
+
+		entry := info.ChangelogEntry
+		previousReleases := content[offset:]
+		if previousReleases != "" {
+			entry = strings.TrimRight(entry, "\n") + "\n\n"
 		}
 
-		content = ChangelogHeader + "\n\n" + info.ChangelogEntry + content
+		return strings.TrimRight(content[:offset], "\n") + "\n\n" + entry + previousReleases, nil
+	}
+}
+
+// firstReleaseOffset returns the position of the first release in the changelog, or the end of the content if there is
+// none. Releases are the headings with the same level as the first heading of the entry, or level 2 by default.
+// Headings with a higher level, e.g. in the preamble, are not considered releases.
+func firstReleaseOffset(content, entry string) (int, error) {
+	var entryHeadings []markdown.Heading
+	err := markdown.WalkAST([]byte(entry), markdown.GetHeadings([]byte(entry), &entryHeadings))
+	if err != nil {
+		return 0, err
+	}
+
+	releaseLevel := 2
+	if len(entryHeadings) > 0 && entryHeadings[0].Level > 1 {
+		releaseLevel = entryHeadings[0].Level
+	}
+
+	var headings []markdown.Heading
+	err = markdown.WalkAST([]byte(content), markdown.GetHeadings([]byte(content), &headings))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, heading := range headings {
+		if heading.Level > 1 && heading.Level <= releaseLevel {
+			return heading.Offset, nil
+		}
+	}
+
+	return len(content), nil
+}
 
-		return content, nil
+// ChangelogEntry replaces the content of the file with the changelog entry, for files that only describe a single
+// release.
+func ChangelogEntry(info ReleaseInfo) Updater {
+	return func(string) (string, error) {
+		return info.ChangelogEntry, nil
 	}
 }
@@ -0,0 +1,34 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const ChangelogJSONFile = "CHANGELOG.json"
+
+// ChangelogJSON adds the changelog entry of the release to the start of the JSON array of previous releases. The entry
+// must be a JSON object, as rendered by changelog.JSONRenderer. If the file is empty, it is created with an array.
+func ChangelogJSON(info ReleaseInfo) Updater {
+	return func(content string) (string, error) {
+		var releases []json.RawMessage
+		if strings.TrimSpace(content) != "" {
+			if err := json.Unmarshal([]byte(content), &releases); err != nil {
+				return "", fmt.Errorf("unexpected format of %s, expected an array of releases: %w", ChangelogJSONFile, err)
+			}
+		}
+
+		entry := json.RawMessage(info.ChangelogEntry)
+		if !json.Valid(entry) {
+			return "", fmt.Errorf("changelog entry is not valid JSON")
+		}
+
+		out, err := json.MarshalIndent(append([]json.RawMessage{entry}, releases...), "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		return string(out) + "\n", nil
+	}
+}
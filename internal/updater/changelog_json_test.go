@@ -0,0 +1,63 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangelogJSONUpdater_UpdateContent(t *testing.T) {
+	tests := []updaterTestCase{
+		{
+			name:    "empty file",
+			content: "",
+			info:    ReleaseInfo{ChangelogEntry: "{\"version\": \"v1.0.0\"}\n"},
+			want: `[
+  {
+    "version": "v1.0.0"
+  }
+]
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "previous releases",
+			content: `[
+  {
+    "version": "v0.1.0",
+    "sections": []
+  }
+]
+`,
+			info: ReleaseInfo{ChangelogEntry: "{\n  \"version\": \"v1.0.0\"\n}\n"},
+			want: `[
+  {
+    "version": "v1.0.0"
+  },
+  {
+    "version": "v0.1.0",
+    "sections": []
+  }
+]
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "not an array",
+			content: `{"version": "v0.1.0"}`,
+			info:    ReleaseInfo{ChangelogEntry: `{"version": "v1.0.0"}`},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid entry",
+			content: "",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0\n"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, ChangelogJSON, tt)
+		})
+	}
+}
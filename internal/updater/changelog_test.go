@@ -44,6 +44,60 @@ func TestChangelogUpdater_UpdateContent(t *testing.T) {
 `,
 			wantErr: assert.NoError,
 		},
+		{
+			name:    "empty file with preamble",
+			content: "",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0\n", ChangelogPreamble: "All notable changes are documented here.\n"},
+			want:    "# Changelog\n\nAll notable changes are documented here.\n\n## v1.0.0\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "keeps preamble",
+			content: `# Changelog
+
+All notable changes are documented here.
+
+### Versioning
+
+This project uses [Semantic Versioning](https://semver.org).
+
+## v0.0.1
+
+- Bazzle
+`,
+			info: ReleaseInfo{ChangelogEntry: "## v1.0.0\n\n- Version 1, juhu.\n", ChangelogPreamble: "Ignored for existing files."},
+			want: `# Changelog
+
+All notable changes are documented here.
+
+### Versioning
+
+This project uses [Semantic Versioning](https://semver.org).
+
+## v1.0.0
+
+- Version 1, juhu.
+
+## v0.0.1
+
+- Bazzle
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "preamble without releases",
+			content: "# Changelog\n\nAll notable changes are documented here.\n",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0\n\n- Version 1, juhu.\n"},
+			want:    "# Changelog\n\nAll notable changes are documented here.\n\n## v1.0.0\n\n- Version 1, juhu.\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "headings in code blocks",
+			content: "# Changelog\n\n```md\n## Example\n```\n\n## v0.0.1\n",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0"},
+			want:    "# Changelog\n\n```md\n## Example\n```\n\n## v1.0.0\n\n## v0.0.1\n",
+			wantErr: assert.NoError,
+		},
 		{
 			name:    "error on invalid header",
 			content: "What even is this file?",
@@ -58,3 +112,27 @@ func TestChangelogUpdater_UpdateContent(t *testing.T) {
 		})
 	}
 }
+
+func TestChangelogEntryUpdater_UpdateContent(t *testing.T) {
+	tests := []updaterTestCase{
+		{
+			name:    "empty file",
+			content: "",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0\n"},
+			want:    "## v1.0.0\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "existing file",
+			content: "## v1.0.0-rc.1\n",
+			info:    ReleaseInfo{ChangelogEntry: "## v1.0.0\n"},
+			want:    "## v1.0.0\n",
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, ChangelogEntry, tt)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package updater
+
+import (
+	"regexp"
+)
+
+var goVersionConstRegex = regexp.MustCompile(`(?m)^(\s*(?:const\s+)?Version(?:\s+string)?\s*=\s*")([^"]*)(")`)
+
+// Go updates the value of the Version constant or variable in a Go source file.
+func Go(info ReleaseInfo) Updater {
+	return func(content string) (string, error) {
+		return goVersionConstRegex.ReplaceAllStringFunc(content, func(match string) string {
+			parts := goVersionConstRegex.FindStringSubmatch(match)
+			return parts[1] + versionLike(info, parts[2]) + parts[3]
+		}), nil
+	}
+}
@@ -0,0 +1,38 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoUpdater_UpdateContent(t *testing.T) {
+	tests := []updaterTestCase{
+		{
+			name:    "const",
+			content: "package version\n\nconst Version = \"v1.0.0\"\n",
+			info:    ReleaseInfo{Version: "v1.2.0"},
+			want:    "package version\n\nconst Version = \"v1.2.0\"\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "const block with type",
+			content: "package version\n\nconst (\n\tName = \"foo\"\n\tVersion string = \"1.0.0\"\n)\n",
+			info:    ReleaseInfo{Version: "v1.2.0"},
+			want:    "package version\n\nconst (\n\tName = \"foo\"\n\tVersion string = \"1.2.0\"\n)\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "no version",
+			content: "package version\n\nconst OtherVersion = \"1.0.0\"\n",
+			info:    ReleaseInfo{Version: "v1.2.0"},
+			want:    "package version\n\nconst OtherVersion = \"1.0.0\"\n",
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, Go, tt)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON updates the string value at the key in a JSON document. Nested keys are separated by ".", e.g.
+// "packages..version" selects the version of the package with the empty name. The formatting of the document is
+// preserved, only the value itself is replaced.
+func JSON(key string) NewUpdater {
+	return func(info ReleaseInfo) Updater {
+		return func(content string) (string, error) {
+			start, end, err := findJSONValue(content, strings.Split(key, "."))
+			if err != nil {
+				return "", fmt.Errorf("failed to find key %q: %w", key, err)
+			}
+
+			var existing string
+			if err = json.Unmarshal([]byte(content[start:end]), &existing); err != nil {
+				return "", err
+			}
+
+			value, err := json.Marshal(versionLike(info, existing))
+			if err != nil {
+				return "", err
+			}
+
+			return content[:start] + string(value) + content[end:], nil
+		}
+	}
+}
+
+// findJSONValue returns the start and end offset of the string value at the path.
+func findJSONValue(content string, path []string) (int, int, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	return findJSONValueInObject(dec, content, path)
+}
+
+func findJSONValueInObject(dec *json.Decoder, content string, path []string) (int, int, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return 0, 0, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return 0, 0, fmt.Errorf("expected object at %q", path[0])
+	}
+
+	for dec.More() {
+		token, err = dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if key, ok := token.(string); !ok || key != path[0] {
+			if err = skipJSONValue(dec); err != nil {
+				return 0, 0, err
+			}
+			continue
+		}
+
+		if len(path) > 1 {
+			return findJSONValueInObject(dec, content, path[1:])
+		}
+
+		keyEnd := int(dec.InputOffset())
+		token, err = dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		if _, ok := token.(string); !ok {
+			return 0, 0, fmt.Errorf("value of %q is not a string", path[0])
+		}
+		end := int(dec.InputOffset())
+
+		// Only the separator and whitespace are between the key and the opening quote of the value
+		start := keyEnd + strings.Index(content[keyEnd:end], `"`)
+
+		return start, end, nil
+	}
+
+	return 0, 0, fmt.Errorf("%w: %q", ErrKeyNotFound, path[0])
+}
+
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
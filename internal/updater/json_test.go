@@ -0,0 +1,70 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONUpdater_UpdateContent(t *testing.T) {
+	tests := []struct {
+		updaterTestCase
+		key string
+	}{
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "top-level key",
+				content: "{\n  \"name\": \"foo\",\n  \"version\": \"1.0.0\",\n  \"private\": true\n}\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "{\n  \"name\": \"foo\",\n  \"version\": \"1.2.0\",\n  \"private\": true\n}\n",
+				wantErr: assert.NoError,
+			},
+			key: "version",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "nested key after other values",
+				content: `{"version": "1.0.0", "deps": {"a": [1, {"version": "0.1.0"}]}, "packages": {"": {"version": "1.0.0"}}}`,
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    `{"version": "1.0.0", "deps": {"a": [1, {"version": "0.1.0"}]}, "packages": {"": {"version": "1.2.0"}}}`,
+				wantErr: assert.NoError,
+			},
+			key: "packages..version",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "keeps v prefix",
+				content: `{"version":"v1.0.0"}`,
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    `{"version":"v1.2.0"}`,
+				wantErr: assert.NoError,
+			},
+			key: "version",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "missing key",
+				content: `{"name": "foo"}`,
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "",
+				wantErr: assert.Error,
+			},
+			key: "version",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "non-string value",
+				content: `{"version": 1}`,
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "",
+				wantErr: assert.Error,
+			},
+			key: "version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, JSON(tt.key), tt.updaterTestCase)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package updater
+
+// File is a well-known file of an ecosystem that contains the version.
+type File struct {
+	// Path of the file relative to the root of the package.
+	Path     string
+	Updaters []NewUpdater
+}
+
+// ReleaseTypes maps the name of an ecosystem to the files that usually contain the version of a package in it. Not
+// all files need to exist in a package, and not all keys need to exist in the files, e.g. the appVersion of a Helm
+// chart is optional.
+var ReleaseTypes = map[string][]File{
+	"go": {
+		{Path: "version.go", Updaters: []NewUpdater{Go}},
+		{Path: "version/version.go", Updaters: []NewUpdater{Go}},
+	},
+	"node": {
+		{Path: "package.json", Updaters: []NewUpdater{IgnoreMissingKey(JSON("version"))}},
+		{Path: "package-lock.json", Updaters: []NewUpdater{IgnoreMissingKey(JSON("version")), IgnoreMissingKey(JSON("packages..version"))}},
+	},
+	"python": {
+		{Path: "pyproject.toml", Updaters: []NewUpdater{IgnoreMissingKey(TOML("project.version", "tool.poetry.version"))}},
+	},
+	"rust": {
+		{Path: "Cargo.toml", Updaters: []NewUpdater{IgnoreMissingKey(TOML("package.version", "workspace.package.version"))}},
+		{Path: "Cargo.lock", Updaters: []NewUpdater{CargoLock}},
+	},
+	"helm": {
+		{Path: "Chart.yaml", Updaters: []NewUpdater{IgnoreMissingKey(YAML("version")), IgnoreMissingKey(YAML("appVersion"))}},
+	},
+}
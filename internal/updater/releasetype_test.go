@@ -0,0 +1,75 @@
+package updater
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		releaseType string
+		path        string
+		content     string
+		want        string
+	}{
+		{
+			name:        "helm chart without appVersion",
+			releaseType: "helm",
+			path:        "Chart.yaml",
+			content:     "apiVersion: v2\nname: foo\nversion: 1.0.0\n",
+			want:        "apiVersion: v2\nname: foo\nversion: 1.2.0\n",
+		},
+		{
+			name:        "helm chart with appVersion",
+			releaseType: "helm",
+			path:        "Chart.yaml",
+			content:     "apiVersion: v2\nname: foo\nversion: 1.0.0\nappVersion: \"1.0.0\"\n",
+			want:        "apiVersion: v2\nname: foo\nversion: 1.2.0\nappVersion: \"1.2.0\"\n",
+		},
+		{
+			name:        "package-lock.json v1 without packages",
+			releaseType: "node",
+			path:        "package-lock.json",
+			content:     "{\n  \"name\": \"foo\",\n  \"version\": \"1.0.0\",\n  \"lockfileVersion\": 1,\n  \"dependencies\": {}\n}\n",
+			want:        "{\n  \"name\": \"foo\",\n  \"version\": \"1.2.0\",\n  \"lockfileVersion\": 1,\n  \"dependencies\": {}\n}\n",
+		},
+		{
+			name:        "pyproject.toml with dynamic version",
+			releaseType: "python",
+			path:        "pyproject.toml",
+			content:     "[project]\nname = \"foo\"\ndynamic = [\"version\"]\n\n[tool.setuptools_scm]\n",
+			want:        "[project]\nname = \"foo\"\ndynamic = [\"version\"]\n\n[tool.setuptools_scm]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := ReleaseTypes[tt.releaseType]
+			i := slices.IndexFunc(files, func(file File) bool { return file.Path == tt.path })
+			require.GreaterOrEqual(t, i, 0)
+
+			content := tt.content
+			for _, update := range WithInfo(ReleaseInfo{Version: "v1.2.0"}, files[i].Updaters...) {
+				var err error
+				content, err = update(content)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, content)
+		})
+	}
+}
+
+func TestIgnoreMissingKey(t *testing.T) {
+	update := IgnoreMissingKey(YAML("appVersion"))(ReleaseInfo{Version: "v1.2.0"})
+
+	got, err := update("version: 1.0.0\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "version: 1.0.0\n", got)
+
+	// Other errors are still returned
+	_, err = update("appVersion: [1.0.0]\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,19 @@
+package updater
+
+import (
+	"strings"
+)
+
+// Text replaces the whole content of the file with the version. This is useful for files like VERSION that only
+// contain the version number. A trailing new line is preserved.
+func Text(info ReleaseInfo) Updater {
+	return func(content string) (string, error) {
+		version := versionLike(info, content)
+
+		if strings.HasSuffix(content, "\n") {
+			return version + "\n", nil
+		}
+
+		return version, nil
+	}
+}
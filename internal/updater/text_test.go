@@ -0,0 +1,31 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextUpdater_UpdateContent(t *testing.T) {
+	tests := []updaterTestCase{
+		{
+			name:    "with new line",
+			content: "1.0.0\n",
+			info:    ReleaseInfo{Version: "v1.2.0"},
+			want:    "1.2.0\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "without new line and v prefix",
+			content: "v1.0.0",
+			info:    ReleaseInfo{Version: "v1.2.0"},
+			want:    "v1.2.0",
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, Text, tt)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	tomlTableRegex    = regexp.MustCompile(`^\s*\[([^\[\]]+)]\s*(#.*)?$`)
+	tomlArrayRegex    = regexp.MustCompile(`^\s*\[\[([^\[\]]+)]]\s*(#.*)?$`)
+	tomlKeyValueRegex = regexp.MustCompile(`^(\s*([\w-]+)\s*=\s*")([^"]*)("[^\n]*\n?)$`)
+)
+
+// TOML updates the string value at the key in a TOML document. The key consists of the table and the key in the
+// table, separated by ".", e.g. "project.version". If multiple keys are passed, the first one that exists is updated.
+// Only simple documents with keys in standard tables are supported.
+func TOML(keys ...string) NewUpdater {
+	return func(info ReleaseInfo) Updater {
+		return func(content string) (string, error) {
+			lines := strings.SplitAfter(content, "\n")
+
+			for _, key := range keys {
+				table, name := "", key
+				if i := strings.LastIndex(key, "."); i >= 0 {
+					table, name = key[:i], key[i+1:]
+				}
+
+				currentTable := ""
+				for i, line := range lines {
+					if match := tomlTableRegex.FindStringSubmatch(line); match != nil {
+						currentTable = strings.TrimSpace(match[1])
+						continue
+					}
+					if match := tomlArrayRegex.FindStringSubmatch(line); match != nil {
+						currentTable = "[[" + strings.TrimSpace(match[1]) + "]]"
+						continue
+					}
+
+					if currentTable != table {
+						continue
+					}
+
+					if match := tomlKeyValueRegex.FindStringSubmatch(line); match != nil && match[2] == name {
+						lines[i] = match[1] + versionLike(info, match[3]) + match[4]
+						return strings.Join(lines, ""), nil
+					}
+				}
+			}
+
+			return "", fmt.Errorf("%w: none of %q", ErrKeyNotFound, keys)
+		}
+	}
+}
+
+// CargoLock updates the versions of all local packages in a Cargo.lock file. Local packages are those without a
+// source, which are the crates of the current workspace.
+func CargoLock(info ReleaseInfo) Updater {
+	return func(content string) (string, error) {
+		lines := strings.SplitAfter(content, "\n")
+
+		// Start and end line of the current [[package]] block, end is exclusive
+		blockStart := -1
+		updateBlock := func(end int) {
+			if blockStart < 0 {
+				return
+			}
+
+			versionLine := -1
+			for i := blockStart; i < end; i++ {
+				match := tomlKeyValueRegex.FindStringSubmatch(lines[i])
+				if match == nil {
+					continue
+				}
+
+				switch match[2] {
+				case "source":
+					// Package from a registry or git, not part of the workspace
+					return
+				case "version":
+					versionLine = i
+				}
+			}
+
+			if versionLine >= 0 {
+				match := tomlKeyValueRegex.FindStringSubmatch(lines[versionLine])
+				lines[versionLine] = match[1] + versionLike(info, match[3]) + match[4]
+			}
+		}
+
+		for i, line := range lines {
+			if tomlTableRegex.MatchString(line) || tomlArrayRegex.MatchString(line) {
+				updateBlock(i)
+				blockStart = -1
+
+				if match := tomlArrayRegex.FindStringSubmatch(line); match != nil && strings.TrimSpace(match[1]) == "package" {
+					blockStart = i + 1
+				}
+			}
+		}
+		updateBlock(len(lines))
+
+		return strings.Join(lines, ""), nil
+	}
+}
@@ -0,0 +1,93 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOMLUpdater_UpdateContent(t *testing.T) {
+	tests := []struct {
+		updaterTestCase
+		keys []string
+	}{
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "pyproject",
+				content: "[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"foo\"\nversion = \"1.0.0\" # comment\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"foo\"\nversion = \"1.2.0\" # comment\n",
+				wantErr: assert.NoError,
+			},
+			keys: []string{"project.version", "tool.poetry.version"},
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "fallback key",
+				content: "[tool.poetry]\nname = \"foo\"\nversion = \"1.0.0\"\n\n[tool.poetry.dependencies]\nversion = \"2.0.0\"\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "[tool.poetry]\nname = \"foo\"\nversion = \"1.2.0\"\n\n[tool.poetry.dependencies]\nversion = \"2.0.0\"\n",
+				wantErr: assert.NoError,
+			},
+			keys: []string{"project.version", "tool.poetry.version"},
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "missing key",
+				content: "[package]\nname = \"foo\"\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "",
+				wantErr: assert.Error,
+			},
+			keys: []string{"package.version"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, TOML(tt.keys...), tt.updaterTestCase)
+		})
+	}
+}
+
+func TestCargoLockUpdater_UpdateContent(t *testing.T) {
+	tests := []updaterTestCase{
+		{
+			name: "local and registry packages",
+			content: `version = 3
+
+[[package]]
+name = "foo"
+version = "1.0.0"
+dependencies = [
+ "serde",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			info: ReleaseInfo{Version: "v1.2.0"},
+			want: `version = 3
+
+[[package]]
+name = "foo"
+version = "1.2.0"
+dependencies = [
+ "serde",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, CargoLock, tt)
+		})
+	}
+}
@@ -1,8 +1,21 @@
 package updater
 
+import (
+	"errors"
+	"strings"
+)
+
+// ErrKeyNotFound is returned by the updaters of structured files if the key with the version does not exist.
+var ErrKeyNotFound = errors.New("key not found")
+
 type ReleaseInfo struct {
-	Version        string
+	// Version is the version number of the release, it might have a "v" prefix.
+	Version string
+	// TagName is the name of the release tag, including any configured tag prefix.
+	TagName        string
 	ChangelogEntry string
+	// ChangelogPreamble is added below the header, when the changelog file is created.
+	ChangelogPreamble string
 }
 
 type Updater func(string) (string, error)
@@ -17,3 +30,29 @@ func WithInfo(info ReleaseInfo, constructors ...NewUpdater) []Updater {
 
 	return updaters
 }
+
+// versionLike returns the version of the release in the same style as the existing value. The "v" prefix is only
+// added if the existing value has one, to avoid adding/removing it from the users input.
+func versionLike(info ReleaseInfo, existing string) string {
+	version := strings.TrimPrefix(info.Version, "v")
+	if strings.HasPrefix(existing, "v") {
+		version = "v" + version
+	}
+
+	return version
+}
+
+// IgnoreMissingKey keeps the content unchanged if the key of the updater does not exist in the file, e.g. because the
+// key is optional in the file format.
+func IgnoreMissingKey(constructor NewUpdater) NewUpdater {
+	return func(info ReleaseInfo) Updater {
+		update := constructor(info)
+		return func(content string) (string, error) {
+			updated, err := update(content)
+			if errors.Is(err, ErrKeyNotFound) {
+				return content, nil
+			}
+			return updated, err
+		}
+	}
+}
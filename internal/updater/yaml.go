@@ -0,0 +1,88 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML updates the scalar value at the key in a YAML document. Nested keys are separated by ".". The formatting of the
+// document is preserved, only the value itself is replaced.
+func YAML(key string) NewUpdater {
+	return func(info ReleaseInfo) Updater {
+		return func(content string) (string, error) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+				return "", fmt.Errorf("failed to parse yaml: %w", err)
+			}
+
+			node, err := findYAMLValue(&doc, strings.Split(key, "."))
+			if err != nil {
+				return "", fmt.Errorf("failed to find key %q: %w", key, err)
+			}
+
+			return replaceYAMLScalar(content, node, versionLike(info, node.Value))
+		}
+	}
+}
+
+func findYAMLValue(node *yaml.Node, path []string) (*yaml.Node, error) {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, fmt.Errorf("empty document")
+		}
+		return findYAMLValue(node.Content[0], path)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected mapping at %q", path[0])
+	}
+
+	// Mapping nodes store keys and values alternating
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+
+		value := node.Content[i+1]
+		if len(path) > 1 {
+			return findYAMLValue(value, path[1:])
+		}
+
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("value of %q is not a scalar", path[0])
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, path[0])
+}
+
+func replaceYAMLScalar(content string, node *yaml.Node, value string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if node.Line < 1 || node.Line > len(lines) {
+		return "", fmt.Errorf("value is outside of the document")
+	}
+
+	line := lines[node.Line-1]
+	start := node.Column - 1
+
+	var oldValue, newValue string
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		oldValue, newValue = `"`+node.Value+`"`, `"`+value+`"`
+	case yaml.SingleQuotedStyle:
+		oldValue, newValue = `'`+node.Value+`'`, `'`+value+`'`
+	default:
+		oldValue, newValue = node.Value, value
+	}
+
+	if start < 0 || !strings.HasPrefix(line[start:], oldValue) {
+		return "", fmt.Errorf("unable to locate value in line %d", node.Line)
+	}
+
+	lines[node.Line-1] = line[:start] + newValue + line[start+len(oldValue):]
+
+	return strings.Join(lines, ""), nil
+}
@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYAMLUpdater_UpdateContent(t *testing.T) {
+	tests := []struct {
+		updaterTestCase
+		key string
+	}{
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "top-level key",
+				content: "apiVersion: v2\nname: foo\nversion: 1.0.0 # comment\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "apiVersion: v2\nname: foo\nversion: 1.2.0 # comment\n",
+				wantErr: assert.NoError,
+			},
+			key: "version",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "quoted value with v prefix",
+				content: "appVersion: \"v1.0.0\"\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "appVersion: \"v1.2.0\"\n",
+				wantErr: assert.NoError,
+			},
+			key: "appVersion",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "nested key",
+				content: "image:\n  repository: foo\n  tag: '1.0.0'\nversion: 0.1.0\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "image:\n  repository: foo\n  tag: '1.2.0'\nversion: 0.1.0\n",
+				wantErr: assert.NoError,
+			},
+			key: "image.tag",
+		},
+		{
+			updaterTestCase: updaterTestCase{
+				name:    "missing key",
+				content: "name: foo\n",
+				info:    ReleaseInfo{Version: "v1.2.0"},
+				want:    "",
+				wantErr: assert.Error,
+			},
+			key: "version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runUpdaterTest(t, YAML(tt.key), tt.updaterTestCase)
+		})
+	}
+}
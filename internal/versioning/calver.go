@@ -0,0 +1,200 @@
+package versioning
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+const (
+	// DefaultCalVerFormat results in versions like "2024.01.0".
+	DefaultCalVerFormat = "YYYY.0M.MICRO"
+
+	calVerMicro = "MICRO"
+)
+
+// calVerTokens maps the supported tokens of the format to a function returning the value for a date. Tokens are the
+// same as on https://calver.org.
+var calVerTokens = map[string]func(time.Time) string{
+	"YYYY": func(t time.Time) string { return strconv.Itoa(t.Year()) },
+	"YY":   func(t time.Time) string { return strconv.Itoa(t.Year() - 2000) },
+	"0Y":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()-2000) },
+	"MM":   func(t time.Time) string { return strconv.Itoa(int(t.Month())) },
+	"0M":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Month()) },
+	"WW":   func(t time.Time) string { _, week := t.ISOWeek(); return strconv.Itoa(week) },
+	"0W":   func(t time.Time) string { _, week := t.ISOWeek(); return fmt.Sprintf("%02d", week) },
+	"DD":   func(t time.Time) string { return strconv.Itoa(t.Day()) },
+	"0D":   func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) },
+}
+
+type calVer struct {
+	// dateFormat are the tokens of the format, without the trailing MICRO.
+	dateFormat []string
+	now        func() time.Time
+}
+
+// CalVer returns a calendar versioning Strategy. The format is a list of tokens separated by ".", e.g. "YYYY.0M.MICRO".
+// The date is taken from the time of the release (in UTC), the MICRO counter is reset for every new date. Because
+// multiple releases can be made on the same date, the format must end with MICRO.
+//
+// The VersionBump is only used to decide if a release is necessary, it has no effect on the version.
+func CalVer(format string) (Strategy, error) {
+	return newCalVer(format, func() time.Time { return time.Now().UTC() })
+}
+
+func newCalVer(format string, now func() time.Time) (*calVer, error) {
+	tokens := strings.Split(format, ".")
+	if len(tokens) < 2 || tokens[len(tokens)-1] != calVerMicro {
+		return nil, fmt.Errorf("invalid calver format %q: must have a date and end with %s", format, calVerMicro)
+	}
+
+	dateFormat := tokens[:len(tokens)-1]
+	for _, token := range dateFormat {
+		if _, ok := calVerTokens[token]; !ok {
+			return nil, fmt.Errorf("invalid calver format %q: unknown token %q", format, token)
+		}
+	}
+
+	return &calVer{dateFormat: dateFormat, now: now}, nil
+}
+
+type calVerVersion struct {
+	date  string
+	micro int
+
+	preType  string
+	preCount int
+}
+
+func (v calVerVersion) String() string {
+	version := fmt.Sprintf("%s.%d", v.date, v.micro)
+	if v.preType != "" {
+		version += fmt.Sprintf("-%s.%d", v.preType, v.preCount)
+	}
+
+	return version
+}
+
+func (c *calVer) parse(version string) (calVerVersion, error) {
+	base, pre, hasPre := strings.Cut(version, "-")
+
+	parts := strings.Split(base, ".")
+	if len(parts) != len(c.dateFormat)+1 {
+		return calVerVersion{}, fmt.Errorf("version %q does not match the format", version)
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return calVerVersion{}, fmt.Errorf("version %q does not match the format: %w", version, err)
+		}
+	}
+
+	parsed := calVerVersion{date: strings.Join(parts[:len(parts)-1], ".")}
+	parsed.micro, _ = strconv.Atoi(parts[len(parts)-1])
+
+	if hasPre {
+		preType, preCount, ok := strings.Cut(pre, ".")
+		count, err := strconv.Atoi(preCount)
+		if !ok || err != nil {
+			return calVerVersion{}, fmt.Errorf("version %q has an invalid pre-release", version)
+		}
+		parsed.preType = preType
+		parsed.preCount = count
+	}
+
+	return parsed, nil
+}
+
+func (c *calVer) NextVersion(r git.Releases, versionBump VersionBump, nextVersionType NextVersionType) (string, error) {
+	if versionBump == UnknownVersion {
+		return "", fmt.Errorf("invalid latest bump (unknown)")
+	}
+
+	now := c.now()
+	dateParts := make([]string, 0, len(c.dateFormat))
+	for _, token := range c.dateFormat {
+		dateParts = append(dateParts, calVerTokens[token](now))
+	}
+
+	next := calVerVersion{date: strings.Join(dateParts, ".")}
+
+	if r.Stable != nil {
+		stable, err := c.parse(r.Stable.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse stable version: %w", err)
+		}
+
+		if stable.date == next.date {
+			next.micro = stable.micro + 1
+		}
+	}
+
+	if nextVersionType.IsPrerelease() {
+		next.preType = nextVersionType.String()
+
+		if r.Latest != nil {
+			latest, err := c.parse(r.Latest.Name)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse latest version: %w", err)
+			}
+
+			if latest.date == next.date && latest.micro == next.micro && latest.preType == next.preType {
+				next.preCount = latest.preCount + 1
+			}
+		}
+	}
+
+	return next.String(), nil
+}
+
+func (c *calVer) IsPrerelease(version string) bool {
+	parsed, err := c.parse(version)
+	if err != nil {
+		return false
+	}
+
+	return parsed.preType != ""
+}
+
+func (c *calVer) IsVersion(version string) bool {
+	_, err := c.parse(version)
+	return err == nil
+}
+
+func (c *calVer) Compare(a, b string) int {
+	versionA, errA := c.parse(a)
+	versionB, errB := c.parse(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	// The parts of the date were validated as numbers by parse.
+	datesA, datesB := strings.Split(versionA.date, "."), strings.Split(versionB.date, ".")
+	for i := range datesA {
+		partA, _ := strconv.Atoi(datesA[i])
+		partB, _ := strconv.Atoi(datesB[i])
+		if result := cmp.Compare(partA, partB); result != 0 {
+			return result
+		}
+	}
+
+	if result := cmp.Compare(versionA.micro, versionB.micro); result != 0 {
+		return result
+	}
+
+	switch {
+	case versionA.preType == versionB.preType:
+		return cmp.Compare(versionA.preCount, versionB.preCount)
+	case versionA.preType == "":
+		// Stable versions are higher than their pre-releases.
+		return 1
+	case versionB.preType == "":
+		return -1
+	default:
+		// alpha < beta < rc
+		return strings.Compare(versionA.preType, versionB.preType)
+	}
+}
@@ -0,0 +1,204 @@
+package versioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestCalVer_NextVersion(t *testing.T) {
+	now := func() time.Time { return time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC) }
+
+	type args struct {
+		format          string
+		releases        git.Releases
+		versionBump     VersionBump
+		nextVersionType NextVersionType
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "first release",
+			args: args{
+				format:          DefaultCalVerFormat,
+				releases:        git.Releases{},
+				versionBump:     PatchVersion,
+				nextVersionType: NextVersionTypeUndefined,
+			},
+			want:    "2024.03.0",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "previous release in other month",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "2024.02.3"},
+					Stable: &git.Tag{Name: "2024.02.3"},
+				},
+				versionBump:     MajorVersion,
+				nextVersionType: NextVersionTypeUndefined,
+			},
+			want:    "2024.03.0",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "previous release in same month",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "2024.03.1"},
+					Stable: &git.Tag{Name: "2024.03.1"},
+				},
+				versionBump:     PatchVersion,
+				nextVersionType: NextVersionTypeUndefined,
+			},
+			want:    "2024.03.2",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "other format",
+			args: args{
+				format: "YY.MM.DD.MICRO",
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "24.3.5.0"},
+					Stable: &git.Tag{Name: "24.3.5.0"},
+				},
+				versionBump:     MinorVersion,
+				nextVersionType: NextVersionTypeUndefined,
+			},
+			want:    "24.3.5.1",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "new pre-release",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "2024.03.0"},
+					Stable: &git.Tag{Name: "2024.03.0"},
+				},
+				versionBump:     MinorVersion,
+				nextVersionType: NextVersionTypeRC,
+			},
+			want:    "2024.03.1-rc.0",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "existing pre-release",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "2024.03.1-rc.0"},
+					Stable: &git.Tag{Name: "2024.03.0"},
+				},
+				versionBump:     MinorVersion,
+				nextVersionType: NextVersionTypeRC,
+			},
+			want:    "2024.03.1-rc.1",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "pre-release to stable",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "2024.03.1-rc.1"},
+					Stable: &git.Tag{Name: "2024.03.0"},
+				},
+				versionBump:     MinorVersion,
+				nextVersionType: NextVersionTypeNormal,
+			},
+			want:    "2024.03.1",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "invalid previous version",
+			args: args{
+				format: DefaultCalVerFormat,
+				releases: git.Releases{
+					Latest: &git.Tag{Name: "v1.0.0-foo"},
+					Stable: &git.Tag{Name: "1.0"},
+				},
+				versionBump:     MinorVersion,
+				nextVersionType: NextVersionTypeNormal,
+			},
+			want:    "",
+			wantErr: assert.Error,
+		},
+		{
+			name: "no bump",
+			args: args{
+				format:          DefaultCalVerFormat,
+				releases:        git.Releases{},
+				versionBump:     UnknownVersion,
+				nextVersionType: NextVersionTypeNormal,
+			},
+			want:    "",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := newCalVer(tt.args.format, now)
+			require.NoError(t, err)
+
+			got, err := strategy.NextVersion(tt.args.releases, tt.args.versionBump, tt.args.nextVersionType)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCalVer(t *testing.T) {
+	for _, format := range []string{"", "YYYY", "YYYY.0M", "MICRO.YYYY", "YYYY.FOO.MICRO"} {
+		_, err := CalVer(format)
+		assert.Error(t, err, format)
+	}
+
+	_, err := CalVer("YYYY.0W.MICRO")
+	assert.NoError(t, err)
+}
+
+func TestCalVer_IsPrerelease(t *testing.T) {
+	strategy, err := CalVer(DefaultCalVerFormat)
+	require.NoError(t, err)
+
+	assert.False(t, strategy.IsPrerelease("2024.03.0"))
+	assert.True(t, strategy.IsPrerelease("2024.03.0-rc.1"))
+	assert.False(t, strategy.IsPrerelease("v1.0.0-rc.1"))
+}
+
+func TestCalVer_IsVersion(t *testing.T) {
+	strategy, err := CalVer(DefaultCalVerFormat)
+	require.NoError(t, err)
+
+	assert.True(t, strategy.IsVersion("2024.03.0"))
+	assert.True(t, strategy.IsVersion("2024.03.0-beta.2"))
+	assert.False(t, strategy.IsVersion("2024.03"))
+	assert.False(t, strategy.IsVersion("v2024.03.0"))
+	assert.False(t, strategy.IsVersion("2024.03.0-beta"))
+}
+
+func TestCalVer_Compare(t *testing.T) {
+	strategy, err := CalVer("YY.MM.MICRO")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strategy.Compare("24.10.0", "24.9.3"))
+	assert.Equal(t, -1, strategy.Compare("23.12.5", "24.1.0"))
+	assert.Equal(t, 1, strategy.Compare("24.1.2", "24.1.1"))
+	assert.Equal(t, 0, strategy.Compare("24.1.2", "24.1.2"))
+	assert.Equal(t, 1, strategy.Compare("24.1.2", "24.1.2-rc.3"))
+	assert.Equal(t, -1, strategy.Compare("24.1.2-beta.4", "24.1.2-rc.1"))
+	assert.Equal(t, 1, strategy.Compare("24.1.2-rc.2", "24.1.2-rc.1"))
+}
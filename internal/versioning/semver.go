@@ -12,7 +12,22 @@ import (
 
 var SemVer Strategy = semVer{}
 
-type semVer struct{}
+// SemVerOptions change the version bump of releases before 1.0.0, where the public API is not considered stable yet.
+type SemVerOptions struct {
+	// BumpMinorPreMajor bumps the minor instead of the major version for breaking changes.
+	BumpMinorPreMajor bool
+	// BumpPatchForMinorPreMajor bumps the patch instead of the minor version for new features.
+	BumpPatchForMinorPreMajor bool
+}
+
+// NewSemVer returns the Semantic Versioning strategy with the options.
+func NewSemVer(options SemVerOptions) Strategy {
+	return semVer{options: options}
+}
+
+type semVer struct {
+	options SemVerOptions
+}
 
 func (s semVer) NextVersion(r git.Releases, versionBump VersionBump, nextVersionType NextVersionType) (string, error) {
 	latest, err := parseSemverWithDefault(r.Latest)
@@ -32,6 +47,10 @@ func (s semVer) NextVersion(r git.Releases, versionBump VersionBump, nextVersion
 		next = stable
 	}
 
+	if next.Major == 0 {
+		versionBump = s.preMajorBump(versionBump)
+	}
+
 	switch versionBump {
 	case UnknownVersion:
 		return "", fmt.Errorf("invalid latest bump (unknown)")
@@ -65,6 +84,17 @@ func (s semVer) NextVersion(r git.Releases, versionBump VersionBump, nextVersion
 	return "v" + next.String(), nil
 }
 
+func (s semVer) preMajorBump(versionBump VersionBump) VersionBump {
+	switch {
+	case versionBump == MajorVersion && s.options.BumpMinorPreMajor:
+		return MinorVersion
+	case versionBump == MinorVersion && s.options.BumpPatchForMinorPreMajor:
+		return PatchVersion
+	default:
+		return versionBump
+	}
+}
+
 func BumpFromCommits(commits []commitparser.AnalyzedCommit) VersionBump {
 	bump := UnknownVersion
 
@@ -123,3 +153,18 @@ func (s semVer) IsPrerelease(version string) bool {
 
 	return false
 }
+
+func (s semVer) IsVersion(version string) bool {
+	_, err := parseSemverWithDefault(&git.Tag{Hash: "", Name: version})
+	return err == nil
+}
+
+func (s semVer) Compare(a, b string) int {
+	versionA, errA := parseSemverWithDefault(&git.Tag{Name: a})
+	versionB, errB := parseSemverWithDefault(&git.Tag{Name: b})
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return versionA.Compare(versionB)
+}
@@ -335,6 +335,75 @@ func TestSemVer_NextVersion(t *testing.T) {
 	}
 }
 
+func TestSemVer_NextVersion_PreMajor(t *testing.T) {
+	preMajor := git.Releases{Latest: &git.Tag{Name: "v0.3.1"}, Stable: &git.Tag{Name: "v0.3.1"}}
+	stable := git.Releases{Latest: &git.Tag{Name: "v1.3.1"}, Stable: &git.Tag{Name: "v1.3.1"}}
+
+	tests := []struct {
+		name        string
+		options     SemVerOptions
+		releases    git.Releases
+		versionBump VersionBump
+		want        string
+	}{
+		{
+			name:        "default breaking",
+			releases:    preMajor,
+			versionBump: MajorVersion,
+			want:        "v1.0.0",
+		},
+		{
+			name:        "bump minor pre major",
+			options:     SemVerOptions{BumpMinorPreMajor: true},
+			releases:    preMajor,
+			versionBump: MajorVersion,
+			want:        "v0.4.0",
+		},
+		{
+			name:        "bump minor pre major after 1.0.0",
+			options:     SemVerOptions{BumpMinorPreMajor: true},
+			releases:    stable,
+			versionBump: MajorVersion,
+			want:        "v2.0.0",
+		},
+		{
+			name:        "bump patch for minor pre major",
+			options:     SemVerOptions{BumpPatchForMinorPreMajor: true},
+			releases:    preMajor,
+			versionBump: MinorVersion,
+			want:        "v0.3.2",
+		},
+		{
+			name:        "bump patch for minor pre major with breaking change",
+			options:     SemVerOptions{BumpPatchForMinorPreMajor: true},
+			releases:    preMajor,
+			versionBump: MajorVersion,
+			want:        "v1.0.0",
+		},
+		{
+			name:        "both options with breaking change",
+			options:     SemVerOptions{BumpMinorPreMajor: true, BumpPatchForMinorPreMajor: true},
+			releases:    preMajor,
+			versionBump: MajorVersion,
+			want:        "v0.4.0",
+		},
+		{
+			name:        "bump patch for minor pre major on first release",
+			options:     SemVerOptions{BumpPatchForMinorPreMajor: true},
+			releases:    git.Releases{},
+			versionBump: MinorVersion,
+			want:        "v0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSemVer(tt.options).NextVersion(tt.releases, tt.versionBump, NextVersionTypeUndefined)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestVersionBumpFromCommits(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -422,3 +491,21 @@ func TestSemVer_IsPrerelease(t *testing.T) {
 		})
 	}
 }
+
+func TestSemVer_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v1.10.0", b: "v1.9.0", want: 1},
+		{a: "v1.0.0", b: "v1.0.0", want: 0},
+		{a: "v1.0.0-rc.1", b: "v1.0.0", want: -1},
+		{a: "v1.0.0-rc.10", b: "v1.0.0-rc.2", want: 1},
+		{a: "2.0.0", b: "v10.0.0", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, SemVer.Compare(tt.a, tt.b))
+		})
+	}
+}
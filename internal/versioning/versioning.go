@@ -9,6 +9,11 @@ import (
 type Strategy interface {
 	NextVersion(git.Releases, VersionBump, NextVersionType) (string, error)
 	IsPrerelease(version string) bool
+	// IsVersion reports if the version can be parsed by the strategy. Tags with other versions are ignored.
+	IsVersion(version string) bool
+	// Compare returns -1, 0 or +1 depending on whether version a is lower, equal or higher than version b. Both
+	// versions must be valid according to IsVersion.
+	Compare(a, b string) int
 }
 
 type VersionBump conventionalcommits.VersionBump
@@ -0,0 +1,15 @@
+package rp
+
+import (
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+)
+
+// linkCommits links the changelog entries without a pull request to their commit. Entries of pull requests are
+// already linked by the forge through the pull request.
+func linkCommits(commits []commitparser.AnalyzedCommit, commitURL func(hash string) string) {
+	for i, commit := range commits {
+		if commit.PullRequest == nil && commit.Hash != "" {
+			commits[i].CommitURL = commitURL(commit.Hash)
+		}
+	}
+}
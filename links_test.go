@@ -0,0 +1,22 @@
+package rp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func Test_linkCommits(t *testing.T) {
+	commits := []commitparser.AnalyzedCommit{
+		{Commit: git.Commit{Hash: "aaaaaaaaaa"}},
+		{Commit: git.Commit{Hash: "bbbbbbbbbb", PullRequest: &git.PullRequest{ID: 1}}},
+	}
+
+	linkCommits(commits, func(hash string) string { return "https://example.com/commit/" + hash })
+
+	assert.Equal(t, "https://example.com/commit/aaaaaaaaaa", commits[0].CommitURL)
+	assert.Empty(t, commits[1].CommitURL)
+}
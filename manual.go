@@ -0,0 +1,64 @@
+package rp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// keepManualChanges replays the commits that users pushed to the release branch on top of the new release commit, so
+// they are not discarded by the force push. The first commit of the remote branch is the release commit of an earlier
+// run, all later commits are manual changes.
+//
+// It returns the commits that need to be pushed, starting with the release commit, and the manual changes for the
+// description of the pull request. If a manual change conflicts with the new release commit, the returned commits are
+// nil and the branch must not be updated.
+func keepManualChanges(ctx context.Context, logger *slog.Logger, repo *git.Repository, branch, targetBranch string, releaseCommit git.Commit, options git.CommitOptions) ([]git.Commit, []releasepr.ManualChange, error) {
+	branchCommits, err := repo.RemoteBranchCommits(ctx, branch, targetBranch)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(branchCommits) <= 1 {
+		return []git.Commit{releaseCommit}, nil, nil
+	}
+
+	commits := []git.Commit{releaseCommit}
+	changes := make([]releasepr.ManualChange, 0, len(branchCommits)-1)
+	conflict := false
+	for _, commit := range branchCommits[1:] {
+		change := releasepr.ManualChange{
+			Title: strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0],
+			Hash:  commit.Hash[:min(len(commit.Hash), 7)],
+		}
+
+		if !conflict {
+			replayed, err := repo.ReplayCommit(ctx, commit.Hash, options)
+			switch {
+			case errors.Is(err, git.ErrConflict):
+				logger.WarnContext(ctx, "manual change on release branch conflicts with the release commit, skipping update of the branch", "commit.hash", commit.Hash, "err", err)
+				change.Conflict = true
+				conflict = true
+			case err != nil:
+				return nil, nil, err
+			case replayed.Hash == "":
+				logger.InfoContext(ctx, "manual change on release branch is already part of the release commit", "commit.hash", commit.Hash)
+				continue
+			default:
+				logger.InfoContext(ctx, "kept manual change on release branch", "commit.hash", commit.Hash, "commit.replayed_hash", replayed.Hash)
+				commits = append(commits, replayed)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	if conflict {
+		return nil, changes, nil
+	}
+
+	return commits, changes, nil
+}
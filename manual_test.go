@@ -0,0 +1,112 @@
+package rp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/updater"
+)
+
+func Test_keepManualChanges(t *testing.T) {
+	const branch = "releaser-pleaser--branches--main"
+
+	tests := []struct {
+		name          string
+		changelog     string
+		wantChangelog string
+		wantCommits   int
+		wantChanges   []releasepr.ManualChange
+	}{
+		{
+			name:          "kept",
+			changelog:     "# Changelog\n\n## v1.1.0\n\n- Added more\n- Fixd a bug\n",
+			wantChangelog: "# Changelog\n\n## v1.1.0\n\n- Added more\n- Fixed a bug\n",
+			wantCommits:   2,
+			wantChanges:   []releasepr.ManualChange{{Title: "docs: fix typo"}},
+		},
+		{
+			name:          "already included",
+			changelog:     "# Changelog\n\n## v1.1.0\n\n- Fixed a bug\n",
+			wantChangelog: "# Changelog\n\n## v1.1.0\n\n- Fixed a bug\n",
+			wantCommits:   1,
+			wantChanges:   []releasepr.ManualChange{},
+		},
+		{
+			name:          "conflict",
+			changelog:     "# Changelog\n\n## v1.1.0\n\n- Fixd a bug in the parser\n",
+			wantChangelog: "# Changelog\n\n## v1.1.0\n\n- Fixd a bug in the parser\n",
+			wantCommits:   0,
+			wantChanges:   []releasepr.ManualChange{{Title: "docs: fix typo", Conflict: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := gogit.PlainInit(dir, false)
+			require.NoError(t, err)
+			worktree, err := r.Worktree()
+			require.NoError(t, err)
+
+			commit := func(message, path, content string) string {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+				_, err := worktree.Add(path)
+				require.NoError(t, err)
+				hash, err := worktree.Commit(message, &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+				require.NoError(t, err)
+				return hash.String()
+			}
+
+			commit("feat: first", "CHANGELOG.md", "# Changelog\n")
+
+			// Release branch of an earlier run with a manual change on top
+			require.NoError(t, worktree.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}))
+			commit("chore(main): release v1.1.0", "CHANGELOG.md", "# Changelog\n\n## v1.1.0\n\n- Fixd a bug\n")
+			manual := commit("docs: fix typo\n\nSome details.", "CHANGELOG.md", "# Changelog\n\n## v1.1.0\n\n- Fixed a bug\n")
+
+			// More changes on the base branch
+			require.NoError(t, worktree.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}))
+			commit("feat: more", "other.txt", "more\n")
+
+			repo, err := git.CloneRepo(context.Background(), slog.Default(), dir, "master", nil, git.CloneOptions{Mode: git.CloneModeMemory})
+			require.NoError(t, err)
+			require.NoError(t, repo.Checkout(context.Background(), branch))
+			require.NoError(t, repo.UpdateFile(context.Background(), "CHANGELOG.md", false, []updater.Updater{
+				func(string) (string, error) { return tt.changelog, nil },
+			}))
+			releaseCommit, err := repo.Commit(context.Background(), "chore(main): release v1.1.0", git.CommitOptions{})
+			require.NoError(t, err)
+
+			commits, changes, err := keepManualChanges(context.Background(), slog.Default(), repo, branch, "master", releaseCommit, git.CommitOptions{})
+			require.NoError(t, err)
+
+			for i := range tt.wantChanges {
+				tt.wantChanges[i].Hash = manual[:7]
+			}
+			assert.Equal(t, tt.wantChanges, changes)
+			assert.Len(t, commits, tt.wantCommits)
+			if tt.wantCommits > 0 {
+				assert.Equal(t, releaseCommit, commits[0])
+			}
+			if tt.wantCommits > 1 {
+				assert.Equal(t, "docs: fix typo\n\nSome details.", commits[1].Message)
+			}
+
+			if tt.wantCommits > 0 {
+				content, err := repo.ReadFile(context.Background(), "CHANGELOG.md")
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantChangelog, string(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+)
+
+// NextVersion is the next release of a package, as it is proposed in the release pull request.
+type NextVersion struct {
+	// Package is the name of the package, empty for single package repositories.
+	Package string
+	// PreviousTag is the tag of the release the changes are compared to, empty for the first release.
+	PreviousTag string
+	// Version is the tag of the next release.
+	Version string
+}
+
+// NextVersions computes the next version of every package with releasable changes, without modifying anything on the
+// forge. Overrides from an open release pull request are considered.
+func (rp *ReleaserPleaser) NextVersions(ctx context.Context) ([]NextVersion, error) {
+	versions := make([]NextVersion, 0, len(rp.packages))
+
+	for _, pkg := range rp.packages {
+		logger := rp.logger.With("method", "NextVersions")
+		if pkg.Name != "" {
+			logger = logger.With("package.name", pkg.Name, "package.path", pkg.Path)
+		}
+
+		plan, err := rp.planRelease(ctx, logger, pkg)
+		if err != nil {
+			if pkg.Name != "" {
+				return nil, fmt.Errorf("package %s: %w", pkg.Name, err)
+			}
+			return nil, err
+		}
+
+		if plan.nextVersion == "" {
+			continue
+		}
+
+		version := NextVersion{Package: pkg.Name, Version: plan.nextVersion}
+		if plan.lastReleaseCommit != nil {
+			version.PreviousTag = plan.lastReleaseCommit.Name
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,93 @@
+package rp
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+var (
+	// conventionalTitleRegex matches the type and optional scope in the title of a pull request, e.g.
+	// "docs(api): explain foo".
+	conventionalTitleRegex = regexp.MustCompile(`^\w+(?:\(([^)]*)\))?!?: (.+)$`)
+)
+
+// omittedChanges returns the pull requests and commits of the release that are not part of the changelog. Pull
+// requests with the releasepr.LabelChangelogInclude are returned as included commits instead, once per pull request
+// with its title as the description. Skipped commits are always omitted.
+func omittedChanges(commits, skipped []git.Commit, analyzedCommits []commitparser.AnalyzedCommit, pullRequestURL func(id int) string) ([]commitparser.AnalyzedCommit, []releasepr.OmittedChange) {
+	inChangelog := make(map[int]bool)
+	for _, commit := range analyzedCommits {
+		if commit.PullRequest != nil {
+			inChangelog[commit.PullRequest.ID] = true
+		}
+	}
+
+	var included []commitparser.AnalyzedCommit
+	var omitted []releasepr.OmittedChange
+	seen := make(map[int]bool)
+
+	add := func(commit git.Commit, isSkipped bool) {
+		if slices.ContainsFunc(analyzedCommits, func(analyzed commitparser.AnalyzedCommit) bool {
+			return analyzed.Hash == commit.Hash && analyzed.Message == commit.Message
+		}) {
+			return
+		}
+
+		pr := commit.PullRequest
+		if pr == nil {
+			subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+			omitted = append(omitted, releasepr.OmittedChange{
+				Title:   strings.TrimSpace(subject),
+				Hash:    commit.Hash[:min(7, len(commit.Hash))],
+				Skipped: isSkipped,
+			})
+			return
+		}
+
+		// Pull requests that are already in the changelog with another commit, e.g. the merge commit of a
+		// feature branch, are not listed.
+		if inChangelog[pr.ID] || seen[pr.ID] {
+			return
+		}
+		seen[pr.ID] = true
+
+		if !isSkipped && slices.Contains(pr.Labels, releasepr.LabelChangelogInclude.Name) {
+			includedCommit := commitparser.AnalyzedCommit{
+				Commit:      commit,
+				Type:        changelog.SectionTypeIncluded,
+				Description: pr.Title,
+			}
+			if match := conventionalTitleRegex.FindStringSubmatch(pr.Title); match != nil {
+				includedCommit.Description = match[2]
+				if match[1] != "" {
+					includedCommit.Scope = &match[1]
+				}
+			}
+
+			included = append(included, includedCommit)
+			return
+		}
+
+		omitted = append(omitted, releasepr.OmittedChange{
+			Title:          pr.Title,
+			PullRequestID:  pr.ID,
+			PullRequestURL: pullRequestURL(pr.ID),
+			Skipped:        isSkipped,
+		})
+	}
+
+	for _, commit := range commits {
+		add(commit, false)
+	}
+	for _, commit := range skipped {
+		add(commit, true)
+	}
+
+	return included, omitted
+}
@@ -0,0 +1,66 @@
+package rp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/pointer"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+func Test_omittedChanges(t *testing.T) {
+	pullRequestURL := func(id int) string { return fmt.Sprintf("https://example.com/pull/%d", id) }
+
+	feature := git.Commit{Hash: "aaaaaaaaaa", Message: "feat: foo", PullRequest: &git.PullRequest{ID: 1, Title: "feat: foo"}}
+	mergeCommit := git.Commit{Hash: "bbbbbbbbbb", Message: "Merge pull request #1 from foo", PullRequest: feature.PullRequest}
+	docs := git.Commit{Hash: "cccccccccc", Message: "docs: explain foo", PullRequest: &git.PullRequest{ID: 2, Title: "docs: explain foo"}}
+	includedDocs := git.Commit{Hash: "dddddddddd", Message: "docs(api): explain bar", PullRequest: &git.PullRequest{ID: 3, Title: "docs(api): explain bar", Labels: []string{releasepr.LabelChangelogInclude.Name}}}
+	chore := git.Commit{Hash: "eeeeeeeeee", Message: "chore: update baz\n\nDetails"}
+	skippedFix := git.Commit{Hash: "ffffffffff", Message: "fix: bar", PullRequest: &git.PullRequest{ID: 4, Title: "fix: bar", Labels: []string{releasepr.LabelChangelogInclude.Name}}}
+
+	tests := []struct {
+		name         string
+		commits      []git.Commit
+		skipped      []git.Commit
+		analyzed     []commitparser.AnalyzedCommit
+		wantIncluded []commitparser.AnalyzedCommit
+		wantOmitted  []releasepr.OmittedChange
+	}{
+		{
+			name:     "all in changelog",
+			commits:  []git.Commit{feature, mergeCommit},
+			analyzed: []commitparser.AnalyzedCommit{{Commit: feature, Type: "feat", Description: "foo"}},
+		},
+		{
+			name:     "omitted",
+			commits:  []git.Commit{feature, docs, chore},
+			skipped:  []git.Commit{skippedFix},
+			analyzed: []commitparser.AnalyzedCommit{{Commit: feature, Type: "feat", Description: "foo"}},
+			wantOmitted: []releasepr.OmittedChange{
+				{Title: "docs: explain foo", PullRequestID: 2, PullRequestURL: "https://example.com/pull/2"},
+				{Title: "chore: update baz", Hash: "eeeeeee"},
+				{Title: "fix: bar", PullRequestID: 4, PullRequestURL: "https://example.com/pull/4", Skipped: true},
+			},
+		},
+		{
+			name:     "included by label",
+			commits:  []git.Commit{feature, includedDocs},
+			analyzed: []commitparser.AnalyzedCommit{{Commit: feature, Type: "feat", Description: "foo"}},
+			wantIncluded: []commitparser.AnalyzedCommit{
+				{Commit: includedDocs, Type: changelog.SectionTypeIncluded, Description: "explain bar", Scope: pointer.Pointer("api")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			included, omitted := omittedChanges(tt.commits, tt.skipped, tt.analyzed, pullRequestURL)
+			assert.Equal(t, tt.wantIncluded, included)
+			assert.Equal(t, tt.wantOmitted, omitted)
+		})
+	}
+}
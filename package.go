@@ -0,0 +1,105 @@
+package rp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/updater"
+)
+
+const (
+	// DefaultTagPrefix is used for repositories that do not configure any packages.
+	DefaultTagPrefix = "v"
+)
+
+// Package is a part of the repository that is versioned and released independently. Repositories without explicit
+// packages are released as a single package spanning the whole repository.
+type Package struct {
+	// Name identifies the package in branch names. Empty for the default package.
+	Name string
+	// Path limits the commits considered for the package to those touching files below it. Empty for the whole
+	// repository.
+	Path string
+	// TagPrefix is prepended to the version number to get the tag name, e.g. "api/v" for "api/v1.2.3".
+	TagPrefix string
+	// ExtraFiles are scanned for version references and updated in the release commit.
+	ExtraFiles []ExtraFile
+	// ReleaseCommands are run with "sh -c" in the root of the release branch worktree after the files were updated,
+	// e.g. "make generate-docs". All files they change are added to the release commit. The tag and version are
+	// passed in EnvAssetsTag and EnvAssetsVersion. They require git.CloneModeDisk.
+	ReleaseCommands []string
+	// Assets are built and attached to every release of the package.
+	Assets Assets
+}
+
+// ExtraFile is updated with the new version in the release commit.
+type ExtraFile struct {
+	// Path of the file relative to the repository root.
+	Path string
+	// Updaters are run on the content of the file, in order.
+	Updaters []updater.NewUpdater
+	// Optional files are skipped if they do not exist in the repository.
+	Optional bool
+}
+
+func (p Package) branch(targetBranch string) string {
+	if p.Name == "" {
+		return fmt.Sprintf(PullRequestBranchFormat, targetBranch)
+	}
+
+	return fmt.Sprintf(PullRequestPackageBranchFormat, targetBranch, p.Name)
+}
+
+func (p Package) changelogFile(file string) string {
+	if p.Path == "" {
+		return file
+	}
+
+	return strings.TrimSuffix(p.Path, "/") + "/" + file
+}
+
+// tagName returns the name of the tag for a version returned by the versioning.Strategy.
+func (p Package) tagName(version string) string {
+	return p.TagPrefix + strings.TrimPrefix(version, "v")
+}
+
+// version returns the version number of a tag created for this package, without the tag prefix.
+func (p Package) version(tagName string) string {
+	return strings.TrimPrefix(tagName, p.TagPrefix)
+}
+
+// releases strips the tag prefix from the tags, so they can be passed on to the versioning.Strategy.
+func (p Package) releases(r git.Releases) git.Releases {
+	trim := func(tag *git.Tag) *git.Tag {
+		if tag == nil {
+			return nil
+		}
+
+		return &git.Tag{Hash: tag.Hash, Name: p.version(tag.Name)}
+	}
+
+	return git.Releases{
+		Latest: trim(r.Latest),
+		Stable: trim(r.Stable),
+	}
+}
+
+// packageForTag returns the package with the longest tag prefix matching the tag name.
+func packageForTag(packages []Package, tagName string) (Package, bool) {
+	var match Package
+	found := false
+
+	for _, pkg := range packages {
+		if !strings.HasPrefix(tagName, pkg.TagPrefix) {
+			continue
+		}
+
+		if !found || len(pkg.TagPrefix) > len(match.TagPrefix) {
+			match = pkg
+			found = true
+		}
+	}
+
+	return match, found
+}
@@ -0,0 +1,98 @@
+package rp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func TestPackage_tagName(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkg     Package
+		version string
+		want    string
+	}{
+		{
+			name:    "default package",
+			pkg:     Package{TagPrefix: DefaultTagPrefix},
+			version: "v1.2.3",
+			want:    "v1.2.3",
+		},
+		{
+			name:    "package with prefix",
+			pkg:     Package{Name: "api", TagPrefix: "api/v"},
+			version: "v1.2.3-rc.0",
+			want:    "api/v1.2.3-rc.0",
+		},
+		{
+			name:    "custom prefix",
+			pkg:     Package{TagPrefix: "release-"},
+			version: "v1.2.3",
+			want:    "release-1.2.3",
+		},
+		{
+			name:    "bare version",
+			pkg:     Package{TagPrefix: ""},
+			version: "v1.2.3",
+			want:    "1.2.3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pkg.tagName(tt.version))
+		})
+	}
+}
+
+func TestPackage_releases(t *testing.T) {
+	pkg := Package{Name: "api", TagPrefix: "api/v"}
+
+	got := pkg.releases(git.Releases{
+		Latest: &git.Tag{Hash: "abc", Name: "api/v1.1.0-rc.0"},
+		Stable: nil,
+	})
+
+	assert.Equal(t, git.Releases{
+		Latest: &git.Tag{Hash: "abc", Name: "1.1.0-rc.0"},
+		Stable: nil,
+	}, got)
+}
+
+func TestPackage_branch(t *testing.T) {
+	assert.Equal(t, "releaser-pleaser--branches--main", Package{}.branch("main"))
+	assert.Equal(t, "releaser-pleaser--branches--main--api", Package{Name: "api"}.branch("main"))
+}
+
+func TestPackage_changelogFile(t *testing.T) {
+	assert.Equal(t, "CHANGELOG.md", Package{}.changelogFile("CHANGELOG.md"))
+	assert.Equal(t, "api/CHANGELOG.md", Package{Path: "api/"}.changelogFile("CHANGELOG.md"))
+}
+
+func Test_packageForTag(t *testing.T) {
+	packages := []Package{
+		{Name: "api", TagPrefix: "api/v"},
+		{Name: "api-client", TagPrefix: "api/client/v"},
+		{Name: "web", TagPrefix: "web/v"},
+	}
+
+	tests := []struct {
+		name      string
+		tagName   string
+		want      string
+		wantFound bool
+	}{
+		{name: "simple", tagName: "web/v1.0.0", want: "web", wantFound: true},
+		{name: "longest prefix", tagName: "api/client/v1.0.0", want: "api-client", wantFound: true},
+		{name: "no match", tagName: "v1.0.0", want: "", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := packageForTag(packages, tt.tagName)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package rp
 
 import (
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/apricote/releaser-pleaser/internal/git"
@@ -10,33 +12,61 @@ import (
 func parsePRBodyForCommitOverrides(commits []git.Commit) ([]git.Commit, error) {
 	result := make([]git.Commit, 0, len(commits))
 
+	// Pull requests that were merged with a merge commit or rebased are associated with multiple commits. The
+	// overrides replace all of them, so they are only added for the first commit.
+	overridden := make(map[int]bool)
+
 	for _, commit := range commits {
-		singleResult, err := parseSinglePRBodyForCommitOverrides(commit)
+		if commit.PullRequest != nil && overridden[commit.PullRequest.ID] {
+			continue
+		}
+
+		overrides, found, err := commitOverrides(commit)
 		if err != nil {
 			return nil, err
 		}
 
-		result = append(result, singleResult...)
+		if !found {
+			result = append(result, commit)
+			continue
+		}
+
+		overridden[commit.PullRequest.ID] = true
+		result = append(result, overrides...)
 	}
 
 	return result, nil
 }
 
 func parseSinglePRBodyForCommitOverrides(commit git.Commit) ([]git.Commit, error) {
-	if commit.PullRequest == nil {
+	overrides, found, err := commitOverrides(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
 		return []git.Commit{commit}, nil
 	}
 
+	return overrides, nil
+}
+
+// commitOverrides returns a commit for every line in the "rp-commits" code block of the pull request description.
+func commitOverrides(commit git.Commit) ([]git.Commit, bool, error) {
+	if commit.PullRequest == nil {
+		return nil, false, nil
+	}
+
 	source := []byte(commit.PullRequest.Description)
 	var overridesText string
 	var found bool
 	err := markdown.WalkAST(source, markdown.GetCodeBlockText(source, "rp-commits", &overridesText, &found))
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if !found {
-		return []git.Commit{commit}, nil
+		return nil, false, nil
 	}
 
 	lines := strings.Split(overridesText, "\n")
@@ -53,5 +83,38 @@ func parseSinglePRBodyForCommitOverrides(commit git.Commit) ([]git.Commit, error
 		result = append(result, newCommit)
 	}
 
-	return result, nil
+	return result, true, nil
+}
+
+var (
+	// linkedIssueRegex matches the keywords that close issues on GitHub and GitLab, e.g. "Closes #12" or
+	// "fixes owner/repo#12".
+	linkedIssueRegex = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\b:?\s+((?:[\w.-]+/[\w.-]+)?#\d+)`)
+)
+
+// addLinkedIssues sets git.PullRequest.LinkedIssues from the closing keywords in the pull request descriptions.
+func addLinkedIssues(commits []git.Commit) []git.Commit {
+	for i, commit := range commits {
+		if commit.PullRequest == nil {
+			continue
+		}
+
+		pr := *commit.PullRequest
+		pr.LinkedIssues = linkedIssues(pr.Description)
+		commits[i].PullRequest = &pr
+	}
+
+	return commits
+}
+
+func linkedIssues(description string) []string {
+	var issues []string
+
+	for _, match := range linkedIssueRegex.FindAllStringSubmatch(description, -1) {
+		if !slices.Contains(issues, match[1]) {
+			issues = append(issues, match[1])
+		}
+	}
+
+	return issues
 }
@@ -100,6 +100,77 @@ func Test_parsePRBodyForCommitOverrides(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "multiple commits of the same pull request",
+			commits: []git.Commit{
+				{
+					Hash:    "123",
+					Message: "Merge pull request #1 from foo",
+					PullRequest: &git.PullRequest{
+						ID:          1,
+						Title:       "Foo",
+						Description: "```rp-commits\nfeat: shiny\n```\n",
+					},
+				},
+				{
+					Hash:    "456",
+					Message: "feat: shiny first draft",
+					PullRequest: &git.PullRequest{
+						ID:          1,
+						Title:       "Foo",
+						Description: "```rp-commits\nfeat: shiny\n```\n",
+					},
+				},
+				{
+					Hash:    "789",
+					Message: "fix: boom",
+					PullRequest: &git.PullRequest{
+						ID:          2,
+						Title:       "Bar",
+						Description: "# Foobazzle\n\n",
+					},
+				},
+				{
+					Hash:    "abc",
+					Message: "fix: boom again",
+					PullRequest: &git.PullRequest{
+						ID:          2,
+						Title:       "Bar",
+						Description: "# Foobazzle\n\n",
+					},
+				},
+			},
+			want: []git.Commit{
+				{
+					Hash:    "123",
+					Message: "feat: shiny",
+					PullRequest: &git.PullRequest{
+						ID:          1,
+						Title:       "Foo",
+						Description: "```rp-commits\nfeat: shiny\n```\n",
+					},
+				},
+				{
+					Hash:    "789",
+					Message: "fix: boom",
+					PullRequest: &git.PullRequest{
+						ID:          2,
+						Title:       "Bar",
+						Description: "# Foobazzle\n\n",
+					},
+				},
+				{
+					Hash:    "abc",
+					Message: "fix: boom again",
+					PullRequest: &git.PullRequest{
+						ID:          2,
+						Title:       "Bar",
+						Description: "# Foobazzle\n\n",
+					},
+				},
+			},
+			wantErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -240,3 +311,27 @@ func Test_parseSinglePRBodyForCommitOverrides(t *testing.T) {
 		})
 	}
 }
+
+func Test_linkedIssues(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []string
+	}{
+		{name: "empty", description: "", want: nil},
+		{name: "no keyword", description: "Related to #12", want: nil},
+		{name: "closes", description: "Closes #12", want: []string{"#12"}},
+		{
+			name:        "multiple keywords",
+			description: "This fixes #12 and resolves: #13.\n\nAlso closed #12 again.",
+			want:        []string{"#12", "#13"},
+		},
+		{name: "other repository", description: "fix apricote/releaser-pleaser#7", want: []string{"apricote/releaser-pleaser#7"}},
+		{name: "keyword in word", description: "prefixes #12", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, linkedIssues(tt.description))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// CommitMode controls how the release commit is pushed to the forge.
+type CommitMode string
+
+const (
+	// CommitModeGit pushes the release commit with git.
+	CommitModeGit CommitMode = "git"
+	// CommitModeAPI creates the release commit through the API of the forge. This works with branch protection rules
+	// that block pushes or require verified commits, see forge.CommitCreator.
+	CommitModeAPI CommitMode = "api"
+)
+
+// commitCreator returns the forge if the release commit is created through its API, or nil if it is pushed with git.
+func (rp *ReleaserPleaser) commitCreator() (forge.CommitCreator, error) {
+	switch rp.commitMode {
+	case CommitModeGit, "":
+		return nil, nil
+	case CommitModeAPI:
+		creator, ok := rp.forge.(forge.CommitCreator)
+		if !ok {
+			return nil, fmt.Errorf("forge does not support creating commits through the API")
+		}
+		return creator, nil
+	default:
+		return nil, fmt.Errorf("unknown commit mode: %s", rp.commitMode)
+	}
+}
+
+// pushReleaseCommit updates the branch on the forge to the release commit, followed by the manual changes that were
+// kept on top of it. It returns the last commit of the branch. If creator is not nil, the changes of every commit are
+// recreated through the API of the forge and the commits on the forge are returned, as their hashes differ from the
+// local ones.
+func pushReleaseCommit(ctx context.Context, repo *git.Repository, creator forge.CommitCreator, branch string, commits ...git.Commit) (git.Commit, error) {
+	if creator == nil {
+		if err := repo.ForcePush(ctx, branch); err != nil {
+			return git.Commit{}, err
+		}
+		return commits[len(commits)-1], nil
+	}
+
+	var pushed git.Commit
+	for i, commit := range commits {
+		parent, files, err := repo.ChangedFiles(ctx, commit.Hash)
+		if err != nil {
+			return git.Commit{}, err
+		}
+		if i > 0 {
+			parent = pushed.Hash
+		}
+
+		pushed, err = creator.CreateCommit(ctx, branch, parent, commit.Message, files)
+		if err != nil {
+			return git.Commit{}, err
+		}
+	}
+
+	return pushed, nil
+}
@@ -0,0 +1,118 @@
+package rp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+// fakeCommitForge records the commits created through its API.
+type fakeCommitForge struct {
+	forge.Forge
+
+	branch, parent, message string
+	files                   []git.FileChange
+}
+
+func (f *fakeCommitForge) CreateCommit(_ context.Context, branch, parent, message string, files []git.FileChange) (git.Commit, error) {
+	f.branch, f.parent, f.message, f.files = branch, parent, message, files
+	return git.Commit{Hash: "forge", Message: message}, nil
+}
+
+func TestReleaserPleaser_commitCreator(t *testing.T) {
+	apiForge := &fakeCommitForge{}
+
+	tests := []struct {
+		name    string
+		forge   forge.Forge
+		mode    CommitMode
+		want    forge.CommitCreator
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "default",
+			forge:   apiForge,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "git",
+			forge:   apiForge,
+			mode:    CommitModeGit,
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "api",
+			forge:   apiForge,
+			mode:    CommitModeAPI,
+			want:    apiForge,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "api unsupported by forge",
+			forge:   struct{ forge.Forge }{},
+			mode:    CommitModeAPI,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown",
+			forge:   apiForge,
+			mode:    "svn",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := New(tt.forge, Options{CommitMode: tt.mode})
+
+			got, err := rp.commitCreator()
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_pushReleaseCommit_API(t *testing.T) {
+	dir := t.TempDir()
+	r, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	commit := func(message, content string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte(content), 0o644))
+		_, err := worktree.Add("CHANGELOG.md")
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &gogit.CommitOptions{Author: &object.Signature{Name: "test"}})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	parent := commit("feat: first", "# Changelog\n")
+	local := commit("chore(main): release v1.0.0", "# Changelog\n\n## v1.0.0\n")
+
+	repo, err := git.OpenRepo(slog.Default(), dir)
+	require.NoError(t, err)
+
+	creator := &fakeCommitForge{}
+	got, err := pushReleaseCommit(context.Background(), repo, creator, "releaser-pleaser--branches--main", git.Commit{Hash: local, Message: "chore(main): release v1.0.0"})
+	require.NoError(t, err)
+
+	assert.Equal(t, git.Commit{Hash: "forge", Message: "chore(main): release v1.0.0"}, got)
+	assert.Equal(t, "releaser-pleaser--branches--main", creator.branch)
+	assert.Equal(t, parent, creator.parent)
+	assert.Equal(t, []git.FileChange{{Path: "CHANGELOG.md", Content: []byte("# Changelog\n\n## v1.0.0\n")}}, creator.files)
+}
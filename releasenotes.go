@@ -0,0 +1,67 @@
+package rp
+
+import (
+	"strings"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/markdown"
+)
+
+const (
+	// releaseNoteLanguage is the language of the code block in pull request descriptions with the release notes.
+	releaseNoteLanguage = "release-note"
+	// releaseNotesHeading is the heading in pull request descriptions above the release notes.
+	releaseNotesHeading = "Release Notes"
+	// releaseNoteNone marks pull requests without release notes, like in the release-note blocks of Kubernetes.
+	releaseNoteNone = "NONE"
+)
+
+// addReleaseNoteDetails sets the details of the changelog entries from the release notes in the descriptions of their
+// pull requests. Pull requests with multiple entries, e.g. from the "rp-commits" block, only get the details on the
+// first one.
+func addReleaseNoteDetails(commits []commitparser.AnalyzedCommit) error {
+	seen := make(map[int]bool)
+
+	for i, commit := range commits {
+		if commit.PullRequest == nil || seen[commit.PullRequest.ID] {
+			continue
+		}
+		seen[commit.PullRequest.ID] = true
+
+		details, err := releaseNoteDetails(commit.PullRequest.Description)
+		if err != nil {
+			return err
+		}
+		commits[i].Details = details
+	}
+
+	return nil
+}
+
+// releaseNoteDetails returns the content of the "release-note" code block in the description, or the text below the
+// "Release Notes" heading if there is no code block.
+func releaseNoteDetails(description string) (string, error) {
+	source := []byte(description)
+
+	var codeBlock, heading string
+	var codeBlockFound bool
+	err := markdown.WalkAST(source,
+		markdown.GetCodeBlockText(source, releaseNoteLanguage, &codeBlock, &codeBlockFound),
+		markdown.GetHeadingText(source, releaseNotesHeading, &heading, nil),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	details := heading
+	if codeBlockFound {
+		details = codeBlock
+	}
+
+	details = strings.TrimSpace(strings.ReplaceAll(details, "\r\n", "\n"))
+	if strings.EqualFold(details, releaseNoteNone) {
+		return "", nil
+	}
+
+	return details, nil
+}
@@ -0,0 +1,66 @@
+package rp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/git"
+)
+
+func Test_releaseNoteDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{
+			name:        "no release notes",
+			description: "Fixes #12",
+			want:        "",
+		},
+		{
+			name:        "code block",
+			description: "Some context.\n\n```release-note\nThe foo can now bar.\n\nUse `--baz` to enable it.\n```\n",
+			want:        "The foo can now bar.\n\nUse `--baz` to enable it.",
+		},
+		{
+			name:        "heading",
+			description: "## Summary\r\n\r\nSome context.\r\n\r\n### Release Notes\r\n\r\nThe foo can now bar.\r\n\r\n### Checklist\r\n\r\n- [x] Tests\r\n",
+			want:        "The foo can now bar.",
+		},
+		{
+			name:        "code block takes precedence",
+			description: "### Release Notes\n\nFrom the heading.\n\n```release-note\nFrom the code block.\n```\n",
+			want:        "From the code block.",
+		},
+		{
+			name:        "none",
+			description: "```release-note\nNONE\n```\n",
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := releaseNoteDetails(tt.description)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_addReleaseNoteDetails(t *testing.T) {
+	pr := &git.PullRequest{ID: 12, Description: "```release-note\nThe foo can now bar.\n```"}
+	commits := []commitparser.AnalyzedCommit{
+		{Commit: git.Commit{Hash: "aaa", PullRequest: pr}, Type: "feat", Description: "foo"},
+		{Commit: git.Commit{Hash: "aaa", PullRequest: pr}, Type: "fix", Description: "bar"},
+		{Commit: git.Commit{Hash: "bbb"}, Type: "fix", Description: "baz"},
+	}
+
+	require.NoError(t, addReleaseNoteDetails(commits))
+	assert.Equal(t, "The foo can now bar.", commits[0].Details)
+	assert.Empty(t, commits[1].Details, "only the first entry of the pull request")
+	assert.Empty(t, commits[2].Details)
+}
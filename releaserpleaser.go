@@ -2,41 +2,208 @@ package rp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/apricote/releaser-pleaser/internal/changelog"
 	"github.com/apricote/releaser-pleaser/internal/commitparser"
+	"github.com/apricote/releaser-pleaser/internal/commitparser/conventionalcommits"
 	"github.com/apricote/releaser-pleaser/internal/forge"
 	"github.com/apricote/releaser-pleaser/internal/git"
 	"github.com/apricote/releaser-pleaser/internal/releasepr"
+	"github.com/apricote/releaser-pleaser/internal/telemetry"
 	"github.com/apricote/releaser-pleaser/internal/updater"
 	"github.com/apricote/releaser-pleaser/internal/versioning"
 )
 
 const (
-	PullRequestBranchFormat = "releaser-pleaser--branches--%s"
+	PullRequestBranchFormat        = "releaser-pleaser--branches--%s"
+	PullRequestPackageBranchFormat = "releaser-pleaser--branches--%s--%s"
 )
 
 type ReleaserPleaser struct {
-	forge        forge.Forge
-	logger       *slog.Logger
-	targetBranch string
-	commitParser commitparser.CommitParser
-	versioning   versioning.Strategy
-	extraFiles   []string
-	updaters     []updater.NewUpdater
+	forge         forge.Forge
+	logger        *slog.Logger
+	targetBranch  string
+	commitParser  commitparser.CommitParser
+	versioning    versioning.Strategy
+	packages      []Package
+	sections      []changelog.Section
+	scopes        changelog.Scopes
+	cloneOptions  git.CloneOptions
+	commitOptions git.CommitOptions
+	linkedIssues  bool
+	preamble      string
+	format        changelog.Format
+	order         changelog.Order
+	authors       bool
+	contributors  bool
+	announcers    []forge.ReleaseAnnouncer
+	maintenance   bool
+	commitMode    CommitMode
+	requireMajor  bool
+	prTitle       *template.Template
+	commitMessage *template.Template
+	dependencies  DependencyUpdates
+	changelogs    []ChangelogFile
+	events        EventSink
+
+	result Result
+}
+
+// Options configure the ReleaserPleaser. All fields are optional.
+type Options struct {
+	// Logger defaults to slog.Default.
+	Logger *slog.Logger
+	// TargetBranch is the branch that is released, defaults to DefaultTargetBranch.
+	TargetBranch string
+	// CommitParser defaults to the conventional commits parser, keeping all commit types listed in Sections.
+	CommitParser commitparser.CommitParser
+	// Versioning defaults to versioning.SemVer.
+	Versioning versioning.Strategy
+	// RequireMajorLabel limits the version bump for breaking changes to a minor version, unless the
+	// releasepr.LabelAllowMajor is added to the release pull request.
+	RequireMajorLabel bool
+	// Packages defaults to releasing the whole repository as a single package.
+	Packages []Package
+	// Sections defaults to changelog.DefaultSections, or changelog.KeepAChangelogSections for
+	// changelog.FormatKeepAChangelog.
+	Sections []changelog.Section
+	// Scopes configures grouping and filtering of the changelog entries by their scope.
+	Scopes changelog.Scopes
+	// Clone is used when cloning the repository to create the release commit.
+	Clone git.CloneOptions
+	// Commit is used when creating the release commit and tag.
+	Commit git.CommitOptions
+	// CommitMode defaults to CommitModeGit.
+	CommitMode CommitMode
+	// LinkedIssues adds the issues closed by a pull request to its changelog entries.
+	LinkedIssues bool
+	// ChangelogPreamble is added below the header of the changelog file, when it is created for the first release.
+	// Defaults to changelog.KeepAChangelogPreamble for changelog.FormatKeepAChangelog.
+	ChangelogPreamble string
+	// ChangelogFormat of the changelog file, defaults to changelog.FormatMarkdown. The release pull request and the
+	// release on the forge always use markdown.
+	ChangelogFormat changelog.Format
+	// ChangelogOrder of the entries in every section, defaults to changelog.OrderMergeTime.
+	ChangelogOrder changelog.Order
+	// ChangelogPath of the changelog file in the package, defaults to updater.ChangelogFile, or
+	// updater.ChangelogJSONFile for changelog.FormatJSON.
+	ChangelogPath string
+	// ChangelogFiles receive the changelog entry of every release in addition to the changelog file.
+	ChangelogFiles []ChangelogFile
+	// Authors credits every changelog entry to the author of its pull request or commit.
+	Authors bool
+	// NewContributors lists the users that made their first contribution in the changelog. It requires a forge that
+	// implements forge.ContributionCounter.
+	NewContributors bool
+	// Dependencies configures grouping of the updates by dependency bots like Renovate and Dependabot.
+	Dependencies DependencyUpdates
+	// Announcers are called after a release was created on the forge.
+	Announcers []forge.ReleaseAnnouncer
+	// Events receives the progress of every Run, see Event for the emitted events.
+	Events EventSink
+	// Maintenance marks the TargetBranch as a maintenance branch for an older version. Releases are limited to patch
+	// versions and are not marked as the latest release.
+	Maintenance bool
+	// PullRequestTitleTemplate renders the title of the release pull request from releasepr.TitleData, defaults to
+	// releasepr.DefaultTitleTemplate.
+	PullRequestTitleTemplate *template.Template
+	// ReleaseCommitTemplate renders the message of the release commit from releasepr.TitleData, defaults to
+	// releasepr.DefaultTitleTemplate.
+	ReleaseCommitTemplate *template.Template
 }
 
-func New(forge forge.Forge, logger *slog.Logger, targetBranch string, commitParser commitparser.CommitParser, versioningStrategy versioning.Strategy, extraFiles []string, updaters []updater.NewUpdater) *ReleaserPleaser {
+const DefaultTargetBranch = "main"
+
+// New creates a ReleaserPleaser for the repository on the forge. Unset options are replaced by their defaults.
+func New(forge forge.Forge, options Options) *ReleaserPleaser {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	if options.TargetBranch == "" {
+		options.TargetBranch = DefaultTargetBranch
+	}
+	if len(options.Packages) == 0 {
+		options.Packages = []Package{{TagPrefix: DefaultTagPrefix}}
+	}
+	if options.ChangelogFormat == "" {
+		options.ChangelogFormat = changelog.FormatMarkdown
+	}
+	if len(options.Sections) == 0 {
+		options.Sections = changelog.DefaultSections
+		if options.ChangelogFormat == changelog.FormatKeepAChangelog {
+			options.Sections = changelog.KeepAChangelogSections
+		}
+	}
+	if options.ChangelogPreamble == "" && options.ChangelogFormat == changelog.FormatKeepAChangelog {
+		options.ChangelogPreamble = changelog.KeepAChangelogPreamble
+	}
+	if options.ChangelogPath == "" {
+		options.ChangelogPath = updater.ChangelogFile
+		if options.ChangelogFormat == changelog.FormatJSON {
+			options.ChangelogPath = updater.ChangelogJSONFile
+		}
+	}
+	changelogs := []ChangelogFile{{Path: options.ChangelogPath, Format: options.ChangelogFormat}}
+	for _, file := range options.ChangelogFiles {
+		if file.Format == "" {
+			file.Format = options.ChangelogFormat
+		}
+		changelogs = append(changelogs, file)
+	}
+	if options.CommitParser == nil {
+		options.CommitParser = conventionalcommits.NewParser(options.Logger, changelog.Types(options.Sections)...)
+	}
+	if options.Versioning == nil {
+		options.Versioning = versioning.SemVer
+	}
+	if options.Dependencies.Group && len(options.Dependencies.Authors) == 0 && len(options.Dependencies.BranchPrefixes) == 0 {
+		options.Dependencies.Authors = DefaultDependencyAuthors
+		options.Dependencies.BranchPrefixes = DefaultDependencyBranchPrefixes
+	}
+	if options.PullRequestTitleTemplate == nil {
+		options.PullRequestTitleTemplate = releasepr.DefaultTitleTemplate()
+	}
+	if options.ReleaseCommitTemplate == nil {
+		options.ReleaseCommitTemplate = releasepr.DefaultTitleTemplate()
+	}
+
 	return &ReleaserPleaser{
-		forge:        forge,
-		logger:       logger,
-		targetBranch: targetBranch,
-		commitParser: commitParser,
-		versioning:   versioningStrategy,
-		extraFiles:   extraFiles,
-		updaters:     updaters,
+		forge:         forge,
+		logger:        options.Logger,
+		targetBranch:  options.TargetBranch,
+		commitParser:  options.CommitParser,
+		versioning:    options.Versioning,
+		packages:      options.Packages,
+		sections:      options.Sections,
+		scopes:        options.Scopes,
+		cloneOptions:  options.Clone,
+		commitOptions: options.Commit,
+		linkedIssues:  options.LinkedIssues,
+		preamble:      options.ChangelogPreamble,
+		format:        options.ChangelogFormat,
+		order:         options.ChangelogOrder,
+		authors:       options.Authors,
+		contributors:  options.NewContributors,
+		announcers:    options.Announcers,
+		maintenance:   options.Maintenance,
+		commitMode:    options.CommitMode,
+		requireMajor:  options.RequireMajorLabel,
+		prTitle:       options.PullRequestTitleTemplate,
+		commitMessage: options.ReleaseCommitTemplate,
+		dependencies:  options.Dependencies,
+		changelogs:    changelogs,
+		events:        options.Events,
 	}
 }
 
@@ -46,8 +213,18 @@ func (rp *ReleaserPleaser) EnsureLabels(ctx context.Context) error {
 	return rp.forge.EnsureLabelsExist(ctx, releasepr.KnownLabels)
 }
 
-func (rp *ReleaserPleaser) Run(ctx context.Context) error {
-	err := rp.runOnboarding(ctx)
+func (rp *ReleaserPleaser) Run(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "rp.Run", attribute.String("branch", rp.targetBranch))
+	defer func() { telemetry.End(span, err) }()
+
+	rp.result = Result{}
+
+	err = rp.runPreflight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	err = rp.runOnboarding(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to onboard repository: %w", err)
 	}
@@ -62,6 +239,8 @@ func (rp *ReleaserPleaser) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to reconcile release pull request: %w", err)
 	}
 
+	rp.runCleanup(ctx)
+
 	return nil
 }
 
@@ -83,6 +262,14 @@ func (rp *ReleaserPleaser) runCreatePendingReleases(ctx context.Context) error {
 		return err
 	}
 
+	if finder, ok := rp.forge.(forge.ReleaseFinder); ok {
+		unlabeled, err := rp.unlabeledReleases(ctx, logger, finder, prs)
+		if err != nil {
+			return err
+		}
+		prs = append(prs, unlabeled...)
+	}
+
 	if len(prs) == 0 {
 		logger.InfoContext(ctx, "No pending releases found")
 		return nil
@@ -100,7 +287,10 @@ func (rp *ReleaserPleaser) runCreatePendingReleases(ctx context.Context) error {
 	return nil
 }
 
-func (rp *ReleaserPleaser) createPendingRelease(ctx context.Context, pr *releasepr.ReleasePullRequest) error {
+func (rp *ReleaserPleaser) createPendingRelease(ctx context.Context, pr *releasepr.ReleasePullRequest) (err error) {
+	ctx, span := telemetry.Start(ctx, "rp.createPendingRelease", attribute.Int("pr.id", pr.ID))
+	defer func() { telemetry.End(span, err) }()
+
 	logger := rp.logger.With(
 		"method", "createPendingRelease",
 		"pr.id", pr.ID,
@@ -117,19 +307,35 @@ func (rp *ReleaserPleaser) createPendingRelease(ctx context.Context, pr *release
 		return err
 	}
 
+	pkg, ok := packageForTag(rp.packages, version)
+	if !ok {
+		return fmt.Errorf("no package matches the version %q of the pull request", version)
+	}
+
 	changelogText, err := pr.ChangelogText()
 	if err != nil {
 		return err
 	}
 
-	// TODO: Check if version should be marked latest
-
-	logger.DebugContext(ctx, "Creating release on forge")
-	err = rp.forge.CreateRelease(ctx, *pr.ReleaseCommit, version, changelogText, rp.versioning.IsPrerelease(version), true)
+	existing, err := rp.existingRelease(ctx, version)
 	if err != nil {
-		return fmt.Errorf("failed to create release on forge: %w", err)
+		return err
 	}
-	logger.DebugContext(ctx, "created release", "release.title", version, "release.url", rp.forge.ReleaseURL(version))
+
+	var release forge.Release
+	if existing != nil {
+		// A previous run failed after the release was created, only the remaining steps are retried.
+		logger.WarnContext(ctx, "release already exists, skipping creation and asset upload", "release.url", existing.URL)
+		release = *existing
+		release.Prerelease = rp.versioning.IsPrerelease(pkg.version(version))
+		rp.result.Releases = append(rp.result.Releases, release)
+	} else {
+		release, err = rp.createRelease(ctx, logger, pkg, *pr.ReleaseCommit, version, changelogText)
+		if err != nil {
+			return err
+		}
+	}
+	rp.emit(ctx, ReleaseCreated{Package: pkg.Name, Release: release})
 
 	logger.DebugContext(ctx, "updating pr labels")
 	err = rp.forge.SetPullRequestLabels(ctx, pr, []releasepr.Label{releasepr.LabelReleasePending}, []releasepr.Label{releasepr.LabelReleaseTagged})
@@ -138,36 +344,182 @@ func (rp *ReleaserPleaser) createPendingRelease(ctx context.Context, pr *release
 	}
 	logger.DebugContext(ctx, "updated pr labels")
 
-	logger.InfoContext(ctx, "Created release", "release.title", version, "release.url", rp.forge.ReleaseURL(version))
+	for _, announcer := range rp.announcers {
+		err = announcer.AnnounceRelease(ctx, release)
+		if err != nil {
+			return fmt.Errorf("failed to announce release: %w", err)
+		}
+	}
+
+	logger.InfoContext(ctx, "Created release", "release.title", version, "release.url", release.URL)
 
 	return nil
 }
 
-func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
-	logger := rp.logger.With("method", "runReconcileReleasePR")
+// createRelease creates the release on the forge and uploads the assets of the package.
+func (rp *ReleaserPleaser) createRelease(ctx context.Context, logger *slog.Logger, pkg Package, commit git.Commit, version, changelogText string) (forge.Release, error) {
+	prerelease := rp.versioning.IsPrerelease(pkg.version(version))
+	// Pre-releases and releases of older versions from maintenance branches should never replace the latest stable
+	// release on the forge.
+	latest := !prerelease && !rp.maintenance
+
+	if rp.commitOptions.Signer != nil {
+		// The forges only create unsigned lightweight tags, so we need to push the signed tag before creating the
+		// release.
+		err := rp.createSignedTag(ctx, commit, version)
+		if err != nil {
+			return forge.Release{}, fmt.Errorf("failed to create signed tag: %w", err)
+		}
+	}
 
-	rpBranch := fmt.Sprintf(PullRequestBranchFormat, rp.targetBranch)
+	var assets []string
+	if !pkg.Assets.empty() {
+		// Assets are built before the release is created, so a failing build does not result in a release without
+		// assets.
+		var err error
+		assets, err = rp.buildAssets(ctx, logger, pkg.Assets, commit, version, pkg.version(version))
+		if err != nil {
+			return forge.Release{}, fmt.Errorf("failed to build release assets: %w", err)
+		}
+	}
 
-	pr, err := rp.forge.PullRequestForBranch(ctx, rpBranch)
+	logger.DebugContext(ctx, "Creating release on forge", "release.prerelease", prerelease, "release.latest", latest)
+	release, err := rp.forge.CreateRelease(ctx, commit, version, changelogText, prerelease, latest)
+	if err != nil {
+		return forge.Release{}, fmt.Errorf("failed to create release on forge: %w", err)
+	}
+	logger.DebugContext(ctx, "created release", "release.title", version, "release.url", release.URL)
+	rp.result.Releases = append(rp.result.Releases, release)
+
+	err = rp.uploadAssets(ctx, logger, release, assets)
+	if err != nil {
+		return forge.Release{}, err
+	}
+
+	return release, nil
+}
+
+// buildAssets runs the asset commands and resolves the asset files in a clone of the repository at the release commit,
+// the same as the release commands.
+func (rp *ReleaserPleaser) buildAssets(ctx context.Context, logger *slog.Logger, assets Assets, commit git.Commit, tag, version string) ([]string, error) {
+	logger.DebugContext(ctx, "cloning repository", "clone.url", rp.forge.CloneURL(), "clone.mode", rp.cloneOptions.Mode, "clone.depth", rp.cloneOptions.Depth)
+	repo, err := git.CloneRepo(ctx, logger, rp.forge.CloneURL(), rp.targetBranch, rp.forge.GitAuth(), rp.cloneOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	dir := repo.Dir()
+	if dir == "" {
+		return nil, errors.New("release assets require a worktree on disk, use clone mode disk")
+	}
+
+	if err = repo.CheckoutCommit(ctx, commit.Hash); err != nil {
+		return nil, err
+	}
+
+	return assets.build(ctx, logger, dir, os.Stderr, tag, version)
+}
+
+func (rp *ReleaserPleaser) createSignedTag(ctx context.Context, commit git.Commit, version string) error {
+	logger := rp.logger.With("method", "createSignedTag", "tag.name", version, "commit.hash", commit.Hash)
+
+	logger.DebugContext(ctx, "cloning repository", "clone.url", rp.forge.CloneURL())
+	repo, err := git.CloneRepo(ctx, logger, rp.forge.CloneURL(), rp.targetBranch, rp.forge.GitAuth(), rp.cloneOptions)
+	if err != nil {
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	target, err := repo.TagTarget(ctx, version)
 	if err != nil {
 		return err
 	}
+	switch target {
+	case "":
+	case commit.Hash:
+		// The tag was pushed by a previous run that failed to create the release
+		logger.InfoContext(ctx, "tag already exists")
+		return nil
+	default:
+		return fmt.Errorf("tag already exists and points at commit %s", target)
+	}
 
-	var releaseOverrides releasepr.ReleaseOverrides
+	_, err = repo.CreateTag(ctx, version, commit.Hash, version, rp.commitOptions)
+	if err != nil {
+		return err
+	}
+
+	err = repo.PushTag(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to push tag: %w", err)
+	}
+
+	logger.InfoContext(ctx, "pushed signed tag")
+
+	return nil
+}
+
+func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
+	for _, pkg := range rp.packages {
+		err := rp.reconcileReleasePR(ctx, pkg)
+		if err != nil {
+			if pkg.Name != "" {
+				return fmt.Errorf("package %s: %w", pkg.Name, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releasePlan is the next release of a package, computed from the commits since the last release and the overrides
+// of the open release pull request.
+type releasePlan struct {
+	// pr is the open release pull request, nil if none exists.
+	pr                *releasepr.ReleasePullRequest
+	releaseOverrides  releasepr.ReleaseOverrides
+	lastReleaseCommit *git.Tag
+	analyzedCommits   []commitparser.AnalyzedCommit
+	// commits are all commits of the release, including those that are not part of the changelog.
+	commits []git.Commit
+	// omitted are the changes that are not part of the changelog.
+	omitted []releasepr.OmittedChange
+	// nextVersion is the tag of the next release, empty if there are no releasable commits.
+	nextVersion string
+}
+
+func (rp *ReleaserPleaser) planRelease(ctx context.Context, logger *slog.Logger, pkg Package) (_ *releasePlan, err error) {
+	ctx, span := telemetry.Start(ctx, "rp.planRelease")
+	defer func() { telemetry.End(span, err) }()
+
+	plan := &releasePlan{}
+
+	pr, err := rp.forge.PullRequestForBranch(ctx, pkg.branch(rp.targetBranch))
+	if err != nil {
+		return nil, err
+	}
+	plan.pr = pr
 
 	if pr != nil {
 		logger = logger.With("pr.id", pr.ID, "pr.title", pr.Title)
 		logger.InfoContext(ctx, "found existing release pull request")
 
-		releaseOverrides, err = pr.GetOverrides()
+		plan.releaseOverrides, err = pr.GetOverrides()
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		comments, err := rp.forge.PullRequestComments(ctx, pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request comments: %w", err)
+		}
+		plan.releaseOverrides = releasepr.ApplyCommands(plan.releaseOverrides, comments)
 	}
+	releaseOverrides := plan.releaseOverrides
 
-	releases, err := rp.forge.LatestTags(ctx)
+	releases, err := rp.forge.LatestTags(ctx, pkg.TagPrefix, rp.versioning)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if releases.Latest != nil {
@@ -178,41 +530,148 @@ func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
 	} else {
 		logger.InfoContext(ctx, "no latest tag found")
 	}
+	rp.emit(ctx, TagFound{Package: pkg.Name, Tag: releases.Latest})
 
 	// By default, we want to show everything that has happened since the last stable release
-	lastReleaseCommit := releases.Stable
+	plan.lastReleaseCommit = releases.Stable
 	if releaseOverrides.NextVersionType.IsPrerelease() {
 		// if the new release will be a prerelease,
 		// only show changes since the latest release (stable or prerelease)
-		lastReleaseCommit = releases.Latest
+		plan.lastReleaseCommit = releases.Latest
 	}
 
-	commits, err := rp.forge.CommitsSince(ctx, lastReleaseCommit)
+	commits, err := rp.forge.CommitsSince(ctx, plan.lastReleaseCommit, pkg.Path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if withoutReverts := git.DropReverted(commits); len(withoutReverts) < len(commits) {
+		logger.InfoContext(ctx, "dropped reverted commits and their reverts", "length", len(commits)-len(withoutReverts))
+		commits = withoutReverts
 	}
 
 	commits, err = parsePRBodyForCommitOverrides(commits)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if rp.linkedIssues {
+		commits = addLinkedIssues(commits)
+	}
+
+	var skipped []git.Commit
+	if len(releaseOverrides.SkipCommits) > 0 {
+		commits, skipped = skipCommits(commits, releaseOverrides.SkipCommits)
 	}
 
 	logger.InfoContext(ctx, "Found releasable commits", "length", len(commits))
 
-	analyzedCommits, err := rp.commitParser.Analyze(commits)
+	plan.analyzedCommits, err = rp.commitParser.Analyze(commits)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Analyzed commits", "length", len(plan.analyzedCommits))
+	rp.emit(ctx, CommitsDiscovered{Package: pkg.Name, Commits: commits, Analyzed: len(plan.analyzedCommits)})
+
+	included, omitted := omittedChanges(commits, skipped, plan.analyzedCommits, rp.forge.PullRequestURL)
+	if len(included) > 0 {
+		logger.InfoContext(ctx, "including pull requests with label", "label", releasepr.LabelChangelogInclude.Name, "length", len(included))
+		plan.analyzedCommits = append(plan.analyzedCommits, included...)
+	}
+	plan.omitted = omitted
+	plan.commits = commits
+
+	// Commits that are only shown in the changelog do not warrant a release on their own
+	versionBump := versioning.BumpFromCommits(plan.analyzedCommits)
+
+	// Grouped updates lose their type, so they are only grouped after the version bump is known
+	if rp.dependencies.Group {
+		plan.analyzedCommits, plan.omitted = groupDependencyUpdates(commits, plan.analyzedCommits, plan.omitted, rp.dependencies)
+	}
+
+	if rp.authors {
+		attributeAuthors(plan.analyzedCommits)
+	}
+	linkCommits(plan.analyzedCommits, rp.forge.CommitURL)
+	if err = addReleaseNoteDetails(plan.analyzedCommits); err != nil {
+		return nil, err
+	}
+	// The forges return the commits in different orders, sorting them avoids updates of the release pull request that
+	// only reorder the changelog.
+	changelog.Sort(plan.analyzedCommits, rp.order)
+
+	if versionBump == versioning.UnknownVersion {
+		return plan, nil
+	}
+
+	if rp.requireMajor && versionBump == versioning.MajorVersion && !releaseOverrides.AllowMajor {
+		logger.WarnContext(ctx, "limiting version bump to minor, add the label to the release pull request to allow a major version", "label", releasepr.LabelAllowMajor.Name)
+		versionBump = versioning.MinorVersion
+	}
+
+	if rp.maintenance && versionBump > versioning.PatchVersion {
+		logger.WarnContext(ctx, "limiting version bump to patch on maintenance branch", "branch", rp.targetBranch)
+		versionBump = versioning.PatchVersion
+	}
+
+	nextVersion, err := rp.versioning.NextVersion(pkg.releases(releases), versionBump, releaseOverrides.NextVersionType)
+	if err != nil {
+		return nil, err
+	}
+	if releaseOverrides.NextVersion != "" {
+		if rp.versioning.IsVersion(pkg.version(pkg.tagName(releaseOverrides.NextVersion))) {
+			logger.InfoContext(ctx, "using next version from pull request", "version", releaseOverrides.NextVersion, "calculated_version", nextVersion)
+			nextVersion = releaseOverrides.NextVersion
+		} else {
+			logger.WarnContext(ctx, "ignoring invalid next version from pull request", "version", releaseOverrides.NextVersion, "calculated_version", nextVersion)
+		}
+	}
+	plan.nextVersion = pkg.tagName(nextVersion)
+	logger.InfoContext(ctx, "next version", "version", plan.nextVersion)
+
+	return plan, nil
+}
+
+func (rp *ReleaserPleaser) reconcileReleasePR(ctx context.Context, pkg Package) (err error) {
+	ctx, span := telemetry.Start(ctx, "rp.reconcileReleasePR", attribute.String("package.name", pkg.Name))
+	defer func() { telemetry.End(span, err) }()
+
+	logger := rp.logger.With("method", "reconcileReleasePR")
+	if pkg.Name != "" {
+		logger = logger.With("package.name", pkg.Name, "package.path", pkg.Path)
+	}
+
+	rpBranch := pkg.branch(rp.targetBranch)
+
+	plan, err := rp.planRelease(ctx, logger, pkg)
 	if err != nil {
 		return err
 	}
 
-	logger.InfoContext(ctx, "Analyzed commits", "length", len(analyzedCommits))
+	versionComputed := VersionComputed{Package: pkg.Name, Version: plan.nextVersion}
+	if plan.lastReleaseCommit != nil {
+		versionComputed.PreviousVersion = plan.lastReleaseCommit.Name
+	}
+	rp.emit(ctx, versionComputed)
+
+	pr := plan.pr
+	if pr != nil {
+		logger = logger.With("pr.id", pr.ID, "pr.title", pr.Title)
+	}
+	releaseOverrides := plan.releaseOverrides
+	lastReleaseCommit := plan.lastReleaseCommit
+	analyzedCommits := plan.analyzedCommits
+	nextVersion := plan.nextVersion
 
-	if len(analyzedCommits) == 0 {
+	if nextVersion == "" {
 		if pr != nil {
 			logger.InfoContext(ctx, "closing existing pull requests, no commits available", "pr.id", pr.ID, "pr.title", pr.Title)
 			err = rp.forge.ClosePullRequest(ctx, pr)
 			if err != nil {
 				return err
 			}
+			rp.emit(ctx, rp.pullRequestUpdated(pkg, pr, PullRequestActionClosed))
 		} else {
 			logger.InfoContext(ctx, "No commits available for release")
 		}
@@ -220,16 +679,18 @@ func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
 		return nil
 	}
 
-	versionBump := versioning.BumpFromCommits(analyzedCommits)
-	// TODO: Set version in release pr
-	nextVersion, err := rp.versioning.NextVersion(releases, versionBump, releaseOverrides.NextVersionType)
+	prResult := PullRequestResult{Package: pkg.Name, Version: nextVersion, Commits: len(analyzedCommits)}
+	if lastReleaseCommit != nil {
+		prResult.PreviousTag = lastReleaseCommit.Name
+	}
+
+	creator, err := rp.commitCreator()
 	if err != nil {
 		return err
 	}
-	logger.InfoContext(ctx, "next version", "version", nextVersion)
 
-	logger.DebugContext(ctx, "cloning repository", "clone.url", rp.forge.CloneURL())
-	repo, err := git.CloneRepo(ctx, logger, rp.forge.CloneURL(), rp.targetBranch, rp.forge.GitAuth())
+	logger.DebugContext(ctx, "cloning repository", "clone.url", rp.forge.CloneURL(), "clone.mode", rp.cloneOptions.Mode, "clone.depth", rp.cloneOptions.Depth)
+	repo, err := git.CloneRepo(ctx, logger, rp.forge.CloneURL(), rp.targetBranch, rp.forge.GitAuth(), rp.cloneOptions)
 	if err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
@@ -242,64 +703,149 @@ func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
 		return err
 	}
 
-	changelogData := changelog.New(commitparser.ByType(analyzedCommits), nextVersion, rp.forge.ReleaseURL(nextVersion), releaseOverrides.Prefix, releaseOverrides.Suffix)
+	readFile := func(path string) ([]byte, error) { return repo.ReadFile(ctx, path) }
+	changelogRenderer, err := changelog.LoadRenderer(logger, rp.format, readFile)
+	if err != nil {
+		return err
+	}
+
+	// The pull request description is parsed as markdown, other formats are only used for the changelog file.
+	pullRequestRenderer := changelogRenderer
+	if rp.format == changelog.FormatJSON {
+		pullRequestRenderer, err = changelog.LoadRenderer(logger, changelog.FormatMarkdown, readFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	changelogData := changelog.New(analyzedCommits, rp.sections, rp.scopes, nextVersion, rp.forge.ReleaseURL(nextVersion), releaseOverrides.Prefix, releaseOverrides.Suffix)
+	changelogData.Date = time.Now().UTC().Format(time.DateOnly)
+	if lastReleaseCommit != nil {
+		changelogData.PreviousVersion = lastReleaseCommit.Name
+		changelogData.CompareLink = rp.forge.CompareURL(lastReleaseCommit.Name, nextVersion)
+	}
+
+	if rp.contributors {
+		if counter, ok := rp.forge.(forge.ContributionCounter); ok {
+			changelogData.NewContributors, err = newContributors(ctx, counter, plan.commits, rp.forge.PullRequestURL)
+			if err != nil {
+				return err
+			}
+		} else {
+			logger.WarnContext(ctx, "forge does not support listing new contributors")
+		}
+	}
 
-	changelogEntry, err := changelog.Entry(logger, changelog.DefaultTemplate(), changelogData, changelog.Formatting{})
+	_, changelogSpan := telemetry.Start(ctx, "changelog.Entry")
+	changelogEntry, err := changelogRenderer.Render(changelogData, changelog.Formatting{})
+	telemetry.End(changelogSpan, err)
 	if err != nil {
 		return fmt.Errorf("failed to build changelog entry: %w", err)
 	}
 
 	// Info for updaters
-	info := updater.ReleaseInfo{Version: nextVersion, ChangelogEntry: changelogEntry}
+	info := updater.ReleaseInfo{
+		Version:           pkg.version(nextVersion),
+		TagName:           nextVersion,
+		ChangelogEntry:    changelogEntry,
+		ChangelogPreamble: rp.preamble,
+	}
 
-	err = repo.UpdateFile(ctx, updater.ChangelogFile, true, updater.WithInfo(info, updater.Changelog))
+	err = writeChangelogFiles(ctx, logger, repo, pkg, rp.changelogs, changelogData, info)
 	if err != nil {
-		return fmt.Errorf("failed to update changelog file: %w", err)
+		return err
 	}
 
-	for _, path := range rp.extraFiles {
+	for _, file := range pkg.ExtraFiles {
 		// TODO: Check for missing files
-		err = repo.UpdateFile(ctx, path, false, updater.WithInfo(info, rp.updaters...))
+		err = repo.UpdateFile(ctx, file.Path, false, updater.WithInfo(info, file.Updaters...))
+		if file.Optional && errors.Is(err, fs.ErrNotExist) {
+			logger.DebugContext(ctx, "skipping missing optional file", "file.path", file.Path)
+			continue
+		}
 		if err != nil {
 			return fmt.Errorf("failed to run file updater: %w", err)
 		}
 	}
 
-	releaseCommitMessage := fmt.Sprintf("chore(%s): release %s", rp.targetBranch, nextVersion)
-	releaseCommit, err := repo.Commit(ctx, releaseCommitMessage)
+	err = runReleaseCommands(ctx, logger, repo, pkg.ReleaseCommands, os.Stderr, nextVersion, pkg.version(nextVersion))
+	if err != nil {
+		return err
+	}
+
+	titleData := releasepr.TitleData{Version: nextVersion, Branch: rp.targetBranch, Package: pkg.Name}
+
+	releaseCommitMessage, err := releasepr.RenderTitle(rp.commitMessage, titleData)
+	if err != nil {
+		return fmt.Errorf("failed to render release commit message: %w", err)
+	}
+	commitOptions := rp.commitOptions
+	if creator != nil {
+		// The forge signs the commit it creates, the local commit is only used to collect the changes.
+		commitOptions.Signer = nil
+	}
+	releaseCommit, err := repo.Commit(ctx, releaseCommitMessage, commitOptions)
 	if err != nil {
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
 	logger.InfoContext(ctx, "created release commit", "commit.hash", releaseCommit.Hash, "commit.message", releaseCommit.Message)
 
+	// Users can push changes to the branch of an open pull request, e.g. to fix the changelog. They are kept on top
+	// of the release commit instead of being discarded by the force push.
+	branchCommits := []git.Commit{releaseCommit}
+	var manualChanges []releasepr.ManualChange
+	if pr != nil {
+		branchCommits, manualChanges, err = keepManualChanges(ctx, logger, repo, rpBranch, rp.targetBranch, releaseCommit, commitOptions)
+		if err != nil {
+			return fmt.Errorf("failed to keep manual changes of release branch: %w", err)
+		}
+	}
+
 	// Check if anything changed in comparison to the remote branch (if exists)
-	newReleasePRChanges, err := repo.HasChangesWithRemote(ctx, rpBranch)
-	if err != nil {
-		return err
+	newReleasePRChanges := false
+	if branchCommits != nil {
+		newReleasePRChanges, err = repo.HasChangesWithRemote(ctx, rpBranch)
+		if err != nil {
+			return err
+		}
 	}
 
-	if newReleasePRChanges {
-		err = repo.ForcePush(ctx, rpBranch)
+	switch {
+	case branchCommits == nil:
+		logger.WarnContext(ctx, "manual changes conflict with the release commit, skipping push")
+	case newReleasePRChanges:
+		releaseCommit, err = pushReleaseCommit(ctx, repo, creator, rpBranch, branchCommits...)
 		if err != nil {
 			return fmt.Errorf("failed to push branch: %w", err)
 		}
 
 		logger.InfoContext(ctx, "pushed branch", "commit.hash", releaseCommit.Hash, "branch.name", rpBranch)
-	} else {
+		prResult.Pushed = true
+		rp.emit(ctx, BranchPushed{Package: pkg.Name, Branch: rpBranch, Commit: releaseCommit})
+	default:
 		logger.InfoContext(ctx, "file content is already up-to-date in remote branch, skipping push")
 	}
 
 	// We do not need the version title here. In the pull request the version is available from the title, and in the
 	// release on the Forge its usually in a heading somewhere above the text.
-	changelogEntryPullRequest, err := changelog.Entry(logger, changelog.DefaultTemplate(), changelogData, changelog.Formatting{HideVersionTitle: true})
+	_, changelogSpan = telemetry.Start(ctx, "changelog.Entry", attribute.Bool("pull_request", true))
+	changelogEntryPullRequest, err := pullRequestRenderer.Render(changelogData, changelog.Formatting{HideVersionTitle: true})
+	telemetry.End(changelogSpan, err)
 	if err != nil {
 		return fmt.Errorf("failed to build pull request changelog entry: %w", err)
 	}
 
+	// Forges limit the length of the description, long changelogs are truncated. The changelog file always has the
+	// full changelog.
+	maxDescriptionLength := 0
+	if limiter, ok := rp.forge.(forge.DescriptionLimiter); ok {
+		maxDescriptionLength = limiter.MaxDescriptionLength()
+	}
+
 	// Open/Update PR
 	if pr == nil {
-		pr, err = releasepr.NewReleasePullRequest(rpBranch, rp.targetBranch, nextVersion, changelogEntryPullRequest)
+		pr, err = releasepr.NewReleasePullRequest(rpBranch, rp.prTitle, titleData, changelogEntryPullRequest, plan.omitted, maxDescriptionLength)
 		if err != nil {
 			return err
 		}
@@ -309,24 +855,67 @@ func (rp *ReleaserPleaser) runReconcileReleasePR(ctx context.Context) error {
 			return err
 		}
 		logger.InfoContext(ctx, "opened pull request", "pr.title", pr.Title, "pr.id", pr.ID, "pr.url", rp.forge.PullRequestURL(pr.ID))
+		rp.addPullRequestResult(prResult, pr.ID)
+		rp.emit(ctx, rp.pullRequestUpdated(pkg, pr, PullRequestActionOpened))
 	} else {
-		pr.SetTitle(rp.targetBranch, nextVersion)
+		previousTitle, previousDescription := pr.Title, pr.Description
+
+		err = pr.SetTitleFromTemplate(rp.prTitle, titleData)
+		if err != nil {
+			return err
+		}
 
 		overrides, err := pr.GetOverrides()
 		if err != nil {
 			return err
 		}
-		err = pr.SetDescription(changelogEntryPullRequest, overrides)
+		pr.ManualChanges = manualChanges
+		err = pr.SetDescriptionWithLimit(changelogEntryPullRequest, overrides, plan.omitted, maxDescriptionLength)
 		if err != nil {
 			return err
 		}
 
+		if !slices.Contains(pr.Labels, releasepr.LabelReleasePending) {
+			// Without the label, the pull request is not found once it is merged and the release is never created.
+			logger.InfoContext(ctx, "restoring missing label on pull request", "pr.id", pr.ID, "label", releasepr.LabelReleasePending.Name)
+			err = rp.forge.SetPullRequestLabels(ctx, pr, nil, []releasepr.Label{releasepr.LabelReleasePending})
+			if err != nil {
+				return err
+			}
+			pr.Labels = append(pr.Labels, releasepr.LabelReleasePending)
+		}
+
+		if pr.Title == previousTitle && pr.Description == previousDescription {
+			logger.InfoContext(ctx, "pull request is already up-to-date, skipping update", "pr.id", pr.ID, "pr.url", rp.forge.PullRequestURL(pr.ID))
+			rp.addPullRequestResult(prResult, pr.ID)
+			return nil
+		}
+
 		err = rp.forge.UpdatePullRequest(ctx, pr)
 		if err != nil {
 			return err
 		}
 		logger.InfoContext(ctx, "updated pull request", "pr.title", pr.Title, "pr.id", pr.ID, "pr.url", rp.forge.PullRequestURL(pr.ID))
+		rp.addPullRequestResult(prResult, pr.ID)
+		rp.emit(ctx, rp.pullRequestUpdated(pkg, pr, PullRequestActionUpdated))
 	}
 
 	return nil
 }
+
+// skipCommits removes all commits matching one of the (abbreviated) hashes. It returns the remaining and the removed
+// commits.
+func skipCommits(commits []git.Commit, hashes []string) ([]git.Commit, []git.Commit) {
+	var skipped []git.Commit
+	remaining := slices.DeleteFunc(commits, func(commit git.Commit) bool {
+		skip := slices.ContainsFunc(hashes, func(hash string) bool {
+			return strings.HasPrefix(commit.Hash, hash)
+		})
+		if skip {
+			skipped = append(skipped, commit)
+		}
+		return skip
+	})
+
+	return remaining, skipped
+}
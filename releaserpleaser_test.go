@@ -0,0 +1,39 @@
+package rp
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apricote/releaser-pleaser/internal/changelog"
+	"github.com/apricote/releaser-pleaser/internal/versioning"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		rp := New(nil, Options{})
+
+		assert.Equal(t, slog.Default(), rp.logger)
+		assert.Equal(t, DefaultTargetBranch, rp.targetBranch)
+		assert.NotNil(t, rp.commitParser)
+		assert.Equal(t, versioning.SemVer, rp.versioning)
+		assert.Equal(t, []Package{{TagPrefix: DefaultTagPrefix}}, rp.packages)
+		assert.Equal(t, changelog.DefaultSections, rp.sections)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		packages := []Package{{Name: "api", Path: "api", TagPrefix: "api/v"}}
+		sections := []changelog.Section{{Type: "perf", Title: "Performance"}}
+
+		rp := New(nil, Options{
+			TargetBranch: "develop",
+			Packages:     packages,
+			Sections:     sections,
+		})
+
+		assert.Equal(t, "develop", rp.targetBranch)
+		assert.Equal(t, packages, rp.packages)
+		assert.Equal(t, sections, rp.sections)
+	})
+}
@@ -0,0 +1,40 @@
+package rp
+
+import (
+	"github.com/apricote/releaser-pleaser/internal/forge"
+)
+
+// Result summarizes the changes of the last Run. It is used to report the outcome to CI systems.
+type Result struct {
+	// Releases are created from merged release pull requests.
+	Releases []forge.Release
+	// PullRequests are the open release pull requests, one per package with releasable changes.
+	PullRequests []PullRequestResult
+}
+
+// PullRequestResult describes a release pull request that was opened, updated or already up-to-date.
+type PullRequestResult struct {
+	// Package is the name of the package, empty for single package repositories.
+	Package string
+	ID      int
+	URL     string
+	// PreviousTag is the tag of the release the changes are compared to, empty for the first release.
+	PreviousTag string
+	// Version is the tag of the proposed release.
+	Version string
+	// Commits is the number of commits included in the release.
+	Commits int
+	// Pushed is true if the release branch was pushed in this run.
+	Pushed bool
+}
+
+// Result returns the changes of the last Run.
+func (rp *ReleaserPleaser) Result() Result {
+	return rp.result
+}
+
+func (rp *ReleaserPleaser) addPullRequestResult(result PullRequestResult, id int) {
+	result.ID = id
+	result.URL = rp.forge.PullRequestURL(id)
+	rp.result.PullRequests = append(rp.result.PullRequests, result)
+}
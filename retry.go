@@ -0,0 +1,70 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// existingRelease returns the release of the version if it was already created on the forge, e.g. by a previous run
+// that failed before the labels of the pull request were updated. It returns nil if the forge does not implement
+// forge.ReleaseFinder.
+func (rp *ReleaserPleaser) existingRelease(ctx context.Context, version string) (*forge.Release, error) {
+	finder, ok := rp.forge.(forge.ReleaseFinder)
+	if !ok {
+		return nil, nil
+	}
+
+	release, err := finder.FindRelease(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing release: %w", err)
+	}
+
+	return release, nil
+}
+
+// unlabeledReleases returns the last merged release pull request of every package, if it was not released yet and is
+// not one of the pending pull requests. This happens if the releasepr.LabelReleasePending was removed from the pull
+// request before it was merged.
+func (rp *ReleaserPleaser) unlabeledReleases(ctx context.Context, logger *slog.Logger, finder forge.ReleaseFinder, pending []*releasepr.ReleasePullRequest) ([]*releasepr.ReleasePullRequest, error) {
+	var prs []*releasepr.ReleasePullRequest
+
+	for _, pkg := range rp.packages {
+		pr, err := finder.LastMergedPullRequest(ctx, pkg.branch(rp.targetBranch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last merged release pull request: %w", err)
+		}
+		if pr == nil || slices.Contains(pr.Labels, releasepr.LabelReleaseTagged) {
+			continue
+		}
+
+		if slices.ContainsFunc(slices.Concat(pending, prs), func(other *releasepr.ReleasePullRequest) bool {
+			return other.ID == pr.ID
+		}) {
+			continue
+		}
+
+		version, err := pr.Version()
+		if err != nil {
+			logger.WarnContext(ctx, "unable to parse version from merged release pull request, skipping", "pr.id", pr.ID, "pr.title", pr.Title)
+			continue
+		}
+
+		release, err := finder.FindRelease(ctx, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing release: %w", err)
+		}
+		if release != nil {
+			continue
+		}
+
+		logger.WarnContext(ctx, "found merged release pull request without label that was not released yet", "pr.id", pr.ID, "pr.title", pr.Title, "label", releasepr.LabelReleasePending.Name)
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
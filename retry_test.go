@@ -0,0 +1,125 @@
+package rp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apricote/releaser-pleaser/internal/forge"
+	"github.com/apricote/releaser-pleaser/internal/git"
+	"github.com/apricote/releaser-pleaser/internal/releasepr"
+)
+
+// fakeReleaseFinder knows the last merged pull request of every branch and the existing releases.
+type fakeReleaseFinder struct {
+	forge.Forge
+
+	merged   map[string]*releasepr.ReleasePullRequest
+	releases map[string]*forge.Release
+
+	created []string
+	labeled []int
+}
+
+func (f *fakeReleaseFinder) FindRelease(_ context.Context, tagName string) (*forge.Release, error) {
+	return f.releases[tagName], nil
+}
+
+func (f *fakeReleaseFinder) LastMergedPullRequest(_ context.Context, branch string) (*releasepr.ReleasePullRequest, error) {
+	return f.merged[branch], nil
+}
+
+func (f *fakeReleaseFinder) CreateRelease(_ context.Context, _ git.Commit, title, changelog string, prerelease, _ bool) (forge.Release, error) {
+	f.created = append(f.created, title)
+	return forge.Release{TagName: title, Changelog: changelog, Prerelease: prerelease}, nil
+}
+
+func (f *fakeReleaseFinder) SetPullRequestLabels(_ context.Context, pr *releasepr.ReleasePullRequest, _, _ []releasepr.Label) error {
+	f.labeled = append(f.labeled, pr.ID)
+	return nil
+}
+
+func mergedReleasePR(t *testing.T, id int, version string, labels ...releasepr.Label) *releasepr.ReleasePullRequest {
+	t.Helper()
+
+	pr, err := releasepr.NewReleasePullRequest("releaser-pleaser--branches--main", nil, releasepr.TitleData{Version: version, Branch: "main"}, "### Features\n\n- foo\n", nil, 0)
+	require.NoError(t, err)
+	pr.ID = id
+	pr.Labels = labels
+	pr.ReleaseCommit = &git.Commit{Hash: "1234567890abcdef"}
+	return pr
+}
+
+func TestReleaserPleaser_unlabeledReleases(t *testing.T) {
+	const branch = "releaser-pleaser--branches--main"
+
+	tests := []struct {
+		name     string
+		merged   *releasepr.ReleasePullRequest
+		releases map[string]*forge.Release
+		pending  []*releasepr.ReleasePullRequest
+		want     []int
+	}{
+		{
+			name: "no merged pull request",
+		},
+		{
+			name:   "unlabeled",
+			merged: mergedReleasePR(t, 1, "v1.1.0"),
+			want:   []int{1},
+		},
+		{
+			name:     "already released",
+			merged:   mergedReleasePR(t, 1, "v1.1.0"),
+			releases: map[string]*forge.Release{"v1.1.0": {TagName: "v1.1.0"}},
+		},
+		{
+			name:   "tagged",
+			merged: mergedReleasePR(t, 1, "v1.1.0", releasepr.LabelReleaseTagged),
+		},
+		{
+			name:    "pending",
+			merged:  mergedReleasePR(t, 1, "v1.1.0", releasepr.LabelReleasePending),
+			pending: []*releasepr.ReleasePullRequest{mergedReleasePR(t, 1, "v1.1.0", releasepr.LabelReleasePending)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &fakeReleaseFinder{
+				merged:   map[string]*releasepr.ReleasePullRequest{branch: tt.merged},
+				releases: tt.releases,
+			}
+			rp := New(finder, Options{})
+
+			got, err := rp.unlabeledReleases(context.Background(), slog.Default(), finder, tt.pending)
+			require.NoError(t, err)
+
+			var ids []int
+			for _, pr := range got {
+				ids = append(ids, pr.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestReleaserPleaser_createPendingRelease_existing(t *testing.T) {
+	finder := &fakeReleaseFinder{
+		releases: map[string]*forge.Release{"v1.1.0": {TagName: "v1.1.0", URL: "https://example.com/v1.1.0"}},
+	}
+	rp := New(finder, Options{})
+
+	err := rp.createPendingRelease(context.Background(), mergedReleasePR(t, 1, "v1.1.0", releasepr.LabelReleasePending))
+	require.NoError(t, err)
+
+	assert.Empty(t, finder.created)
+	assert.Equal(t, []int{1}, finder.labeled)
+	assert.Equal(t, []forge.Release{{TagName: "v1.1.0", URL: "https://example.com/v1.1.0"}}, rp.result.Releases)
+
+	err = rp.createPendingRelease(context.Background(), mergedReleasePR(t, 2, "v1.2.0", releasepr.LabelReleasePending))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.2.0"}, finder.created)
+}